@@ -26,6 +26,7 @@ function AppContent() {
   const [exerciseType, setExerciseType] = useState<'function' | 'server'>('function');
   const [showModuleDropdown, setShowModuleDropdown] = useState(false);
   const [hasAttemptedSubmit, setHasAttemptedSubmit] = useState(false);
+  const [solution, setSolution] = useState<string | undefined>(undefined);
 
   // Default module ID - could be made configurable later
   const [currentModuleId, setCurrentModuleId] = useState('module-1');
@@ -41,6 +42,7 @@ function AppContent() {
       const content = await ModuleService.getModuleContent(currentModuleId);
       setModuleContent(content);
       setCode(content.exerciseContent.editorFiles.server);
+      setSolution(undefined);
       
       // Set exercise type based on module ID
       setExerciseType(currentModuleId === 'module-1' ? 'function' : 'server');
@@ -137,6 +139,11 @@ function AppContent() {
     try {
       const results = await ModuleService.runTests(currentModuleId, code);
       setTestResults(results);
+
+      // Each attempt may unlock the solution
+      ModuleService.getSolution(currentModuleId)
+        .then((access) => setSolution(access.available ? access.solution : undefined))
+        .catch(() => setSolution(undefined));
       
       if (results.totalTests === 0) {
         setOutput(`⚠️ No tests were executed.\n\nExecution time: ${results.executionTime}ms\n\nPlease check that the test setup is working correctly.`);
@@ -344,7 +351,7 @@ function AppContent() {
               code={code} 
               onCodeChange={setCode}
               packageJson={moduleContent.exerciseContent.editorFiles.package}
-              solution={moduleContent.exerciseContent.solution}
+              solution={solution}
               runCode={handleRunCode}
               hasAttemptedSubmit={hasAttemptedSubmit}
             />
@@ -33,10 +33,18 @@ export interface ModuleContent {
       test: string;
       package: string;
     };
-    solution: string;
   };
 }
 
+export interface SolutionAccess {
+  moduleId: string;
+  exerciseId: string;
+  available: boolean;
+  attempts: number;
+  availableAt?: string;
+  solution?: string;
+}
+
 export interface TestResult {
   testName: string;
   passed: boolean;
@@ -65,6 +73,17 @@ export interface RunResult {
 }
 
 const API_BASE_URL = config.apiBaseUrl;
+const LEARNER_ID_KEY = 'learnerId';
+
+// The server tracks attempts per learner with an anonymous ID kept in the browser
+function getLearnerId(): string {
+  let learnerId = localStorage.getItem(LEARNER_ID_KEY);
+  if (!learnerId) {
+    learnerId = crypto.randomUUID();
+    localStorage.setItem(LEARNER_ID_KEY, learnerId);
+  }
+  return learnerId;
+}
 
 export class ModuleService {
   static async getAllModules(): Promise<Module[]> {
@@ -88,6 +107,7 @@ export class ModuleService {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
+        'X-Learner-ID': getLearnerId(),
       },
       body: JSON.stringify({ code }),
     });
@@ -99,6 +119,21 @@ export class ModuleService {
     return response.json();
   }
 
+  // Resolves with the access status whether or not the solution is unlocked yet
+  static async getSolution(moduleId: string): Promise<SolutionAccess> {
+    const response = await fetch(`${API_BASE_URL}/modules/${moduleId}/solution`, {
+      headers: {
+        'X-Learner-ID': getLearnerId(),
+      },
+    });
+
+    if (!response.ok && response.status !== 403) {
+      throw new Error(`Failed to fetch solution: ${response.statusText}`);
+    }
+
+    return response.json();
+  }
+
   static async runCode(moduleId: string, code: string): Promise<RunResult> {
     const response = await fetch(`${API_BASE_URL}/run/${moduleId}`, {
       method: 'POST',
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/backend2lab/backend2lab/server/config"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+)
+
+// runCommand runs a command-line subcommand and returns the process exit code
+func runCommand(name string, args []string) int {
+	switch name {
+	case "selfcheck":
+		return runSelfCheck(args)
+	case "validate":
+		return runValidate(args)
+	case "export":
+		return runExport(args)
+	case "import":
+		return runImport(args)
+	case "similarity":
+		return runSimilarity(args)
+	case "help", "-h", "--help":
+		printUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return 2
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: backend-playground-server [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "With no command the API server is started.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  selfcheck   Run every module's solution and starter code against its tests")
+	fmt.Fprintln(w, "  validate    Check module.json files and the content they reference")
+	fmt.Fprintln(w, "  export      Write a module bundle (.tar.gz)")
+	fmt.Fprintln(w, "  import      Install a module from a bundle into the modules directory")
+	fmt.Fprintln(w, "  similarity  Report learners' submissions that look copied from each other or the solution")
+}
+
+// runSelfCheck verifies that solutions pass and starter code fails.
+// It exits with 1 when any module does not behave as expected.
+func runSelfCheck(args []string) int {
+	flags := flag.NewFlagSet("selfcheck", flag.ContinueOnError)
+	modules := flags.String("modules", "", "comma-separated module IDs to check (default: all)")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var moduleIds []string
+	for _, id := range strings.Split(*modules, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			moduleIds = append(moduleIds, id)
+		}
+	}
+
+	source, err := newContentSource(config.LoadContentConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Self-check failed: %v\n", err)
+		return 2
+	}
+
+	moduleService := services.NewModuleServiceWithSource(source)
+	selfCheck := services.NewSelfCheckService(moduleService, services.NewTestRunnerWithSource(source).WithCatalog(moduleService))
+	report, err := selfCheck.Run(moduleIds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Self-check failed: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		printSelfCheckReport(os.Stdout, report)
+	}
+
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+// printSelfCheckReport prints the expected vs actual matrix as a table
+func printSelfCheckReport(w io.Writer, report *models.SelfCheckReport) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tEXERCISE\tSOLUTION\tSTARTER\tRESULT")
+	for _, result := range report.Modules {
+		for _, exercise := range result.Exercises {
+			status := "ok"
+			if !exercise.OK {
+				status = "REGRESSION"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", result.ModuleID, exercise.ExerciseID, formatSelfCheckRun(exercise.Solution), formatSelfCheckRun(exercise.Starter), status)
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d modules checked, %d regressions (%dms)\n", len(report.Modules), report.Regressions, report.Duration)
+	for _, result := range report.Modules {
+		for _, exercise := range result.Exercises {
+			if exercise.Solution.Error != nil {
+				fmt.Fprintf(w, "  %s/%s solution: %s\n", result.ModuleID, exercise.ExerciseID, *exercise.Solution.Error)
+			}
+			if exercise.Starter.Error != nil {
+				fmt.Fprintf(w, "  %s/%s starter: %s\n", result.ModuleID, exercise.ExerciseID, *exercise.Starter.Error)
+			}
+		}
+	}
+}
+
+// formatSelfCheckRun renders a run as "expected/actual (passed/total)"
+func formatSelfCheckRun(run models.SelfCheckRun) string {
+	return fmt.Sprintf("%s/%s (%d/%d)", run.Expected, run.Actual, run.PassedTests, run.TotalTests)
+}
+
+// runValidate checks module content and exits with 1 when errors are found
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	dir := flags.String("dir", "", "modules directory to validate (default: the configured content source)")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	contentConfig := config.LoadContentConfig()
+	if *dir != "" {
+		contentConfig.ModulesDir = *dir
+	}
+
+	source, err := newContentSource(contentConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
+		return 2
+	}
+
+	report, err := services.NewModuleServiceWithSource(source).Validate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		printValidationReport(os.Stdout, report)
+	}
+
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+// printValidationReport prints one line per issue followed by a summary
+func printValidationReport(w io.Writer, report *models.ValidationReport) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, issue := range report.Issues {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", issue.Severity, issue.Module, issue.Code, issue.Message)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d modules checked, %d errors, %d warnings\n", report.Modules, report.Errors, report.Warnings)
+}
+
+// runExport writes a module bundle to a file or stdout
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	moduleId := flags.String("module", "", "module ID to export (required)")
+	output := flags.String("o", "", "output file (default: <module>.tar.gz, - for stdout)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *moduleId == "" {
+		fmt.Fprintln(os.Stderr, "export: -module is required")
+		return 2
+	}
+
+	source, err := newContentSource(config.LoadContentConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		return 2
+	}
+
+	path := *output
+	if path == "" {
+		path = *moduleId + ".tar.gz"
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+			return 2
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := services.NewModuleServiceWithSource(source).ExportModule(*moduleId, w); err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		if path != "-" {
+			os.Remove(path)
+		}
+		return 1
+	}
+
+	if path != "-" {
+		fmt.Fprintf(os.Stderr, "Exported %s to %s\n", *moduleId, path)
+	}
+	return 0
+}
+
+// runImport installs a module bundle. It exits with 1 when the bundle is
+// rejected, printing the validation report if there is one.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := flags.String("file", "", "bundle to import (required, - for stdin)")
+	onConflict := flags.String("on-conflict", models.ConflictReject, "what to do when the module ID is taken: reject, replace or renumber")
+	targetId := flags.String("id", "", "install under this module ID instead of the bundle's")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "import: -file is required")
+		return 2
+	}
+
+	source, err := newContentSource(config.LoadContentConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		return 2
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		r = f
+	}
+
+	result, err := services.NewModuleServiceWithSource(source).ImportModule(r, models.ImportOptions{
+		OnConflict: *onConflict,
+		TargetID:   *targetId,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		if result != nil && result.Validation != nil {
+			printValidationReport(os.Stderr, result.Validation)
+		}
+		return 1
+	}
+
+	fmt.Printf("Imported %s as %s (%d files)\n", result.OriginalID, result.ModuleID, result.Files)
+	return 0
+}
+
+// runSimilarity compares learners' latest submissions and exits with 1 when
+// any are at least as similar as the threshold, so it can run as a scheduled job
+func runSimilarity(args []string) int {
+	flags := flag.NewFlagSet("similarity", flag.ContinueOnError)
+	modules := flags.String("modules", "", "comma-separated module IDs to compare (default: all)")
+	exerciseId := flags.String("exercise", "", "only compare submissions to this exercise (requires a single module)")
+	threshold := flags.Float64("threshold", services.DefaultSimilarityThreshold, "similarity from which submissions are reported, above 0 and at most 1")
+	asJSON := flags.Bool("json", false, "print the reports as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *threshold <= 0 || *threshold > 1 {
+		fmt.Fprintln(os.Stderr, "similarity: -threshold must be above 0 and at most 1")
+		return 2
+	}
+
+	source, err := newContentSource(config.LoadContentConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Similarity check failed: %v\n", err)
+		return 2
+	}
+	moduleService := services.NewModuleServiceWithSource(source)
+
+	var moduleIds []string
+	for _, id := range strings.Split(*modules, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			moduleIds = append(moduleIds, id)
+		}
+	}
+	if len(moduleIds) == 0 {
+		all, err := moduleService.GetAllModules()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Similarity check failed: %v\n", err)
+			return 2
+		}
+		for _, module := range all {
+			moduleIds = append(moduleIds, module.ID)
+		}
+	}
+	if *exerciseId != "" && len(moduleIds) != 1 {
+		fmt.Fprintln(os.Stderr, "similarity: -exercise requires a single module in -modules")
+		return 2
+	}
+
+	db, err := services.OpenSQLite(config.LoadProgressConfig().DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Similarity check failed: %v\n", err)
+		return 2
+	}
+	defer db.Close()
+	store, err := services.NewSQLiteSubmissionStore(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Similarity check failed: %v\n", err)
+		return 2
+	}
+
+	similarity := services.NewSimilarityService(moduleService, store)
+	reports := []*models.SimilarityReport{}
+	found := false
+	for _, moduleId := range moduleIds {
+		report, err := similarity.Detect(moduleId, *exerciseId, *threshold)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Similarity check failed: %v\n", err)
+			return 2
+		}
+		reports = append(reports, report)
+		found = found || len(report.Pairs) > 0 || len(report.SolutionMatches) > 0
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(reports)
+	} else {
+		printSimilarityReports(os.Stdout, reports)
+	}
+
+	if found {
+		return 1
+	}
+	return 0
+}
+
+// printSimilarityReports prints one line per match, most similar first
+// within each module, followed by a summary
+func printSimilarityReports(w io.Writer, reports []*models.SimilarityReport) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tEXERCISE\tSIMILARITY\tFIRST\tSECOND")
+	submissions, pairs, solutionMatches := 0, 0, 0
+	for _, report := range reports {
+		for _, pair := range report.Pairs {
+			fmt.Fprintf(tw, "%s\t%s\t%.0f%%\t%s\t%s\n", report.ModuleID, pair.ExerciseID, pair.Similarity*100,
+				formatSimilarSubmission(pair.First), formatSimilarSubmission(pair.Second))
+		}
+		for _, match := range report.SolutionMatches {
+			fmt.Fprintf(tw, "%s\t%s\t%.0f%%\t%s\tsolution\n", report.ModuleID, match.ExerciseID, match.Similarity*100,
+				formatSimilarSubmission(match.Submission))
+		}
+		submissions += report.Submissions
+		pairs += len(report.Pairs)
+		solutionMatches += len(report.SolutionMatches)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d modules checked, %d submissions compared, %d similar pairs, %d similar to solutions\n",
+		len(reports), submissions, pairs, solutionMatches)
+}
+
+// formatSimilarSubmission renders a submission as "learner (submission ID)"
+func formatSimilarSubmission(submission models.SimilarSubmission) string {
+	return fmt.Sprintf("%s (%s)", submission.LearnerID, submission.SubmissionID)
+}
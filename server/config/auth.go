@@ -0,0 +1,28 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// AuthConfig holds user account configuration
+type AuthConfig struct {
+	SessionTTL time.Duration // How long a login stays valid
+}
+
+// LoadAuthConfig loads account configuration from environment variables
+func LoadAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		SessionTTL: getEnvDuration("SESSION_TTL", 7*24*time.Hour),
+	}
+}
+
+// getEnvDuration gets a duration environment variable, e.g. "12h", with a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,21 @@
+package config
+
+import "os"
+
+// ContentConfig holds module content configuration
+type ContentConfig struct {
+	ModulesDir       string // Directory to load modules from; empty uses embedded content when available
+	RunnerDockerfile string // Path to the module runner Dockerfile; empty uses the embedded copy
+	Watch            bool   // Reload modules when files in ModulesDir change
+	HistoryDir       string // Directory to persist module versions in; empty keeps them in memory
+}
+
+// LoadContentConfig loads content configuration from environment variables
+func LoadContentConfig() *ContentConfig {
+	return &ContentConfig{
+		ModulesDir:       os.Getenv("MODULES_DIR"),
+		RunnerDockerfile: os.Getenv("RUNNER_DOCKERFILE"),
+		Watch:            getEnvBool("MODULES_WATCH", true),
+		HistoryDir:       os.Getenv("MODULE_HISTORY_DIR"),
+	}
+}
@@ -11,6 +11,7 @@ type DockerConfig struct {
 	MemoryLimit       int64  // Memory limit in bytes
 	CPULimit          int64  // CPU limit (quota/period)
 	ExecutionTimeout  int    // Execution timeout in seconds
+	TestTimeout       int    // Per-test timeout in milliseconds
 	NetworkDisabled   bool   // Disable network access
 	ReadOnlyRootFS    bool   // Use read-only root filesystem
 }
@@ -22,6 +23,7 @@ func LoadDockerConfig() *DockerConfig {
 		MemoryLimit:       getEnvInt64("DOCKER_MEMORY_LIMIT", 128*1024*1024), // 128MB default
 		CPULimit:          getEnvInt64("DOCKER_CPU_LIMIT", 50000),             // 50% CPU default
 		ExecutionTimeout:  getEnvInt("DOCKER_EXECUTION_TIMEOUT", 30),          // 30 seconds default
+		TestTimeout:       getEnvInt("DOCKER_TEST_TIMEOUT", 5000),             // 5 seconds per test default
 		NetworkDisabled:   getEnvBool("DOCKER_NETWORK_DISABLED", true),
 		ReadOnlyRootFS:    getEnvBool("DOCKER_READONLY_ROOTFS", false),
 	}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// LTIConfig registers the LMS platform that may launch modules over LTI 1.3
+type LTIConfig struct {
+	Issuer         string   // Platform issuer (iss claim); empty disables LTI
+	ClientID       string   // Client ID the platform assigned to this tool
+	DeploymentIDs  []string // Deployment IDs to accept; empty accepts any
+	AuthURL        string   // Platform OIDC authorization endpoint
+	KeySetURL      string   // Platform JSON Web Key Set used to verify launches
+	TokenURL       string   // Platform OAuth 2 token endpoint, for grade passback
+	ToolURL        string   // Public URL of this server, e.g. https://lab.example.edu
+	AppURL         string   // Page launches are redirected to; defaults to ToolURL
+	PrivateKeyFile string   // PEM RSA key messages to the platform are signed with; empty generates one at startup
+}
+
+// Enabled reports whether a platform is configured
+func (c *LTIConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// LoadLTIConfig loads the LTI platform registration from environment variables
+func LoadLTIConfig() *LTIConfig {
+	config := &LTIConfig{
+		Issuer:         os.Getenv("LTI_ISSUER"),
+		ClientID:       os.Getenv("LTI_CLIENT_ID"),
+		AuthURL:        os.Getenv("LTI_AUTH_URL"),
+		KeySetURL:      os.Getenv("LTI_JWKS_URL"),
+		TokenURL:       os.Getenv("LTI_TOKEN_URL"),
+		ToolURL:        strings.TrimSuffix(os.Getenv("LTI_TOOL_URL"), "/"),
+		AppURL:         os.Getenv("LTI_APP_URL"),
+		PrivateKeyFile: os.Getenv("LTI_PRIVATE_KEY_FILE"),
+	}
+	for _, id := range strings.Split(os.Getenv("LTI_DEPLOYMENT_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			config.DeploymentIDs = append(config.DeploymentIDs, id)
+		}
+	}
+	if config.AppURL == "" {
+		config.AppURL = config.ToolURL
+	}
+	return config
+}
@@ -0,0 +1,17 @@
+package config
+
+import "os"
+
+// ProgressConfig holds progress tracking configuration
+type ProgressConfig struct {
+	DatabasePath string // SQLite database file for progress, drafts and submissions; ":memory:" keeps them in memory
+}
+
+// LoadProgressConfig loads progress configuration from environment variables
+func LoadProgressConfig() *ProgressConfig {
+	config := &ProgressConfig{DatabasePath: os.Getenv("PROGRESS_DB")}
+	if config.DatabasePath == "" {
+		config.DatabasePath = "data/progress.db"
+	}
+	return config
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/backend2lab/backend2lab/server/config"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// runnerDockerfile is the Dockerfile used to build module runner images
+//
+//go:embed Dockerfile.module-runner
+var runnerDockerfile []byte
+
+// defaultModulesDir is used when no directory is configured and the binary
+// was built without embedded content
+var defaultModulesDir = filepath.Join("src", "modules")
+
+// newContentSource picks where module content comes from: MODULES_DIR when
+// set, otherwise the modules compiled into the binary (built with
+// -tags embed_content), otherwise src/modules in the working directory
+func newContentSource(cfg *config.ContentConfig) (*services.ContentSource, error) {
+	dockerfile := runnerDockerfile
+	if cfg.RunnerDockerfile != "" {
+		data, err := os.ReadFile(cfg.RunnerDockerfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read runner Dockerfile: %w", err)
+		}
+		dockerfile = data
+	}
+
+	if cfg.ModulesDir != "" {
+		return services.NewDirContentSource(cfg.ModulesDir).WithRunnerDockerfile(dockerfile), nil
+	}
+
+	if embedded := embeddedModules(); embedded != nil {
+		logrus.Info("Using module content embedded in the binary")
+		return services.NewFSContentSource(embedded).WithRunnerDockerfile(dockerfile), nil
+	}
+
+	return services.NewDirContentSource(defaultModulesDir).WithRunnerDockerfile(dockerfile), nil
+}
@@ -0,0 +1,20 @@
+//go:build embed_content
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed src/modules
+var moduleFiles embed.FS
+
+// embeddedModules returns the module content compiled into the binary
+func embeddedModules() fs.FS {
+	modules, err := fs.Sub(moduleFiles, "src/modules")
+	if err != nil {
+		return nil
+	}
+	return modules
+}
@@ -0,0 +1,11 @@
+//go:build !embed_content
+
+package main
+
+import "io/fs"
+
+// embeddedModules returns nil: this binary was built without embedded
+// content (build with -tags embed_content to include src/modules)
+func embeddedModules() fs.FS {
+	return nil
+}
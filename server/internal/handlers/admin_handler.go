@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminHandler struct {
+	moduleService    services.ModuleServiceInterface
+	selfCheckService services.SelfCheckServiceInterface
+}
+
+func NewAdminHandler(moduleService services.ModuleServiceInterface, selfCheckService services.SelfCheckServiceInterface) *AdminHandler {
+	return &AdminHandler{
+		moduleService:    moduleService,
+		selfCheckService: selfCheckService,
+	}
+}
+
+// ReloadModules rebuilds the module catalog from disk
+func (h *AdminHandler) ReloadModules(c *gin.Context) {
+	status, err := h.moduleService.Reload()
+	if err != nil {
+		logrus.Errorf("Failed to reload modules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload modules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// RunSelfCheck runs one module's solution and starter code through the test
+// runner and reports expected vs actual outcomes. Modules are checked one
+// per request so a request stays short; the runs wait their turn with
+// learners' test runs.
+func (h *AdminHandler) RunSelfCheck(c *gin.Context) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return
+	}
+
+	report, err := h.selfCheckService.Run([]string{moduleId})
+	if errors.Is(err, services.ErrModulesNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Self-check of %s failed: %v", moduleId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Self-check failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportModule downloads a module as a bundle (.tar.gz)
+func (h *AdminHandler) ExportModule(c *gin.Context) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return
+	}
+
+	// Build the bundle in memory so failures can still be reported as JSON
+	var bundle bytes.Buffer
+	err := h.moduleService.ExportModule(moduleId, &bundle)
+	if errors.Is(err, services.ErrModuleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to export module %s: %v", moduleId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export module"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", moduleId+".tar.gz"))
+	c.Data(http.StatusOK, "application/gzip", bundle.Bytes())
+}
+
+// ImportModule installs a module from an uploaded bundle. The bundle is read
+// from the "bundle" form field or, failing that, the raw request body.
+func (h *AdminHandler) ImportModule(c *gin.Context) {
+	opts := models.ImportOptions{
+		OnConflict: c.DefaultQuery("onConflict", models.ConflictReject),
+		TargetID:   c.Query("targetId"),
+	}
+
+	switch opts.OnConflict {
+	case models.ConflictReject, models.ConflictReplace, models.ConflictRenumber:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "onConflict must be reject, replace or renumber"})
+		return
+	}
+	if opts.TargetID != "" && !ValidateModuleId(opts.TargetID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if file, err := c.FormFile("bundle"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read bundle"})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := h.moduleService.ImportModule(body, opts)
+	switch {
+	case errors.Is(err, services.ErrModuleExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrInvalidBundle):
+		response := gin.H{"error": err.Error()}
+		if result != nil && result.Validation != nil {
+			response["validation"] = result.Validation
+		}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	case errors.Is(err, services.ErrContentReadOnly):
+		c.JSON(http.StatusConflict, gin.H{"error": "Module content is read-only"})
+		return
+	case err != nil:
+		logrus.Errorf("Failed to import module: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import module"})
+		return
+	}
+
+	logrus.Infof("Imported module %s (from %s)", result.ModuleID, result.OriginalID)
+	c.JSON(http.StatusCreated, result)
+}
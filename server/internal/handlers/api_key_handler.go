@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// ListKeys lists the signed-in user's API keys without the keys themselves
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	keys, err := h.apiKeyService.ListKeys(user.ID)
+	if err != nil {
+		respondAPIKeyError(c, "load API keys", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateKey issues the signed-in user an API key from {"name": "...",
+// "scopes": [...]}. The key is only ever returned here.
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var request models.APIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(user.ID, request)
+	if err != nil {
+		respondAPIKeyError(c, "create API key", err)
+		return
+	}
+
+	logrus.Infof("Created API key %s for %s", key.ID, user.ID)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, key)
+}
+
+// RotateKey replaces one of the signed-in user's API keys with a new key,
+// which is only ever returned here
+func (h *APIKeyHandler) RotateKey(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	key, err := h.apiKeyService.RotateKey(user.ID, c.Param("keyId"))
+	if err != nil {
+		respondAPIKeyError(c, "rotate API key", err)
+		return
+	}
+
+	logrus.Infof("Rotated API key %s of %s", key.ID, user.ID)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, key)
+}
+
+// RevokeKey deletes one of the signed-in user's API keys
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	if err := h.apiKeyService.RevokeKey(user.ID, c.Param("keyId")); err != nil {
+		respondAPIKeyError(c, "revoke API key", err)
+		return
+	}
+
+	logrus.Infof("Revoked API key %s of %s", c.Param("keyId"), user.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// respondAPIKeyError maps API key errors to HTTP responses
+func respondAPIKeyError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case errors.Is(err, services.ErrInvalidAPIKeyRequest):
+		// The message names the offending field, e.g. "name must be 1 to 100 characters"
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logrus.Errorf("Failed to %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type AuthHandler struct {
+	accountService services.AccountServiceInterface
+}
+
+func NewAuthHandler(accountService services.AccountServiceInterface) *AuthHandler {
+	return &AuthHandler{
+		accountService: accountService,
+	}
+}
+
+// Signup creates an account and returns a session for it
+func (h *AuthHandler) Signup(c *gin.Context) {
+	var credentials models.Credentials
+	if err := c.ShouldBindJSON(&credentials); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	session, err := h.accountService.Signup(credentials)
+	switch {
+	case errors.Is(err, services.ErrEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+	case errors.Is(err, services.ErrInvalidAccount):
+		// The message names the offending field, e.g. "password must be 8 to 72 characters"
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		logrus.Errorf("Failed to sign up: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+	default:
+		logrus.Infof("Created account %s", session.User.ID)
+		c.JSON(http.StatusCreated, session)
+	}
+}
+
+// Login returns a new session for an email and password
+func (h *AuthHandler) Login(c *gin.Context) {
+	var credentials models.Credentials
+	if err := c.ShouldBindJSON(&credentials); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	session, err := h.accountService.Login(credentials)
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	case err != nil:
+		logrus.Errorf("Failed to log in: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+	default:
+		c.JSON(http.StatusOK, session)
+	}
+}
+
+// Logout ends the session the request was made with
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.accountService.Logout(middleware.BearerToken(c)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login required"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Me returns the signed-in user
+func (h *AuthHandler) Me(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login required"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// ListUsers returns every account
+func (h *AuthHandler) ListUsers(c *gin.Context) {
+	users, err := h.accountService.ListUsers()
+	if err != nil {
+		logrus.Errorf("Failed to list users: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// SetUserRole changes a user's role from {"role": "student|instructor|admin"}
+func (h *AuthHandler) SetUserRole(c *gin.Context) {
+	var request struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role is required"})
+		return
+	}
+
+	user, err := h.accountService.SetRole(c.Param("userId"), request.Role)
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, services.ErrInvalidAccount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		logrus.Errorf("Failed to change role of %s: %v", c.Param("userId"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+	default:
+		logrus.Infof("Changed role of %s to %s", user.ID, user.Role)
+		c.JSON(http.StatusOK, user)
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxModuleFileBody bounds request bodies for file writes; the service
+// enforces the actual file size limit
+const maxModuleFileBody = 6 * 1024 * 1024
+
+type AuthoringHandler struct {
+	moduleService    services.ModuleServiceInterface
+	authoringService services.ModuleAuthoringServiceInterface
+}
+
+func NewAuthoringHandler(moduleService services.ModuleServiceInterface, authoringService services.ModuleAuthoringServiceInterface) *AuthoringHandler {
+	return &AuthoringHandler{
+		moduleService:    moduleService,
+		authoringService: authoringService,
+	}
+}
+
+// ListModules returns every module, including drafts
+func (h *AuthoringHandler) ListModules(c *gin.Context) {
+	modules, err := h.moduleService.GetAllModules()
+	if err != nil {
+		logrus.Errorf("Failed to load modules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load modules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, modules)
+}
+
+// GetModule returns a module's content, including drafts
+func (h *AuthoringHandler) GetModule(c *gin.Context) {
+	moduleId, ok := moduleIdParam(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.moduleService.GetModuleContent(moduleId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// CreateModule creates a draft module from module.json fields
+func (h *AuthoringHandler) CreateModule(c *gin.Context) {
+	var module models.Module
+	if err := c.ShouldBindJSON(&module); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := h.authoringService.CreateModule(module)
+	if err != nil {
+		h.respondError(c, "create module", result, err)
+		return
+	}
+
+	logrus.Infof("Created module %s", result.ModuleID)
+	c.JSON(http.StatusCreated, result)
+}
+
+// UpdateModule updates module.json with the fields in the request body
+func (h *AuthoringHandler) UpdateModule(c *gin.Context) {
+	moduleId, ok := moduleIdParam(c)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := h.authoringService.UpdateModule(moduleId, patch)
+	if err != nil {
+		h.respondError(c, "update module", result, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PublishModule makes a module visible to learners once it passes validation
+func (h *AuthoringHandler) PublishModule(c *gin.Context) {
+	h.setStatus(c, models.ModuleStatusPublished)
+}
+
+// UnpublishModule turns a module back into a draft
+func (h *AuthoringHandler) UnpublishModule(c *gin.Context) {
+	h.setStatus(c, models.ModuleStatusDraft)
+}
+
+func (h *AuthoringHandler) setStatus(c *gin.Context, status string) {
+	moduleId, ok := moduleIdParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.authoringService.SetModuleStatus(moduleId, status)
+	if err != nil {
+		h.respondError(c, "change module status", result, err)
+		return
+	}
+
+	logrus.Infof("Module %s is now %s", moduleId, status)
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteModule removes a module and its files
+func (h *AuthoringHandler) DeleteModule(c *gin.Context) {
+	moduleId, ok := moduleIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authoringService.DeleteModule(moduleId); err != nil {
+		h.respondError(c, "delete module", nil, err)
+		return
+	}
+
+	logrus.Infof("Deleted module %s", moduleId)
+	c.Status(http.StatusNoContent)
+}
+
+// GetModuleFile returns the raw contents of a module file
+func (h *AuthoringHandler) GetModuleFile(c *gin.Context) {
+	moduleId, ok := moduleIdParam(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.authoringService.ReadModuleFile(moduleId, filePathParam(c))
+	if err != nil {
+		h.respondError(c, "read module file", nil, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", data)
+}
+
+// PutModuleFile writes the request body to a lab or exercise file
+func (h *AuthoringHandler) PutModuleFile(c *gin.Context) {
+	moduleId, ok := moduleIdParam(c)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxModuleFileBody))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+		return
+	}
+
+	result, err := h.authoringService.WriteModuleFile(moduleId, filePathParam(c), data)
+	if err != nil {
+		h.respondError(c, "write module file", result, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteModuleFile removes a lab or exercise file
+func (h *AuthoringHandler) DeleteModuleFile(c *gin.Context) {
+	moduleId, ok := moduleIdParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.authoringService.DeleteModuleFile(moduleId, filePathParam(c))
+	if err != nil {
+		h.respondError(c, "delete module file", result, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// respondError maps authoring errors to responses, including the validation
+// report when a change was rejected
+func (h *AuthoringHandler) respondError(c *gin.Context, action string, result *models.AuthoringResult, err error) {
+	switch {
+	case errors.Is(err, services.ErrModuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+	case errors.Is(err, fs.ErrNotExist):
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+	case errors.Is(err, services.ErrModuleExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrContentReadOnly):
+		c.JSON(http.StatusConflict, gin.H{"error": "Module content is read-only"})
+	case errors.Is(err, services.ErrInvalidModule):
+		response := gin.H{"error": err.Error()}
+		if result != nil && result.Validation != nil {
+			response["validation"] = result.Validation
+		}
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		logrus.Errorf("Failed to %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+	}
+}
+
+// moduleIdParam validates the moduleId path parameter, responding with 400
+// when it is malformed
+func moduleIdParam(c *gin.Context) (string, bool) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return "", false
+	}
+	return moduleId, true
+}
+
+// filePathParam returns the module-relative path from a *path wildcard
+func filePathParam(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("path"), "/")
+}
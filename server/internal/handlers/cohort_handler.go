@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type CohortHandler struct {
+	cohortService services.CohortServiceInterface
+}
+
+func NewCohortHandler(cohortService services.CohortServiceInterface) *CohortHandler {
+	return &CohortHandler{
+		cohortService: cohortService,
+	}
+}
+
+// ListMyCohorts returns the cohorts the signed-in user is enrolled in with
+// their assigned modules and due dates
+func (h *CohortHandler) ListMyCohorts(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	cohorts, err := h.cohortService.ListCohorts(user.ID)
+	if err != nil {
+		respondCohortError(c, "load cohorts", "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohorts)
+}
+
+// ListCohorts returns every cohort to admins and the cohorts they teach to
+// instructors
+func (h *CohortHandler) ListCohorts(c *gin.Context) {
+	userId := ""
+	if middleware.CurrentRole(c) != models.RoleAdmin {
+		user, _ := middleware.CurrentUser(c)
+		userId = user.ID
+	}
+
+	cohorts, err := h.cohortService.ListCohorts(userId)
+	if err != nil {
+		respondCohortError(c, "load cohorts", "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohorts)
+}
+
+// CreateCohort creates a cohort from {"name", "description"}, taught by the
+// signed-in user
+func (h *CohortHandler) CreateCohort(c *gin.Context) {
+	var request struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	creatorId := ""
+	if user, ok := middleware.CurrentUser(c); ok {
+		creatorId = user.ID
+	}
+	cohort, err := h.cohortService.CreateCohort(request.Name, request.Description, creatorId)
+	if err != nil {
+		respondCohortError(c, "create cohort", "", err)
+		return
+	}
+
+	logrus.Infof("Created cohort %s (%s)", cohort.ID, cohort.Name)
+	c.JSON(http.StatusCreated, cohort)
+}
+
+// GetCohort returns a cohort with its members and assignments
+func (h *CohortHandler) GetCohort(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	cohort, err := h.cohortService.GetCohort(cohortId)
+	if err != nil {
+		respondCohortError(c, "load cohort", cohortId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// DeleteCohort deletes a cohort; its students keep their progress
+func (h *CohortHandler) DeleteCohort(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cohortService.DeleteCohort(cohortId); err != nil {
+		respondCohortError(c, "delete cohort", cohortId, err)
+		return
+	}
+
+	logrus.Infof("Deleted cohort %s", cohortId)
+	c.Status(http.StatusNoContent)
+}
+
+// EnrollMember enrolls a user from {"email", "role": "student|instructor"}.
+// Enrolling someone already in the cohort changes their role.
+func (h *CohortHandler) EnrollMember(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Email string `json:"email" binding:"required"`
+		Role  string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required"})
+		return
+	}
+	if request.Role == "" {
+		request.Role = models.RoleStudent
+	}
+
+	cohort, err := h.cohortService.EnrollMember(cohortId, request.Email, request.Role)
+	if err != nil {
+		respondCohortError(c, "enroll member", cohortId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// RemoveMember unenrolls a user from a cohort
+func (h *CohortHandler) RemoveMember(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	cohort, err := h.cohortService.RemoveMember(cohortId, c.Param("userId"))
+	if err != nil {
+		respondCohortError(c, "remove member", cohortId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// AssignModule assigns a module to a cohort from {"dueAt": "RFC 3339 time"};
+// without dueAt the module has no due date
+func (h *CohortHandler) AssignModule(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		DueAt *time.Time `json:"dueAt"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dueAt must be an RFC 3339 time"})
+			return
+		}
+	}
+
+	cohort, err := h.cohortService.AssignModule(cohortId, c.Param("moduleId"), request.DueAt)
+	if err != nil {
+		respondCohortError(c, "assign module", cohortId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// UnassignModule removes a module from a cohort's assignments
+func (h *CohortHandler) UnassignModule(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	cohort, err := h.cohortService.UnassignModule(cohortId, c.Param("moduleId"))
+	if err != nil {
+		respondCohortError(c, "unassign module", cohortId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohort)
+}
+
+// GetReport returns each student's progress and latest test results on the
+// cohort's assigned modules
+func (h *CohortHandler) GetReport(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.cohortService.GetReport(cohortId)
+	if err != nil {
+		respondCohortError(c, "build cohort report", cohortId, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportGradebook downloads the cohort report as a CSV gradebook with a row
+// per student and a status and score column per assigned module
+func (h *CohortHandler) ExportGradebook(c *gin.Context) {
+	cohortId, ok := h.managedCohortId(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.cohortService.GetReport(cohortId)
+	if err != nil {
+		respondCohortError(c, "build cohort report", cohortId, err)
+		return
+	}
+	var gradebook bytes.Buffer
+	if err := services.WriteGradebookCSV(&gradebook, report); err != nil {
+		respondCohortError(c, "export gradebook", cohortId, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "gradebook-"+cohortId+".csv"))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", gradebook.Bytes())
+}
+
+// managedCohortId returns the cohort ID from the path when the request may
+// manage it: admins manage every cohort, instructors the ones they teach.
+// Other cohorts answer 404 so their existence isn't revealed.
+func (h *CohortHandler) managedCohortId(c *gin.Context) (string, bool) {
+	cohortId := c.Param("cohortId")
+	if middleware.CurrentRole(c) == models.RoleAdmin {
+		return cohortId, true
+	}
+
+	user, _ := middleware.CurrentUser(c)
+	teaches, err := h.cohortService.IsInstructor(cohortId, user.ID)
+	if err == nil && !teaches {
+		err = services.ErrCohortNotFound
+	}
+	if err != nil {
+		respondCohortError(c, "load cohort", cohortId, err)
+		return "", false
+	}
+	return cohortId, true
+}
+
+// respondCohortError maps cohort errors to HTTP responses
+func respondCohortError(c *gin.Context, action, cohortId string, err error) {
+	switch {
+	case errors.Is(err, services.ErrCohortNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cohort not found"})
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, services.ErrInvalidCohort):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondExerciseError(c, action, cohortId, err)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDraftBody bounds request bodies for draft saves; the service enforces
+// the actual draft size limit
+const maxDraftBody = 2 * services.MaxDraftSize
+
+type DraftHandler struct {
+	moduleService services.ModuleServiceInterface
+	draftService  services.DraftServiceInterface
+}
+
+func NewDraftHandler(moduleService services.ModuleServiceInterface, draftService services.DraftServiceInterface) *DraftHandler {
+	return &DraftHandler{
+		moduleService: moduleService,
+		draftService:  draftService,
+	}
+}
+
+// ListDrafts returns the signed-in user's saved drafts of a module's files
+func (h *DraftHandler) ListDrafts(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	drafts, err := h.draftService.ListDrafts(user.ID, moduleId)
+	if err != nil {
+		respondExerciseError(c, "load drafts", moduleId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, drafts)
+}
+
+// GetDraft returns the signed-in user's draft of an editor file, or the
+// starter file at revision 0 when they haven't saved one
+func (h *DraftHandler) GetDraft(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	draft, err := h.draftService.GetDraft(user.ID, moduleId, c.Query("exerciseId"), c.Param("file"))
+	if err != nil {
+		respondDraftError(c, "load draft", moduleId, draft, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, draft)
+}
+
+// SaveDraft saves an editor file from {"content": "...", "revision": N},
+// where revision is the one the content was based on. Saving over a newer
+// revision answers 409 with the latest draft.
+func (h *DraftHandler) SaveDraft(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	var request struct {
+		Content  *string `json:"content" binding:"required"`
+		Revision *int    `json:"revision" binding:"required,min=0"`
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxDraftBody)
+	if err := c.ShouldBindJSON(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Draft is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Content and a revision of at least 0 are required"})
+		return
+	}
+
+	draft, err := h.draftService.SaveDraft(user.ID, moduleId, c.Query("exerciseId"), c.Param("file"), *request.Content, *request.Revision)
+	if err != nil {
+		respondDraftError(c, "save draft", moduleId, draft, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// ResetDraft replaces the signed-in user's draft with the module's starter file
+func (h *DraftHandler) ResetDraft(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	draft, err := h.draftService.ResetDraft(user.ID, moduleId, c.Query("exerciseId"), c.Param("file"))
+	if err != nil {
+		respondDraftError(c, "reset draft", moduleId, draft, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// respondDraftError maps draft errors to HTTP responses. Conflicts include
+// the latest draft so the client can merge.
+func respondDraftError(c *gin.Context, action, moduleId string, latest *models.Draft, err error) {
+	switch {
+	case errors.Is(err, services.ErrDraftConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Draft was saved from somewhere else", "draft": latest})
+	case errors.Is(err, services.ErrDraftTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Draft is too large"})
+	case errors.Is(err, services.ErrInvalidDraftFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File must be one of server, test or package"})
+	default:
+		respondExerciseError(c, action, moduleId, err)
+	}
+}
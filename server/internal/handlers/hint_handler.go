@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HintHandler struct {
+	moduleService services.ModuleServiceInterface
+	hintService   services.HintServiceInterface
+}
+
+func NewHintHandler(moduleService services.ModuleServiceInterface, hintService services.HintServiceInterface) *HintHandler {
+	return &HintHandler{
+		moduleService: moduleService,
+		hintService:   hintService,
+	}
+}
+
+// GetHints lists the hints the learner has revealed for an exercise
+func (h *HintHandler) GetHints(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	learnerId, ok := requireLearnerId(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.hintService.GetHints(moduleId, c.Query("exerciseId"), learnerId)
+	if err != nil {
+		respondExerciseError(c, "load hints", moduleId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// RevealNextHint reveals the learner's next hint for an exercise, optionally
+// the next one about a failing test
+func (h *HintHandler) RevealNextHint(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	learnerId, ok := requireLearnerId(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		ExerciseID string `json:"exerciseId"`
+		Test       string `json:"test"`
+	}
+
+	// The body is optional; an empty body reveals the first exercise's next hint
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	progress, err := h.hintService.RevealNextHint(moduleId, request.ExerciseID, learnerId, request.Test)
+	if err != nil {
+		respondExerciseError(c, "load hints", moduleId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetHintUsage reports how often each of a module's hints was revealed
+func (h *HintHandler) GetHintUsage(c *gin.Context) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return
+	}
+
+	report, err := h.hintService.UsageReport(moduleId)
+	if err != nil {
+		respondExerciseError(c, "load hints", moduleId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// learnerId identifies the learner making a request: the signed-in user, or
+// else the anonymous learner named in the learner ID header, under
+// AnonymousLearnerPrefix. It is empty when there is neither.
+func learnerId(c *gin.Context) string {
+	if user, ok := middleware.CurrentUser(c); ok {
+		return user.ID
+	}
+	if id := c.GetHeader(LearnerIdHeader); ValidateLearnerId(id) {
+		return AnonymousLearnerPrefix + id
+	}
+	return ""
+}
+
+// requireLearnerId identifies the learner, responding with an error when the
+// request is anonymous and has no valid learner ID header
+func requireLearnerId(c *gin.Context) (string, bool) {
+	id := learnerId(c)
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Log in or send a valid " + LearnerIdHeader + " header"})
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ltiStateCookiePrefix starts the name of the cookie binding a login's state
+// to the browser that started it. Each login has its own cookie, so logins
+// in several tabs don't overwrite each other.
+const ltiStateCookiePrefix = "lti_state_"
+
+type LTIHandler struct {
+	ltiService services.LTIServiceInterface
+}
+
+func NewLTIHandler(ltiService services.LTIServiceInterface) *LTIHandler {
+	return &LTIHandler{
+		ltiService: ltiService,
+	}
+}
+
+// GetKeySet returns the tool's public keys, which the platform verifies deep
+// linking responses and grade passback requests with
+func (h *LTIHandler) GetKeySet(c *gin.Context) {
+	if !h.ltiService.Enabled() {
+		respondLTIError(c, "load key set", services.ErrLTIDisabled)
+		return
+	}
+	c.JSON(http.StatusOK, h.ltiService.KeySet())
+}
+
+// Login answers the platform's third-party initiated login, sent as query
+// parameters or a form, by redirecting to the platform's authorization endpoint
+func (h *LTIHandler) Login(c *gin.Context) {
+	var request models.LTILoginRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login request"})
+		return
+	}
+
+	login, err := h.ltiService.BeginLogin(request)
+	if err != nil {
+		respondLTIError(c, "start LTI login", err)
+		return
+	}
+
+	// The launch is posted from the platform's site, so the cookie has to be
+	// sent cross-site
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(ltiStateCookiePrefix+login.State, login.State, int(time.Until(login.ExpiresAt).Seconds()), "/", "", true, true)
+	c.Redirect(http.StatusFound, login.RedirectURL)
+}
+
+// Launch validates the id_token the platform posts after a login, signs its
+// user in and redirects to the app with their session token
+func (h *LTIHandler) Launch(c *gin.Context) {
+	idToken := c.PostForm("id_token")
+	if idToken == "" {
+		// Platforms report failed authorizations with an error instead
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Launch is missing id_token", "platformError": c.PostForm("error")})
+		return
+	}
+
+	// Only the browser that started the login may complete it, so nobody can
+	// sign a victim in with a launch of their own
+	state := c.PostForm("state")
+	cookie, err := c.Cookie(ltiStateCookiePrefix + state)
+	if state == "" || err != nil || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Launch was not started in this browser"})
+		return
+	}
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(ltiStateCookiePrefix+state, "", -1, "/", "", true, true)
+
+	launch, err := h.ltiService.Launch(idToken, state)
+	if err != nil {
+		respondLTIError(c, "launch", err)
+		return
+	}
+
+	logrus.Infof("LTI %s for %s", launch.MessageType, launch.User.ID)
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, launch.RedirectURL)
+}
+
+// DeepLink links the modules picked from {"moduleIds": [...]} in the
+// platform, answering with the signed response the browser posts back to it
+func (h *LTIHandler) DeepLink(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var request struct {
+		ModuleIDs []string `json:"moduleIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "moduleIds is required"})
+		return
+	}
+	for _, moduleId := range request.ModuleIDs {
+		if !ValidateModuleId(moduleId) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+			return
+		}
+	}
+
+	response, err := h.ltiService.DeepLink(c.Param("deepLinkId"), user.ID, request.ModuleIDs)
+	if err != nil {
+		respondLTIError(c, "link modules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondLTIError maps LTI errors to HTTP responses
+func respondLTIError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrLTIDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": "LTI is not configured"})
+	case errors.Is(err, services.ErrDeepLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deep linking request not found"})
+	case errors.Is(err, services.ErrModuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+	case errors.Is(err, services.ErrInvalidToken):
+		logrus.Warnf("Rejected LTI %s: %v", action, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid id_token"})
+	case errors.Is(err, services.ErrInvalidLTIRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logrus.Errorf("Failed to %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+	}
+}
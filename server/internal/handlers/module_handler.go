@@ -1,8 +1,18 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
 
+	"github.com/backend2lab/backend2lab/server/internal/models"
 	"github.com/backend2lab/backend2lab/server/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -19,16 +29,74 @@ func NewModuleHandler(moduleService services.ModuleServiceInterface) *ModuleHand
 	}
 }
 
-// GetAllModules returns all available modules
+// maxModulePageSize limits how many modules a single page can hold
+const maxModulePageSize = 100
+
+// GetAllModules returns the published modules, optionally filtered by tags,
+// difficulty and search text and split into pages. The number of matching
+// modules is sent in the X-Total-Count header.
 func (h *ModuleHandler) GetAllModules(c *gin.Context) {
-	modules, err := h.moduleService.GetAllModules()
+	query, err := parseModuleQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.moduleService.SearchModules(query)
 	if err != nil {
 		logrus.Errorf("Failed to load modules: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load modules"})
 		return
 	}
 
-	c.JSON(http.StatusOK, modules)
+	// Hints are only handed out one at a time by the hint endpoints
+	for i := range page.Results {
+		page.Results[i].Module = page.Results[i].Module.WithoutHints()
+	}
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	c.JSON(http.StatusOK, page.Results)
+}
+
+// parseModuleQuery reads the tags, difficulty, q, page and pageSize query
+// parameters. Tags and difficulty may be repeated or comma separated.
+func parseModuleQuery(c *gin.Context) (models.ModuleQuery, error) {
+	query := models.ModuleQuery{
+		Tags:       queryList(c, "tags"),
+		Difficulty: queryList(c, "difficulty"),
+		Text:       strings.TrimSpace(c.Query("q")),
+	}
+
+	if value := c.Query("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return query, fmt.Errorf("page must be a positive number")
+		}
+		query.Page = page
+	}
+	if value := c.Query("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > maxModulePageSize {
+			return query, fmt.Errorf("pageSize must be between 1 and %d", maxModulePageSize)
+		}
+		query.PageSize = pageSize
+	} else if query.Page > 0 {
+		query.PageSize = maxModulePageSize
+	}
+
+	return query, nil
+}
+
+// queryList collects a query parameter given repeatedly or as a comma separated list
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, value := range c.QueryArray(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
 }
 
 // GetModuleContent returns the content of a specific module
@@ -46,11 +114,275 @@ func (h *ModuleHandler) GetModuleContent(c *gin.Context) {
 	}
 
 	moduleContent, err := h.moduleService.GetModuleContent(moduleId)
+	if err == nil && moduleContent.Module.IsDraft() {
+		err = fmt.Errorf("module %s is a draft", moduleId)
+	}
 	if err != nil {
 		logrus.Errorf("Failed to load module content for %s: %v", moduleId, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
 		return
 	}
 
+	// Clients revalidate with If-None-Match instead of downloading unchanged
+	// content. Rendered responses have their own tag since they carry more.
+	if moduleContent.Version != "" {
+		etag := contentETag(moduleContent.Version, wantsRendered(c))
+		c.Header("ETag", etag)
+		c.Header("Cache-Control", "no-cache")
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	// Solutions are only handed out by the solution endpoint, and hints one
+	// at a time by the hint endpoints
+	moduleContent.ExerciseContent.Solution = ""
+	moduleContent.Module = moduleContent.Module.WithoutHints()
+	moduleContent.Rendered = h.renderedContent(c, moduleContent.LabContent, moduleContent.ExerciseContent.Readme)
+	c.JSON(http.StatusOK, moduleContent)
+}
+
+// GetModuleVersions lists the recorded versions of a module's content
+func (h *ModuleHandler) GetModuleVersions(c *gin.Context) {
+	moduleId, ok := h.publishedModuleId(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.moduleService.GetModuleVersions(moduleId)
+	if err != nil {
+		logrus.Errorf("Failed to load versions for %s: %v", moduleId, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetModuleVersion returns a module's content as it was at an earlier version
+func (h *ModuleHandler) GetModuleVersion(c *gin.Context) {
+	moduleId, ok := h.publishedModuleId(c)
+	if !ok {
+		return
+	}
+
+	version := c.Param("version")
+	moduleContent, err := h.moduleService.GetModuleVersion(moduleId, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module version not found"})
+		return
+	}
+
+	// A version's content never changes
+	c.Header("ETag", contentETag(version, wantsRendered(c)))
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	// Solutions are only handed out by the solution endpoint, and hints one
+	// at a time by the hint endpoints
+	moduleContent.ExerciseContent.Solution = ""
+	moduleContent.Module = moduleContent.Module.WithoutHints()
+	moduleContent.Rendered = h.renderedContent(c, moduleContent.LabContent, moduleContent.ExerciseContent.Readme)
 	c.JSON(http.StatusOK, moduleContent)
 }
+
+// GetModuleExercises lists a module's exercises in order
+func (h *ModuleHandler) GetModuleExercises(c *gin.Context) {
+	moduleId, ok := h.publishedModuleId(c)
+	if !ok {
+		return
+	}
+
+	exercises, err := h.moduleService.GetModuleExercises(moduleId)
+	if err != nil {
+		logrus.Errorf("Failed to load exercises for %s: %v", moduleId, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, exercises)
+}
+
+// GetModuleExercise returns the lab and exercise content of one exercise
+func (h *ModuleHandler) GetModuleExercise(c *gin.Context) {
+	moduleId, ok := h.publishedModuleId(c)
+	if !ok {
+		return
+	}
+
+	exercise, err := h.moduleService.GetModuleExercise(moduleId, c.Param("exerciseId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		return
+	}
+
+	exercise.ExerciseContent.Solution = ""
+	exercise.Rendered = h.renderedContent(c, exercise.LabContent, exercise.ExerciseContent.Readme)
+	c.JSON(http.StatusOK, exercise)
+}
+
+// GetLearningPath returns the published modules in prerequisite order
+func (h *ModuleHandler) GetLearningPath(c *gin.Context) {
+	path, err := h.moduleService.GetLearningPath()
+	if err != nil {
+		logrus.Errorf("Failed to load learning path: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load learning path"})
+		return
+	}
+
+	c.JSON(http.StatusOK, path)
+}
+
+// GetNextModule recommends what to study after the modules listed in the
+// completed query parameter, given as repeated or comma separated module IDs
+func (h *ModuleHandler) GetNextModule(c *gin.Context) {
+	completed := queryList(c, "completed")
+	for _, moduleId := range completed {
+		if !ValidateModuleId(moduleId) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+			return
+		}
+	}
+
+	recommendation, err := h.moduleService.RecommendNextModule(completed)
+	if err != nil {
+		logrus.Errorf("Failed to recommend next module: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load learning path"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendation)
+}
+
+// GetModuleDependencies returns the modules a module builds on and the
+// modules that build on it
+func (h *ModuleHandler) GetModuleDependencies(c *gin.Context) {
+	moduleId, ok := h.publishedModuleId(c)
+	if !ok {
+		return
+	}
+
+	dependencies, err := h.moduleService.GetModuleDependencies(moduleId)
+	if err != nil {
+		logrus.Errorf("Failed to load dependencies for %s: %v", moduleId, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dependencies)
+}
+
+// GetModuleAsset serves an image or attachment from a module's assets folder.
+// Images are shown inline; other files are downloaded.
+func (h *ModuleHandler) GetModuleAsset(c *gin.Context) {
+	moduleId, ok := h.publishedModuleId(c)
+	if !ok {
+		return
+	}
+
+	asset, err := h.moduleService.GetModuleAsset(moduleId, strings.TrimPrefix(c.Param("path"), "/"))
+	if errors.Is(err, services.ErrAssetTooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Asset is too large"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
+		return
+	}
+
+	sum := sha256.Sum256(asset.Data)
+	c.Header("ETag", moduleETag(hex.EncodeToString(sum[:8])))
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Content-Type", asset.ContentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	// SVGs and other documents can't run scripts when opened directly
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	disposition := "inline"
+	if !strings.HasPrefix(asset.ContentType, "image/") {
+		disposition = "attachment"
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": path.Base(asset.Path)}))
+
+	// ServeContent answers conditional and range requests
+	http.ServeContent(c.Writer, c.Request, asset.Path, asset.ModTime, bytes.NewReader(asset.Data))
+}
+
+// renderedContent renders the lab and exercise instructions when the request
+// asks for them with ?render=true, and returns nil otherwise
+func (h *ModuleHandler) renderedContent(c *gin.Context, lab, exercise string) *models.RenderedContent {
+	if !wantsRendered(c) {
+		return nil
+	}
+	return &models.RenderedContent{
+		Lab:      h.moduleService.RenderMarkdown(lab),
+		Exercise: h.moduleService.RenderMarkdown(exercise),
+	}
+}
+
+// wantsRendered reports whether a request asks for rendered instructions
+// with ?render=true
+func wantsRendered(c *gin.Context) bool {
+	render, _ := strconv.ParseBool(c.Query("render"))
+	return render
+}
+
+// publishedModuleId validates the moduleId parameter and checks that the
+// module exists and isn't a draft, responding with an error otherwise
+func (h *ModuleHandler) publishedModuleId(c *gin.Context) (string, bool) {
+	return publishedModuleId(c, h.moduleService)
+}
+
+// publishedModuleId validates the moduleId parameter against moduleService,
+// responding with an error when it's invalid, unknown or a draft
+func publishedModuleId(c *gin.Context, moduleService services.ModuleServiceInterface) (string, bool) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return "", false
+	}
+
+	module, err := moduleService.GetModuleById(moduleId)
+	if err != nil || module.IsDraft() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return "", false
+	}
+	return moduleId, true
+}
+
+// moduleETag formats a module version as a strong entity tag
+func moduleETag(version string) string {
+	return `"` + version + `"`
+}
+
+// contentETag is the entity tag of a module version's content, with or
+// without the rendered instructions
+func contentETag(version string, rendered bool) string {
+	if rendered {
+		return moduleETag(version + "-r")
+	}
+	return moduleETag(version)
+}
+
+// etagMatches reports whether an If-None-Match or If-Match header lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondExerciseError maps errors looking up a module's exercise to HTTP
+// responses; action describes what failed, e.g. "load hints"
+func respondExerciseError(c *gin.Context, action, moduleId string, err error) {
+	switch {
+	case errors.Is(err, services.ErrModuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+	case errors.Is(err, services.ErrExerciseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+	default:
+		logrus.Errorf("Failed to %s for %s: %v", action, moduleId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+	}
+}
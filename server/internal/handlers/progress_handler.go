@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ProgressHandler struct {
+	moduleService   services.ModuleServiceInterface
+	progressService services.ProgressServiceInterface
+}
+
+func NewProgressHandler(moduleService services.ModuleServiceInterface, progressService services.ProgressServiceInterface) *ProgressHandler {
+	return &ProgressHandler{
+		moduleService:   moduleService,
+		progressService: progressService,
+	}
+}
+
+// GetDashboard returns the signed-in user's progress on every module
+func (h *ProgressHandler) GetDashboard(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	dashboard, err := h.progressService.GetDashboard(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to load progress for %s: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GetModuleProgress returns the signed-in user's progress on one module
+func (h *ProgressHandler) GetModuleProgress(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	progress, err := h.progressService.GetModuleProgress(user.ID, moduleId)
+	if err != nil {
+		logrus.Errorf("Failed to load progress on %s for %s: %v", moduleId, user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetCompletionSummary counts how many users started and completed a module
+func (h *ProgressHandler) GetCompletionSummary(c *gin.Context) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return
+	}
+
+	summary, err := h.progressService.GetCompletionSummary(moduleId)
+	if err != nil {
+		respondExerciseError(c, "load completion summary", moduleId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
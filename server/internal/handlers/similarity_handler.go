@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SimilarityHandler struct {
+	similarityService services.SimilarityServiceInterface
+}
+
+func NewSimilarityHandler(similarityService services.SimilarityServiceInterface) *SimilarityHandler {
+	return &SimilarityHandler{
+		similarityService: similarityService,
+	}
+}
+
+// GetSimilarityReport compares learners' latest submissions to a module, or
+// to the exerciseId query parameter, reporting pairs and solution matches at
+// least as similar as the threshold query parameter (0 to 1)
+func (h *SimilarityHandler) GetSimilarityReport(c *gin.Context) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return
+	}
+
+	var threshold float64
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be above 0 and at most 1"})
+			return
+		}
+		threshold = parsed
+	}
+
+	report, err := h.similarityService.Detect(moduleId, c.Query("exerciseId"), threshold)
+	if err != nil {
+		respondExerciseError(c, "compare submissions", moduleId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type SolutionHandler struct {
+	moduleService   services.ModuleServiceInterface
+	solutionService services.SolutionServiceInterface
+}
+
+func NewSolutionHandler(moduleService services.ModuleServiceInterface, solutionService services.SolutionServiceInterface) *SolutionHandler {
+	return &SolutionHandler{
+		moduleService:   moduleService,
+		solutionService: solutionService,
+	}
+}
+
+// GetSolution returns an exercise's solution once the module's solution
+// policy lets the learner see it, and 403 with what's left to do otherwise
+func (h *SolutionHandler) GetSolution(c *gin.Context) {
+	moduleId, ok := publishedModuleId(c, h.moduleService)
+	if !ok {
+		return
+	}
+	learnerId, ok := requireLearnerId(c)
+	if !ok {
+		return
+	}
+
+	access, err := h.solutionService.GetSolution(moduleId, c.Query("exerciseId"), learnerId)
+	if err != nil {
+		respondExerciseError(c, "load solution", moduleId, err)
+		return
+	}
+
+	// Availability changes with every attempt, so responses aren't cached
+	c.Header("Cache-Control", "no-store")
+	if !access.Available {
+		c.JSON(http.StatusForbidden, access)
+		return
+	}
+	c.JSON(http.StatusOK, access)
+}
+
+// ReleaseSolution makes an exercise's solution available to every learner
+func (h *SolutionHandler) ReleaseSolution(c *gin.Context) {
+	h.setReleased(c, true)
+}
+
+// WithdrawSolution returns an exercise's solution to its module's policy
+func (h *SolutionHandler) WithdrawSolution(c *gin.Context) {
+	h.setReleased(c, false)
+}
+
+func (h *SolutionHandler) setReleased(c *gin.Context, released bool) {
+	moduleId := c.Param("moduleId")
+	if !ValidateModuleId(moduleId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID format"})
+		return
+	}
+
+	access, err := h.solutionService.SetSolutionReleased(moduleId, c.Query("exerciseId"), released)
+	if err != nil {
+		respondExerciseError(c, "load solution", moduleId, err)
+		return
+	}
+
+	logrus.Infof("Solution of %s/%s released: %t", moduleId, access.ExerciseID, released)
+	c.JSON(http.StatusOK, access)
+}
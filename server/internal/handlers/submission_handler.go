@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultSubmissionLimit is how many submissions are listed without a limit
+	defaultSubmissionLimit = 50
+	// maxSubmissionLimit limits how many submissions a single list can hold
+	maxSubmissionLimit = 200
+)
+
+type SubmissionHandler struct {
+	submissionService services.SubmissionServiceInterface
+}
+
+func NewSubmissionHandler(submissionService services.SubmissionServiceInterface) *SubmissionHandler {
+	return &SubmissionHandler{
+		submissionService: submissionService,
+	}
+}
+
+// ListSubmissions lists the learner's submissions, newest first, optionally
+// for one module and exercise
+func (h *SubmissionHandler) ListSubmissions(c *gin.Context) {
+	learnerId, ok := requireLearnerId(c)
+	if !ok {
+		return
+	}
+	h.list(c, learnerId)
+}
+
+// GetSubmission returns one of the learner's submissions with its code and
+// test results
+func (h *SubmissionHandler) GetSubmission(c *gin.Context) {
+	learnerId, ok := requireLearnerId(c)
+	if !ok {
+		return
+	}
+	h.get(c, learnerId)
+}
+
+// DiffSubmissions compares one of the learner's submissions with an earlier
+// one, by default their previous attempt at the exercise
+func (h *SubmissionHandler) DiffSubmissions(c *gin.Context) {
+	learnerId, ok := requireLearnerId(c)
+	if !ok {
+		return
+	}
+	h.diff(c, learnerId)
+}
+
+// ExportSubmission downloads one of the learner's submissions as a JUnit
+// XML report
+func (h *SubmissionHandler) ExportSubmission(c *gin.Context) {
+	learnerId, ok := requireLearnerId(c)
+	if !ok {
+		return
+	}
+	h.junit(c, learnerId)
+}
+
+// ListAllSubmissions lists every learner's submissions, optionally filtered
+// by learnerId, moduleId and exerciseId
+func (h *SubmissionHandler) ListAllSubmissions(c *gin.Context) {
+	h.list(c, c.Query("learnerId"))
+}
+
+// GetAnySubmission returns any learner's submission
+func (h *SubmissionHandler) GetAnySubmission(c *gin.Context) {
+	h.get(c, "")
+}
+
+// ExportAnySubmission downloads any learner's submission as a JUnit XML report
+func (h *SubmissionHandler) ExportAnySubmission(c *gin.Context) {
+	h.junit(c, "")
+}
+
+// DiffAnySubmissions compares any two submissions
+func (h *SubmissionHandler) DiffAnySubmissions(c *gin.Context) {
+	h.diff(c, "")
+}
+
+// list lists the submissions of learnerId, or of everyone when it is empty
+func (h *SubmissionHandler) list(c *gin.Context, learnerId string) {
+	filter, err := parseSubmissionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.LearnerID = learnerId
+
+	submissions, err := h.submissionService.List(filter)
+	if err != nil {
+		logrus.Errorf("Failed to load submissions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load submissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+// parseSubmissionFilter reads the moduleId, exerciseId and limit query parameters
+func parseSubmissionFilter(c *gin.Context) (models.SubmissionFilter, error) {
+	filter := models.SubmissionFilter{
+		ModuleID:   c.Query("moduleId"),
+		ExerciseID: c.Query("exerciseId"),
+		Limit:      defaultSubmissionLimit,
+	}
+	if filter.ModuleID != "" && !ValidateModuleId(filter.ModuleID) {
+		return filter, errors.New("Invalid module ID format")
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSubmissionLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxSubmissionLimit)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// get returns a submission if it belongs to learnerId, or any submission when
+// learnerId is empty
+func (h *SubmissionHandler) get(c *gin.Context, learnerId string) {
+	submission, ok := h.load(c, learnerId)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, submission)
+}
+
+// junit downloads a submission's test results as JUnit XML if it belongs to
+// learnerId, or any submission's when learnerId is empty
+func (h *SubmissionHandler) junit(c *gin.Context, learnerId string) {
+	submission, ok := h.load(c, learnerId)
+	if !ok {
+		return
+	}
+
+	var report bytes.Buffer
+	if err := services.WriteJUnitXML(&report, submission); err != nil {
+		respondSubmissionError(c, "export submission", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "submission-"+submission.ID+".xml"))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", report.Bytes())
+}
+
+// load looks up the submission in the path, answering 404 unless it belongs
+// to learnerId or learnerId is empty
+func (h *SubmissionHandler) load(c *gin.Context, learnerId string) (*models.Submission, bool) {
+	submission, err := h.submissionService.Get(c.Param("submissionId"))
+	if err == nil && learnerId != "" && submission.LearnerID != learnerId {
+		err = services.ErrSubmissionNotFound
+	}
+	if err != nil {
+		respondSubmissionError(c, "load submission", err)
+		return nil, false
+	}
+	return submission, true
+}
+
+// diff compares two submissions if both belong to learnerId, or any two when
+// learnerId is empty
+func (h *SubmissionHandler) diff(c *gin.Context, learnerId string) {
+	diff, err := h.submissionService.Diff(learnerId, c.Query("from"), c.Param("submissionId"))
+	if errors.Is(err, services.ErrInvalidDiff) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only an earlier submission to the same exercise can be compared"})
+		return
+	}
+	if err != nil {
+		respondSubmissionError(c, "compare submissions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// respondSubmissionError answers 404 for unknown submissions, including other
+// learners' ones, and 500 for anything else
+func respondSubmissionError(c *gin.Context, action string, err error) {
+	if errors.Is(err, services.ErrSubmissionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+	logrus.Errorf("Failed to %s: %v", action, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+}
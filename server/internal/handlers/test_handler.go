@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/backend2lab/backend2lab/server/internal/middleware"
 	"github.com/backend2lab/backend2lab/server/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -10,12 +11,24 @@ import (
 )
 
 type TestHandler struct {
-	testRunner services.TestRunnerInterface
+	testRunner        services.TestRunnerInterface
+	moduleService     services.ModuleServiceInterface
+	hintService       services.HintServiceInterface
+	solutionService   services.SolutionServiceInterface
+	progressService   services.ProgressServiceInterface
+	submissionService services.SubmissionServiceInterface
+	ltiService        services.LTIServiceInterface
 }
 
-func NewTestHandler(testRunner services.TestRunnerInterface) *TestHandler {
+func NewTestHandler(testRunner services.TestRunnerInterface, moduleService services.ModuleServiceInterface, hintService services.HintServiceInterface, solutionService services.SolutionServiceInterface, progressService services.ProgressServiceInterface, submissionService services.SubmissionServiceInterface, ltiService services.LTIServiceInterface) *TestHandler {
 	return &TestHandler{
-		testRunner: testRunner,
+		testRunner:        testRunner,
+		moduleService:     moduleService,
+		hintService:       hintService,
+		solutionService:   solutionService,
+		progressService:   progressService,
+		submissionService: submissionService,
+		ltiService:        ltiService,
 	}
 }
 
@@ -34,7 +47,8 @@ func (h *TestHandler) RunTests(c *gin.Context) {
 	}
 
 	var request struct {
-		Code string `json:"code" binding:"required"`
+		Code       string `json:"code" binding:"required"`
+		ExerciseID string `json:"exerciseId"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -42,13 +56,67 @@ func (h *TestHandler) RunTests(c *gin.Context) {
 		return
 	}
 
-	testResult, err := h.testRunner.RunTests(moduleId, request.Code)
+	// If-Match pins the module version the client was working against, so
+	// code isn't graded against tests that changed in the meantime
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		content, err := h.moduleService.GetModuleContent(moduleId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+			return
+		}
+		if !etagMatches(ifMatch, contentETag(content.Version, false)) && !etagMatches(ifMatch, contentETag(content.Version, true)) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{
+				"error":         "Module content has changed",
+				"moduleVersion": content.Version,
+			})
+			return
+		}
+	}
+
+	if !h.exerciseExists(c, moduleId, request.ExerciseID) {
+		return
+	}
+
+	testResult, err := h.testRunner.RunExerciseTests(moduleId, request.ExerciseID, request.Code)
 	if err != nil {
 		logrus.Errorf("Test execution failed for module %s: %v", moduleId, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Test execution failed"})
 		return
 	}
 
+	// History, hints and attempts are a convenience; results are returned regardless
+	learner := learnerId(c)
+	submission, err := h.submissionService.Record(learner, moduleId, request.ExerciseID, request.Code, testResult)
+	if err != nil {
+		logrus.Errorf("Failed to record submission for module %s: %v", moduleId, err)
+	} else {
+		testResult.SubmissionID = submission.ID
+	}
+	if err := h.hintService.SuggestHints(moduleId, request.ExerciseID, learner, testResult); err != nil {
+		logrus.Warnf("Failed to suggest hints for module %s: %v", moduleId, err)
+	}
+	if learner != "" {
+		if err := h.solutionService.RecordAttempt(moduleId, request.ExerciseID, learner, testResult); err != nil {
+			logrus.Warnf("Failed to record attempt for module %s: %v", moduleId, err)
+		}
+	}
+	if user, ok := middleware.CurrentUser(c); ok {
+		progress, err := h.progressService.RecordResult(user.ID, moduleId, request.ExerciseID, testResult)
+		if err != nil {
+			logrus.Errorf("Failed to record progress on module %s for %s: %v", moduleId, user.ID, err)
+		}
+		testResult.Progress = progress
+
+		// Passing the score back to an LMS waits on the platform, so it
+		// happens after responding
+		passback := *testResult
+		go func() {
+			if err := h.ltiService.PassbackScore(user.ID, moduleId, &passback); err != nil {
+				logrus.Warnf("Failed to pass back score on module %s for %s: %v", moduleId, user.ID, err)
+			}
+		}()
+	}
+
 	c.JSON(http.StatusOK, testResult)
 }
 
@@ -67,7 +135,8 @@ func (h *TestHandler) RunCode(c *gin.Context) {
 	}
 
 	var request struct {
-		Code string `json:"code" binding:"required"`
+		Code       string `json:"code" binding:"required"`
+		ExerciseID string `json:"exerciseId"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -75,7 +144,11 @@ func (h *TestHandler) RunCode(c *gin.Context) {
 		return
 	}
 
-	runResult, err := h.testRunner.RunCode(moduleId, request.Code)
+	if !h.exerciseExists(c, moduleId, request.ExerciseID) {
+		return
+	}
+
+	runResult, err := h.testRunner.RunExerciseCode(moduleId, request.ExerciseID, request.Code)
 	if err != nil {
 		logrus.Errorf("Code execution failed for module %s: %v", moduleId, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Code execution failed"})
@@ -84,3 +157,16 @@ func (h *TestHandler) RunCode(c *gin.Context) {
 
 	c.JSON(http.StatusOK, runResult)
 }
+
+// exerciseExists checks that a requested exercise belongs to the module,
+// responding with 404 when it doesn't. No exercise selects the first one.
+func (h *TestHandler) exerciseExists(c *gin.Context, moduleId, exerciseId string) bool {
+	if exerciseId == "" {
+		return true
+	}
+	if _, err := h.moduleService.GetModuleExercise(moduleId, exerciseId); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		return false
+	}
+	return true
+}
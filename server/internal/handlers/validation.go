@@ -10,3 +10,24 @@ func ValidateModuleId(moduleId string) bool {
 	matched, err := regexp.MatchString(pattern, moduleId)
 	return err == nil && matched
 }
+
+// learnerIdPattern matches the anonymous learner IDs clients generate and
+// send in the X-Learner-ID header, e.g. a UUID
+var learnerIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// accountIdPattern matches the IDs of user accounts
+var accountIdPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// LearnerIdHeader identifies the learner whose progress a request belongs to
+const LearnerIdHeader = "X-Learner-ID"
+
+// AnonymousLearnerPrefix starts the learner ID of every anonymous learner.
+// Learner IDs sent in LearnerIdHeader are stored under it, so they can never
+// name a user account.
+const AnonymousLearnerPrefix = "anon-"
+
+// ValidateLearnerId validates a learner ID sent by a client. IDs shaped like
+// account IDs are rejected; accounts are only identified by their session.
+func ValidateLearnerId(learnerId string) bool {
+	return learnerIdPattern.MatchString(learnerId) && !accountIdPattern.MatchString(learnerId)
+}
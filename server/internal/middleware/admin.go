@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminTokenKey is the gin.Context key marking requests made with the admin token
+const adminTokenKey = "adminToken"
+
+// AdminToken returns a gin.HandlerFunc that lets requests carrying the admin
+// token as a bearer token act as an admin, e.g. for scripts or to promote
+// the first admin account. No token disables it.
+func AdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(BearerToken(c)), []byte(token)) == 1 {
+			c.Set(adminTokenKey, true)
+		}
+		c.Next()
+	}
+}
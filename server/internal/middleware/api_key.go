@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// APIKeyAuthenticator resolves an API key to its user, returning
+// services.ErrInvalidAPIKey for keys that don't sign anyone in
+type APIKeyAuthenticator interface {
+	AuthenticateKey(secret string) (*models.User, *models.APIKey, error)
+}
+
+// APIKey returns a gin.HandlerFunc that signs requests carrying an API key
+// as a bearer token in as the key's user, as long as the key has the scope
+// of the route (see APIKeyScope). Unlike session tokens, which fall through
+// to the admin token, bad API keys are rejected at once. It must run after
+// routing, so on a route group.
+func APIKey(authenticator APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := BearerToken(c)
+		if !strings.HasPrefix(secret, models.APIKeyPrefix) {
+			c.Next()
+			return
+		}
+
+		user, key, err := authenticator.AuthenticateKey(secret)
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			return
+		}
+		if err != nil {
+			logrus.Errorf("Failed to check API key: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+			return
+		}
+
+		scope, ok := APIKeyScope(c.Request.Method, c.FullPath())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys can't be used for this request"})
+			return
+		}
+		if !key.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
+			return
+		}
+
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// APIKeyScope returns the scope an API key needs for a route: admin for the
+// admin API, code:run to run code or tests, and content:read to read modules
+// and the learning path. Anything else, such as a user's progress,
+// submissions, drafts and cohorts, and managing API keys, needs a session.
+func APIKeyScope(method, route string) (string, bool) {
+	switch {
+	case strings.HasPrefix(route, "/api/admin/"):
+		return models.APIKeyScopeAdmin, true
+	case method == http.MethodPost && (route == "/api/test/:moduleId" || route == "/api/run/:moduleId"):
+		return models.APIKeyScopeRunCode, true
+	case (method == http.MethodGet || method == http.MethodHead) &&
+		(route == "/api/modules" || strings.HasPrefix(route, "/api/modules/") || strings.HasPrefix(route, "/api/learning-path")):
+		return models.APIKeyScopeReadContent, true
+	}
+	return "", false
+}
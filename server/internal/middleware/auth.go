@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userKey is the gin.Context key the signed-in user is stored under
+const userKey = "user"
+
+// Authenticator resolves a session token to the user it belongs to
+type Authenticator interface {
+	Authenticate(token string) (*models.User, error)
+}
+
+// BearerToken returns the bearer token of a request's Authorization header
+func BearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
+
+// Authenticate returns a gin.HandlerFunc that attaches the user of a valid
+// session token to the context. Requests without one continue anonymously,
+// since the Authorization header also carries the admin token on admin routes.
+func Authenticate(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := BearerToken(c); token != "" {
+			if user, err := authenticator.Authenticate(token); err == nil {
+				c.Set(userKey, user)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireUser returns a gin.HandlerFunc that rejects requests without a
+// signed-in user. It must run after Authenticate.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentUser(c); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Login required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireValidSession returns a gin.HandlerFunc for routes that serve either a
+// signed-in user or an anonymous learner. Requests carrying a bearer token
+// that didn't sign anyone in, e.g. an expired session, are rejected rather
+// than served as anonymous, so an account's data is only ever reached with a
+// session. It must run after Authenticate.
+func RequireValidSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentUser(c); !ok && BearerToken(c) != "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Login required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentUser returns the signed-in user attached by Authenticate
+func CurrentUser(c *gin.Context) (*models.User, bool) {
+	value, ok := c.Get(userKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := value.(*models.User)
+	return user, ok
+}
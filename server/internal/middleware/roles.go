@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentRole returns the role a request is made with: admin for the admin
+// token, otherwise the signed-in user's role, or "" when anonymous
+func CurrentRole(c *gin.Context) string {
+	if c.GetBool(adminTokenKey) {
+		return models.RoleAdmin
+	}
+	if user, ok := CurrentUser(c); ok {
+		return user.Role
+	}
+	return ""
+}
+
+// RequirePermission returns a gin.HandlerFunc that only lets requests through
+// when their role grants permission. It must run after Authenticate and
+// AdminToken.
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := CurrentRole(c)
+		if role == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Login required"})
+			return
+		}
+		if !models.RoleAllows(role, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+		c.Next()
+	}
+}
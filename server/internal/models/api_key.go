@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// API key scopes. A request made with an API key needs the scope of the
+// route it calls, and on admin routes its user's role must still allow it.
+const (
+	APIKeyScopeReadContent = "content:read" // reading modules and the learning path
+	APIKeyScopeRunCode     = "code:run"     // running code and tests
+	APIKeyScopeAdmin       = "admin"        // the admin API
+)
+
+// APIKeyScopes lists every scope in the order they are shown
+var APIKeyScopes = []string{APIKeyScopeReadContent, APIKeyScopeRunCode, APIKeyScopeAdmin}
+
+// APIKeyPrefix starts every API key, telling keys apart from session tokens
+const APIKeyPrefix = "b2l_"
+
+// APIKey is a user's key for scripts and other automated clients. Only a
+// hash of the key itself is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"` // the start of the key, to tell keys apart
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	RotatedAt  *time.Time `json:"rotatedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// HasScope reports whether the key grants a scope
+func (k APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IssuedAPIKey is a newly created or rotated API key with the key itself,
+// which is only shown this once
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyRequest creates an API key
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
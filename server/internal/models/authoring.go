@@ -0,0 +1,11 @@
+package models
+
+// AuthoringResult is returned by authoring changes to a module. Validation
+// describes the module after the change.
+type AuthoringResult struct {
+	ModuleID   string            `json:"moduleId"`
+	Module     *Module           `json:"module,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	Size       int64             `json:"size,omitempty"`
+	Validation *ValidationReport `json:"validation,omitempty"`
+}
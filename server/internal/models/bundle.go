@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// BundleFormatVersion is the module bundle format written by this server
+const BundleFormatVersion = 1
+
+// BundleManifest describes the contents of a module bundle (manifest.json)
+type BundleManifest struct {
+	FormatVersion int          `json:"formatVersion"`
+	ModuleID      string       `json:"moduleId"`
+	Title         string       `json:"title"`
+	ExportedAt    time.Time    `json:"exportedAt"`
+	Files         []BundleFile `json:"files"`
+}
+
+// BundleFile is a file in a module bundle, relative to the module directory
+type BundleFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// How an import handles a module ID that already exists
+const (
+	ConflictReject   = "reject"   // fail the import
+	ConflictReplace  = "replace"  // overwrite the existing module
+	ConflictRenumber = "renumber" // import under the next free module ID
+)
+
+// ImportOptions controls how a module bundle is imported
+type ImportOptions struct {
+	OnConflict string `json:"onConflict"`
+	TargetID   string `json:"targetId,omitempty"`
+}
+
+// ImportResult describes an imported module
+type ImportResult struct {
+	ModuleID   string            `json:"moduleId"`
+	OriginalID string            `json:"originalId"`
+	Renumbered bool              `json:"renumbered"`
+	Replaced   bool              `json:"replaced"`
+	Files      int               `json:"files"`
+	Validation *ValidationReport `json:"validation"`
+}
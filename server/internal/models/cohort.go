@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// CohortMember is a student or instructor enrolled in a cohort. Role is
+// RoleStudent or RoleInstructor.
+type CohortMember struct {
+	UserID     string    `json:"userId"`
+	Email      string    `json:"email,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Role       string    `json:"role"`
+	EnrolledAt time.Time `json:"enrolledAt"`
+}
+
+// CohortAssignment is a module a cohort has to complete, optionally by a due date
+type CohortAssignment struct {
+	ModuleID   string     `json:"moduleId"`
+	Title      string     `json:"title,omitempty"`
+	DueAt      *time.Time `json:"dueAt,omitempty"`
+	AssignedAt time.Time  `json:"assignedAt"`
+}
+
+// Cohort is a group of students taught together. Members are left out when
+// students list their own cohorts.
+type Cohort struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	Members     []CohortMember     `json:"members,omitempty"`
+	Assignments []CohortAssignment `json:"assignments"`
+}
+
+// StudentAssignmentReport is a student's progress on an assigned module and
+// their latest test run of it. Overdue is set when the module wasn't
+// completed by its due date.
+type StudentAssignmentReport struct {
+	ModuleID           string           `json:"moduleId"`
+	Status             string           `json:"status"`
+	Overdue            bool             `json:"overdue"`
+	Progress           *ModuleProgress  `json:"progress,omitempty"`
+	LatestSubmissionID string           `json:"latestSubmissionId,omitempty"`
+	LatestSubmittedAt  *time.Time       `json:"latestSubmittedAt,omitempty"`
+	LatestResult       *TestSuiteResult `json:"latestResult,omitempty"`
+}
+
+// StudentReport is a student's progress on every module assigned to their cohort
+type StudentReport struct {
+	UserID      string                    `json:"userId"`
+	Email       string                    `json:"email,omitempty"`
+	Name        string                    `json:"name,omitempty"`
+	Completed   int                       `json:"completed"`
+	Assignments []StudentAssignmentReport `json:"assignments"`
+}
+
+// AssignmentSummary counts the students who started, completed and missed
+// the due date of an assigned module
+type AssignmentSummary struct {
+	CohortAssignment
+	Started   int `json:"started"`
+	Completed int `json:"completed"`
+	Overdue   int `json:"overdue"`
+}
+
+// CohortReport is the instructor view of a cohort: every student's progress
+// and latest test results on every assigned module
+type CohortReport struct {
+	CohortID    string              `json:"cohortId"`
+	Name        string              `json:"name"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Assignments []AssignmentSummary `json:"assignments"`
+	Students    []StudentReport     `json:"students"`
+}
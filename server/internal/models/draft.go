@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Draft is a user's work in progress on one editor file of an exercise.
+// Revision counts saves from 1; revision 0 is the module's starter file,
+// returned until the user first saves.
+type Draft struct {
+	UserID     string     `json:"userId"`
+	ModuleID   string     `json:"moduleId"`
+	ExerciseID string     `json:"exerciseId"`
+	File       string     `json:"file"`
+	Content    string     `json:"content"`
+	Revision   int        `json:"revision"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+// File returns an editor file by its JSON name, e.g. "server"
+func (f EditorFiles) File(name string) (string, bool) {
+	switch name {
+	case "server":
+		return f.Server, true
+	case "test":
+		return f.Test, true
+	case "package":
+		return f.Package, true
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Hint is a step towards an exercise's solution. Hints are revealed one at a
+// time in order; a hint listing tests is suggested when those tests fail.
+type Hint struct {
+	Text  string   `json:"text"`
+	Tests []string `json:"tests,omitempty"`
+}
+
+// RevealedHint is a hint a learner has revealed. Position is 1-based.
+type RevealedHint struct {
+	Position   int       `json:"position"`
+	Text       string    `json:"text"`
+	Tests      []string  `json:"tests,omitempty"`
+	RevealedAt time.Time `json:"revealedAt"`
+}
+
+// HintProgress lists the hints a learner has revealed for an exercise. Hint
+// is the hint revealed by the request, if any.
+type HintProgress struct {
+	ModuleID   string         `json:"moduleId"`
+	ExerciseID string         `json:"exerciseId"`
+	Total      int            `json:"total"`
+	Remaining  int            `json:"remaining"`
+	Revealed   []RevealedHint `json:"revealed"`
+	Hint       *RevealedHint  `json:"hint,omitempty"`
+}
+
+// HintSuggestion points a failing test at the first hint about it. The hint
+// text is only included once it has been revealed.
+type HintSuggestion struct {
+	Test     string `json:"test"`
+	Position int    `json:"position"`
+	Revealed bool   `json:"revealed"`
+	Text     string `json:"text,omitempty"`
+}
+
+// ExerciseHintUsage counts how often each hint of an exercise was revealed
+type ExerciseHintUsage struct {
+	ExerciseID string `json:"exerciseId"`
+	Learners   int    `json:"learners"`
+	Reveals    []int  `json:"reveals"`
+}
+
+// HintUsageReport summarizes hint use across learners for a module
+type HintUsageReport struct {
+	ModuleID  string              `json:"moduleId"`
+	Exercises []ExerciseHintUsage `json:"exercises"`
+}
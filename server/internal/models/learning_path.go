@@ -0,0 +1,38 @@
+package models
+
+// PathModule is a module's place in the learning path. Prerequisites and
+// Dependents list the module IDs directly linked to it.
+type PathModule struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Difficulty    string   `json:"difficulty"`
+	Position      int      `json:"position"`
+	Prerequisites []string `json:"prerequisites"`
+	Dependents    []string `json:"dependents"`
+}
+
+// LearningPath lists modules so every module comes after its prerequisites.
+// Cycles lists the module IDs along any prerequisite cycle; the modules on a
+// cycle are listed together but can't be ordered among themselves.
+type LearningPath struct {
+	Modules []PathModule `json:"modules"`
+	Cycles  [][]string   `json:"cycles,omitempty"`
+}
+
+// ModuleDependencies describes the modules a module depends on (upstream)
+// and the modules that depend on it (downstream), in learning path order
+type ModuleDependencies struct {
+	Module     PathModule `json:"module"`
+	Upstream   []string   `json:"upstream"`
+	Downstream []string   `json:"downstream"`
+}
+
+// NextModuleRecommendation suggests what to study after the completed modules.
+// Ready lists every module whose prerequisites are all completed; Next is the
+// first of them in learning path order, or nil once everything is done.
+type NextModuleRecommendation struct {
+	Next      *PathModule `json:"next"`
+	Ready     []string    `json:"ready"`
+	Completed []string    `json:"completed"`
+	Remaining int         `json:"remaining"`
+}
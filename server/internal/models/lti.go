@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// LTI 1.3 message types
+const (
+	LTIResourceLinkRequest  = "LtiResourceLinkRequest"
+	LTIDeepLinkingRequest   = "LtiDeepLinkingRequest"
+	LTIDeepLinkingResponse  = "LtiDeepLinkingResponse"
+	LTIVersion              = "1.3.0"
+	LTIScoreScope           = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+	LTIScoreContentType     = "application/vnd.ims.lis.v1.score+json"
+	LTIResourceLinkItemType = "ltiResourceLink"
+)
+
+// JSONWebKey is a public key in a JSON Web Key Set
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is a set of public keys, as published at a JWKS URL
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// LTILoginRequest is a platform's third-party initiated login, the first
+// step of an LTI launch
+type LTILoginRequest struct {
+	Issuer          string `form:"iss"`
+	LoginHint       string `form:"login_hint"`
+	TargetLinkURI   string `form:"target_link_uri"`
+	LTIMessageHint  string `form:"lti_message_hint"`
+	ClientID        string `form:"client_id"`
+	LTIDeploymentID string `form:"lti_deployment_id"`
+}
+
+// LTILogin is a started login: the browser is sent to RedirectURL at the
+// platform, which answers with a launch carrying State until ExpiresAt
+type LTILogin struct {
+	RedirectURL string
+	State       string
+	ExpiresAt   time.Time
+}
+
+// LTILaunch is a completed launch: the user was signed in and is sent to
+// RedirectURL, which carries their session token in its fragment. Deep
+// linking launches have a DeepLinkID to pick modules with.
+type LTILaunch struct {
+	AuthSession
+	MessageType string `json:"messageType"`
+	ModuleID    string `json:"moduleId,omitempty"`
+	DeepLinkID  string `json:"deepLinkId,omitempty"`
+	RedirectURL string `json:"redirectUrl"`
+}
+
+// LTIDeepLinkResponse is the signed message returning picked modules to the
+// platform. The browser has to post JWT as the "JWT" form field to ReturnURL.
+type LTIDeepLinkResponse struct {
+	ReturnURL string `json:"returnUrl"`
+	JWT       string `json:"jwt"`
+}
+
+// LTIGradeLink is where a user's scores on a module are passed back to: the
+// platform's line item for the resource link they launched it from
+type LTIGradeLink struct {
+	UserID    string    `json:"userId"`
+	ModuleID  string    `json:"moduleId"`
+	Subject   string    `json:"subject"` // the user's ID on the platform
+	LineItem  string    `json:"lineItem"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
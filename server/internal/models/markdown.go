@@ -0,0 +1,31 @@
+package models
+
+// RenderedMarkdown is a README rendered on the server. HTML never contains
+// raw HTML from the source; it is escaped like any other text.
+type RenderedMarkdown struct {
+	HTML        string                 `json:"html"`
+	TOC         []TOCEntry             `json:"toc"`
+	CodeBlocks  []CodeBlock            `json:"codeBlocks"`
+	FrontMatter map[string]interface{} `json:"frontMatter,omitempty"`
+}
+
+// TOCEntry is a heading and the anchor (id attribute) it can be linked with
+type TOCEntry struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+// CodeBlock is a fenced code block. Language is the first word of the fence's
+// info string, e.g. "javascript" for ```javascript.
+type CodeBlock struct {
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code"`
+}
+
+// RenderedContent holds the rendered lab and exercise instructions of a module
+// or exercise
+type RenderedContent struct {
+	Lab      *RenderedMarkdown `json:"lab"`
+	Exercise *RenderedMarkdown `json:"exercise"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // ModuleFile represents the file structure for a module
 type ModuleFile struct {
 	Readme    *string `json:"readme,omitempty"`
@@ -20,6 +22,84 @@ type Module struct {
 	Files              ModuleFiles `json:"files"`
 	LearningObjectives []string   `json:"learningObjectives"`
 	Prerequisites      []string   `json:"prerequisites"`
+	Exercises          []Exercise `json:"exercises,omitempty"`
+	Hints              []Hint     `json:"hints,omitempty"`
+	SolutionPolicy     *SolutionPolicy `json:"solutionPolicy,omitempty"`
+	Status             string     `json:"status,omitempty"`
+}
+
+// Exercise types
+const (
+	ExerciseTypeFunction = "function" // the code exports functions the tests call
+	ExerciseTypeServer   = "server"   // the code starts a server the tests send requests to
+)
+
+// DefaultExerciseID identifies the single exercise of a module without an exercises list
+const DefaultExerciseID = "exercise"
+
+// Exercise is one step of a module with its own files, tests and exercise type
+type Exercise struct {
+	ID             string          `json:"id"`
+	Title          string          `json:"title"`
+	Type           string          `json:"type,omitempty"`
+	Lab            *string         `json:"lab,omitempty"`
+	Files          ModuleFile      `json:"files"`
+	Hints          []Hint          `json:"hints,omitempty"`
+	SolutionPolicy *SolutionPolicy `json:"solutionPolicy,omitempty"`
+}
+
+// ExerciseList returns the module's exercises in order. Modules without an
+// exercises list have a single exercise made from files.lab, files.exercise
+// and the module's hints.
+func (m Module) ExerciseList() []Exercise {
+	if len(m.Exercises) > 0 {
+		return m.Exercises
+	}
+	return []Exercise{{
+		ID:    DefaultExerciseID,
+		Title: m.Title,
+		Lab:   m.Files.Lab.Readme,
+		Files: m.Files.Exercise,
+		Hints: m.Hints,
+	}}
+}
+
+// WithoutHints returns a copy of the module without its or its exercises'
+// hints, for learners, who reveal hints one at a time
+func (m Module) WithoutHints() Module {
+	m.Hints = nil
+	if m.Exercises != nil {
+		exercises := make([]Exercise, len(m.Exercises))
+		for i, exercise := range m.Exercises {
+			exercise.Hints = nil
+			exercises[i] = exercise
+		}
+		m.Exercises = exercises
+	}
+	return m
+}
+
+// SolutionPolicyFor returns the policy controlling access to an exercise's
+// solution: the exercise's own, else the module's, else the default
+func (m Module) SolutionPolicyFor(exercise Exercise) SolutionPolicy {
+	if exercise.SolutionPolicy != nil {
+		return *exercise.SolutionPolicy
+	}
+	if m.SolutionPolicy != nil {
+		return *m.SolutionPolicy
+	}
+	return DefaultSolutionPolicy
+}
+
+// Module publication states. Modules without a status are published.
+const (
+	ModuleStatusDraft     = "draft"
+	ModuleStatusPublished = "published"
+)
+
+// IsDraft reports whether the module is hidden from learners
+func (m Module) IsDraft() bool {
+	return m.Status == ModuleStatusDraft
 }
 
 // ModuleFiles represents the file structure for lab and exercise
@@ -31,15 +111,43 @@ type ModuleFiles struct {
 // ModuleContent represents the full content of a module including file contents
 type ModuleContent struct {
 	Module         Module           `json:"module"`
+	Version        string           `json:"version,omitempty"`
 	LabContent     string           `json:"labContent"`
 	ExerciseContent ExerciseContent `json:"exerciseContent"`
+	Exercises      []ExerciseSummary `json:"exercises"`
+	Rendered       *RenderedContent  `json:"rendered,omitempty"`
+}
+
+// ExerciseSummary identifies an exercise in a module's ordered list
+type ExerciseSummary struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+	Hints int    `json:"hints"`
+}
+
+// ExerciseStep is the full content of one exercise
+type ExerciseStep struct {
+	ModuleID        string          `json:"moduleId"`
+	ExerciseSummary
+	Position        int             `json:"position"`
+	LabContent      string          `json:"labContent"`
+	ExerciseContent ExerciseContent `json:"exerciseContent"`
+	Rendered        *RenderedContent `json:"rendered,omitempty"`
+}
+
+// ModuleVersion describes a version of a module's content
+type ModuleVersion struct {
+	Version    string    `json:"version"`
+	RecordedAt time.Time `json:"recordedAt"`
+	Current    bool      `json:"current"`
 }
 
 // ExerciseContent represents the exercise content with file contents
 type ExerciseContent struct {
 	Readme      string      `json:"readme"`
 	EditorFiles EditorFiles `json:"editorFiles"`
-	Solution    string      `json:"solution"`
+	Solution    string      `json:"solution,omitempty"` // omitted from learner-facing responses
 }
 
 // EditorFiles represents the files shown in the editor
@@ -53,25 +161,50 @@ type EditorFiles struct {
 type TestResult struct {
 	TestName string      `json:"testName"`
 	Passed   bool        `json:"passed"`
+	TimedOut bool        `json:"timedOut,omitempty"`
+	Duration int         `json:"duration,omitempty"`
 	Error    *string     `json:"error,omitempty"`
 	Expected interface{} `json:"expected,omitempty"`
 	Actual   interface{} `json:"actual,omitempty"`
 }
 
+// Test suite statuses
+const (
+	SuiteStatusCompleted = "completed" // every test reported a result
+	SuiteStatusTimedOut  = "timedOut"  // the suite was stopped before it finished
+	SuiteStatusError     = "error"     // the suite could not be set up or run
+)
+
 // TestSuiteResult represents the result of running a test suite
 type TestSuiteResult struct {
-	ModuleID      string       `json:"moduleId"`
-	TotalTests    int          `json:"totalTests"`
-	PassedTests   int          `json:"passedTests"`
-	FailedTests   int          `json:"failedTests"`
-	Results       []TestResult `json:"results"`
-	ExecutionTime int64        `json:"executionTime"`
-	ExerciseType  string       `json:"exerciseType"`
+	ModuleID       string            `json:"moduleId"`
+	ModuleVersion  string            `json:"moduleVersion,omitempty"`
+	ExerciseID     string            `json:"exerciseId,omitempty"`
+	Status         string            `json:"status,omitempty"`
+	TotalTests     int               `json:"totalTests"`
+	PassedTests    int               `json:"passedTests"`
+	FailedTests    int               `json:"failedTests"`
+	TimedOutTests  int               `json:"timedOutTests"`
+	NotRunTests    int               `json:"notRunTests"`
+	StoppedAt      *string           `json:"stoppedAt,omitempty"`
+	Results        []TestResult      `json:"results"`
+	SuggestedHints []HintSuggestion  `json:"suggestedHints,omitempty"`
+	HintsUsed      int               `json:"hintsUsed,omitempty"`
+	Progress       *ExerciseProgress `json:"progress,omitempty"` // the signed-in user's updated progress
+	SubmissionID   string            `json:"submissionId,omitempty"`
+	ExecutionTime  int64             `json:"executionTime"`
+	ExerciseType   string            `json:"exerciseType"`
+}
+
+// Passed reports whether tests ran and all of them passed
+func (r *TestSuiteResult) Passed() bool {
+	return r.TotalTests > 0 && r.PassedTests == r.TotalTests
 }
 
 // RunResult represents the result of running code
 type RunResult struct {
 	ModuleID      string  `json:"moduleId"`
+	ExerciseID    string  `json:"exerciseId,omitempty"`
 	Success       bool    `json:"success"`
 	Message       string  `json:"message"`
 	ExecutionTime int64   `json:"executionTime"`
@@ -115,3 +248,18 @@ type MochaStats struct {
 	End      string `json:"end"`
 	Duration int `json:"duration"`
 }
+
+// CatalogStatus describes the currently loaded module catalog
+type CatalogStatus struct {
+	Modules  int       `json:"modules"`
+	LoadedAt time.Time `json:"loadedAt"`
+}
+
+// ModuleAsset is an image or attachment from a module's assets folder
+type ModuleAsset struct {
+	Path        string    `json:"path"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	Data        []byte    `json:"-"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Progress statuses of a module or exercise
+const (
+	ProgressNotStarted = "not_started"
+	ProgressInProgress = "in_progress"
+	ProgressCompleted  = "completed"
+)
+
+// ExerciseProgress is what a user has achieved on one exercise. Score is the
+// percentage of tests passed; TestsPassed and TotalTests are from the attempt
+// with the best score.
+type ExerciseProgress struct {
+	UserID         string     `json:"userId"`
+	ModuleID       string     `json:"moduleId"`
+	ExerciseID     string     `json:"exerciseId"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	BestScore      int        `json:"bestScore"`
+	TestsPassed    int        `json:"testsPassed"`
+	TotalTests     int        `json:"totalTests"`
+	FirstAttemptAt *time.Time `json:"firstAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+}
+
+// ProgressAttempt is one graded test run to record
+type ProgressAttempt struct {
+	UserID      string
+	ModuleID    string
+	ExerciseID  string
+	TestsPassed int
+	TotalTests  int
+	Passed      bool
+	At          time.Time
+}
+
+// ModuleProgress sums up a user's progress on a module's exercises.
+// BestScore is the average best score across all exercises.
+type ModuleProgress struct {
+	ModuleID           string             `json:"moduleId"`
+	Title              string             `json:"title"`
+	Status             string             `json:"status"`
+	BestScore          int                `json:"bestScore"`
+	TestsPassed        int                `json:"testsPassed"`
+	TotalTests         int                `json:"totalTests"`
+	Attempts           int                `json:"attempts"`
+	CompletedExercises int                `json:"completedExercises"`
+	TotalExercises     int                `json:"totalExercises"`
+	StartedAt          *time.Time         `json:"startedAt,omitempty"`
+	LastAttemptAt      *time.Time         `json:"lastAttemptAt,omitempty"`
+	CompletedAt        *time.Time         `json:"completedAt,omitempty"`
+	Exercises          []ExerciseProgress `json:"exercises"`
+}
+
+// ProgressDashboard is a user's progress across the published modules
+type ProgressDashboard struct {
+	UserID           string           `json:"userId"`
+	CompletedModules int              `json:"completedModules"`
+	StartedModules   int              `json:"startedModules"`
+	TotalModules     int              `json:"totalModules"`
+	Modules          []ModuleProgress `json:"modules"`
+}
+
+// ExerciseCompletion counts the users who attempted and completed an exercise
+type ExerciseCompletion struct {
+	ExerciseID       string  `json:"exerciseId"`
+	Started          int     `json:"started"`
+	Completed        int     `json:"completed"`
+	AverageAttempts  float64 `json:"averageAttempts"`
+	AverageBestScore float64 `json:"averageBestScore"`
+}
+
+// ModuleCompletionSummary counts the users who started and completed a module
+type ModuleCompletionSummary struct {
+	ModuleID       string               `json:"moduleId"`
+	Started        int                  `json:"started"`
+	Completed      int                  `json:"completed"`
+	CompletionRate float64              `json:"completionRate"`
+	Exercises      []ExerciseCompletion `json:"exercises"`
+}
@@ -0,0 +1,56 @@
+package models
+
+// Roles a user can have. Every account starts as a student.
+const (
+	RoleStudent    = "student"
+	RoleInstructor = "instructor"
+	RoleAdmin      = "admin"
+)
+
+// Permission names something a role may do beyond learning
+type Permission string
+
+// Permissions checked by the API
+const (
+	PermissionViewSubmissions  Permission = "submissions:view" // other users' submissions
+	PermissionReleaseSolutions Permission = "solutions:release"
+	PermissionViewReports      Permission = "reports:view" // hint usage and completion
+	PermissionManageCohorts    Permission = "cohorts:manage"
+	PermissionManageContent    Permission = "content:manage"
+	PermissionManageUsers      Permission = "users:manage"
+)
+
+// rolePermissions lists what each role may do; students only learn
+var rolePermissions = map[string][]Permission{
+	RoleStudent: {},
+	RoleInstructor: {
+		PermissionViewSubmissions,
+		PermissionReleaseSolutions,
+		PermissionViewReports,
+		PermissionManageCohorts,
+	},
+	RoleAdmin: {
+		PermissionViewSubmissions,
+		PermissionReleaseSolutions,
+		PermissionViewReports,
+		PermissionManageCohorts,
+		PermissionManageContent,
+		PermissionManageUsers,
+	},
+}
+
+// ValidRole reports whether role is a known role
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleAllows reports whether a role grants a permission
+func RoleAllows(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package models
+
+// ModuleQuery filters, searches and pages the module catalog. Empty fields
+// don't filter; a PageSize of 0 returns every match.
+type ModuleQuery struct {
+	Tags       []string `json:"tags,omitempty"`
+	Difficulty []string `json:"difficulty,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Page       int      `json:"page,omitempty"`
+	PageSize   int      `json:"pageSize,omitempty"`
+}
+
+// Searchable module fields
+const (
+	SearchFieldTitle       = "title"
+	SearchFieldDescription = "description"
+	SearchFieldObjectives  = "learningObjectives"
+	SearchFieldTags        = "tags"
+	SearchFieldLab         = "lab"
+	SearchFieldExercise    = "exercise"
+)
+
+// SearchHighlight is an excerpt of a module field matching the search text.
+// Matches are wrapped in <mark></mark>; the rest of the snippet is HTML escaped.
+type SearchHighlight struct {
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
+}
+
+// ModuleSearchResult is a module matching a query. Score and Highlights are
+// only set for text searches.
+type ModuleSearchResult struct {
+	Module
+	Score      float64           `json:"score,omitempty"`
+	Highlights []SearchHighlight `json:"highlights,omitempty"`
+}
+
+// ModuleSearchPage is one page of modules matching a query
+type ModuleSearchPage struct {
+	Results  []ModuleSearchResult `json:"results"`
+	Total    int                  `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
@@ -0,0 +1,50 @@
+package models
+
+// Self-check outcomes
+const (
+	OutcomePass = "pass"
+	OutcomeFail = "fail"
+)
+
+// SelfCheckRun is the outcome of running one variant (solution or starter) of an exercise
+type SelfCheckRun struct {
+	Expected    string  `json:"expected"`
+	Actual      string  `json:"actual"`
+	Status      string  `json:"status,omitempty"`
+	TotalTests  int     `json:"totalTests"`
+	PassedTests int     `json:"passedTests"`
+	FailedTests int     `json:"failedTests"`
+	Error       *string `json:"error,omitempty"`
+}
+
+// Matches reports whether the actual outcome is the expected one
+func (r SelfCheckRun) Matches() bool {
+	return r.Expected == r.Actual
+}
+
+// SelfCheckExerciseResult holds the solution and starter outcomes for one
+// of a module's exercises
+type SelfCheckExerciseResult struct {
+	ExerciseID string       `json:"exerciseId"`
+	Title      string       `json:"title"`
+	Solution   SelfCheckRun `json:"solution"`
+	Starter    SelfCheckRun `json:"starter"`
+	OK         bool         `json:"ok"`
+}
+
+// SelfCheckModuleResult holds the outcomes for every exercise of a module,
+// in order
+type SelfCheckModuleResult struct {
+	ModuleID  string                    `json:"moduleId"`
+	Title     string                    `json:"title"`
+	Exercises []SelfCheckExerciseResult `json:"exercises"`
+	OK        bool                      `json:"ok"`
+}
+
+// SelfCheckReport is the expected-vs-actual matrix for all checked modules
+type SelfCheckReport struct {
+	Modules     []SelfCheckModuleResult `json:"modules"`
+	Regressions int                     `json:"regressions"`
+	OK          bool                    `json:"ok"`
+	Duration    int64                   `json:"duration"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// SimilarityReport lists learners' submissions to a module that are
+// suspiciously similar to each other or to their exercise's solution. Each
+// learner's latest submission to each exercise is compared.
+type SimilarityReport struct {
+	ModuleID        string          `json:"moduleId"`
+	ExerciseID      string          `json:"exerciseId,omitempty"`
+	Threshold       float64         `json:"threshold"`
+	Submissions     int             `json:"submissions"` // submissions compared
+	Pairs           []SimilarPair   `json:"pairs"`
+	SolutionMatches []SolutionMatch `json:"solutionMatches"`
+	GeneratedAt     time.Time       `json:"generatedAt"`
+}
+
+// SimilarPair is two learners' submissions to the same exercise whose code
+// shares more fingerprints than the threshold allows
+type SimilarPair struct {
+	ExerciseID         string            `json:"exerciseId"`
+	Similarity         float64           `json:"similarity"` // shared fingerprints over all fingerprints of both, 0 to 1
+	SharedFingerprints int               `json:"sharedFingerprints"`
+	First              SimilarSubmission `json:"first"`
+	Second             SimilarSubmission `json:"second"`
+}
+
+// SolutionMatch is a submission whose code is similar to its exercise's
+// solution
+type SolutionMatch struct {
+	ExerciseID         string            `json:"exerciseId"`
+	Similarity         float64           `json:"similarity"`
+	SharedFingerprints int               `json:"sharedFingerprints"`
+	Submission         SimilarSubmission `json:"submission"`
+	SolutionLines      []LineRange       `json:"solutionLines"`
+}
+
+// SimilarSubmission is one side of a match with the lines of its code that
+// the other side shares
+type SimilarSubmission struct {
+	SubmissionID string      `json:"submissionId"`
+	LearnerID    string      `json:"learnerId"`
+	SubmittedAt  time.Time   `json:"submittedAt"`
+	MatchedLines []LineRange `json:"matchedLines"`
+}
+
+// LineRange is a range of lines of code, both ends included and counted from 1
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Solution release rules
+const (
+	SolutionReleasePassed     = "passed"     // after the learner's code passes the tests
+	SolutionReleaseAttempts   = "attempts"   // after a number of test runs, or passing
+	SolutionReleaseTime       = "time"       // some time after the first test run, or passing
+	SolutionReleaseInstructor = "instructor" // only once an instructor releases it
+)
+
+// SolutionPolicy controls when learners can see an exercise's solution. An
+// instructor can release a solution to everyone regardless of the policy.
+type SolutionPolicy struct {
+	Release  string `json:"release"`
+	Attempts int    `json:"attempts,omitempty"`
+	After    string `json:"after,omitempty"` // Go duration, e.g. "30m"
+}
+
+// DefaultSolutionPolicy applies to exercises whose module sets no policy
+var DefaultSolutionPolicy = SolutionPolicy{Release: SolutionReleasePassed}
+
+// SolutionAttempts sums up one learner's test runs on one exercise, which
+// count towards unlocking its solution
+type SolutionAttempts struct {
+	Count   int
+	FirstAt time.Time
+	Passed  bool
+}
+
+// SolutionAccess tells a learner whether an exercise's solution is available,
+// and when locked, what is left to unlock it. Solution is only set when
+// available.
+type SolutionAccess struct {
+	ModuleID    string         `json:"moduleId"`
+	ExerciseID  string         `json:"exerciseId"`
+	Policy      SolutionPolicy `json:"policy"`
+	Available   bool           `json:"available"`
+	Released    bool           `json:"released"`
+	Passed      bool           `json:"passed"`
+	Attempts    int            `json:"attempts"`
+	AvailableAt *time.Time     `json:"availableAt,omitempty"`
+	Solution    string         `json:"solution,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// SubmissionSummary describes a graded test run without its code and results
+type SubmissionSummary struct {
+	ID            string    `json:"id"`
+	LearnerID     string    `json:"learnerId,omitempty"`
+	ModuleID      string    `json:"moduleId"`
+	ExerciseID    string    `json:"exerciseId"`
+	ModuleVersion string    `json:"moduleVersion,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	TotalTests    int       `json:"totalTests"`
+	PassedTests   int       `json:"passedTests"`
+	FailedTests   int       `json:"failedTests"`
+	Passed        bool      `json:"passed"`
+	SubmittedAt   time.Time `json:"submittedAt"`
+}
+
+// Submission is a graded test run: the code that was submitted, the module
+// version it was graded against and the full test results
+type Submission struct {
+	SubmissionSummary
+	Code   string           `json:"code"`
+	Result *TestSuiteResult `json:"result"`
+}
+
+// SubmissionFilter selects submissions to list. Empty fields match
+// everything; Limit caps how many of the newest are returned.
+type SubmissionFilter struct {
+	LearnerID  string
+	ModuleID   string
+	ExerciseID string
+	Limit      int
+}
+
+// CodeDiff is a unified diff between the code of two submissions
+type CodeDiff struct {
+	Unified string `json:"unified"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// TestChanges lists tests by how their outcome changed between two submissions
+type TestChanges struct {
+	Fixed        []string `json:"fixed"`     // failing before, passing now
+	Regressed    []string `json:"regressed"` // passing before, failing now
+	StillFailing []string `json:"stillFailing"`
+	StillPassing []string `json:"stillPassing"`
+	Added        []string `json:"added"`   // only run by the later submission
+	Removed      []string `json:"removed"` // only run by the earlier submission
+}
+
+// SubmissionDiff compares an earlier submission with a later one
+type SubmissionDiff struct {
+	From  SubmissionSummary `json:"from"`
+	To    SubmissionSummary `json:"to"`
+	Code  CodeDiff          `json:"code"`
+	Tests TestChanges       `json:"tests"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// User is an account. Password hashes never leave the account service.
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Credentials are what a learner signs up or logs in with
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`
+}
+
+// AuthSession is a signed-in session. The token is sent back as a bearer
+// token in the Authorization header.
+type AuthSession struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      User      `json:"user"`
+}
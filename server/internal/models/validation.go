@@ -0,0 +1,40 @@
+package models
+
+// Validation issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Validation issue codes
+const (
+	IssueInvalidJSON            = "invalid_json"
+	IssueMissingField           = "missing_field"
+	IssueUnknownField           = "unknown_field"
+	IssueInvalidValue           = "invalid_value"
+	IssueInvalidDifficulty      = "invalid_difficulty"
+	IssueMissingFile            = "missing_file"
+	IssueInvalidPath            = "invalid_path"
+	IssueDuplicateID            = "duplicate_id"
+	IssueIDMismatch             = "id_mismatch"
+	IssueUnresolvedPrerequisite = "unresolved_prerequisite"
+	IssuePrerequisiteCycle      = "prerequisite_cycle"
+)
+
+// ValidationIssue is a single problem found in a module's content
+type ValidationIssue struct {
+	Module   string `json:"module"`
+	Path     string `json:"path,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// ValidationReport is the result of validating all module content
+type ValidationReport struct {
+	Modules  int               `json:"modules"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Issues   []ValidationIssue `json:"issues"`
+	OK       bool              `json:"ok"`
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// AccountStore persists accounts and login sessions. Sessions are stored by
+// the hash of their token.
+type AccountStore interface {
+	// CreateUser stores a new account, or returns ErrEmailTaken when its
+	// email is already registered. An empty password hash never matches.
+	CreateUser(user models.User, passwordHash string) error
+	// GetUser returns the account with a user ID, or ErrUserNotFound
+	GetUser(userId string) (*models.User, error)
+	// FindUserByEmail returns the account registered with a normalized email
+	// and its password hash, or ErrUserNotFound
+	FindUserByEmail(email string) (*models.User, string, error)
+	// ListUsers returns every account, oldest first
+	ListUsers() ([]models.User, error)
+	// SetUserRole changes an account's role, or returns ErrUserNotFound
+	SetUserRole(userId, role string) (*models.User, error)
+
+	CreateSession(tokenHash, userId string, expiresAt time.Time) error
+	// SessionUser returns the account a session belongs to, or
+	// ErrInvalidSession when the session is unknown or expired at now
+	SessionUser(tokenHash string, now time.Time) (*models.User, error)
+	// DeleteSession ends a session, or returns ErrInvalidSession
+	DeleteSession(tokenHash string) error
+	// DeleteExpiredSessions removes sessions that expired by now
+	DeleteExpiredSessions(now time.Time) error
+}
+
+const accountSchema = `
+CREATE TABLE IF NOT EXISTS users (
+	id            TEXT PRIMARY KEY,
+	email         TEXT NOT NULL UNIQUE,
+	name          TEXT NOT NULL,
+	role          TEXT NOT NULL,
+	password_hash TEXT NOT NULL,
+	created_at    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sessions (
+	token_hash TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL REFERENCES users (id),
+	expires_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_expiry ON sessions (expires_at);
+`
+
+const userColumns = `id, email, name, role, created_at`
+
+// SQLiteAccountStore keeps accounts and sessions in a SQLite database
+type SQLiteAccountStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteAccountStore keeps accounts in db, creating its tables if needed
+func NewSQLiteAccountStore(db *sql.DB) (*SQLiteAccountStore, error) {
+	if _, err := db.Exec(accountSchema); err != nil {
+		return nil, fmt.Errorf("failed to create account tables: %w", err)
+	}
+	return &SQLiteAccountStore{db: db}, nil
+}
+
+// CreateUser stores a new account
+func (s *SQLiteAccountStore) CreateUser(user models.User, passwordHash string) error {
+	result, err := s.db.Exec(`INSERT INTO users (id, email, name, role, password_hash, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (email) DO NOTHING`,
+		user.ID, user.Email, user.Name, user.Role, passwordHash, formatSQLiteTime(user.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save account: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %s", ErrEmailTaken, user.Email)
+	}
+	return nil
+}
+
+// GetUser returns the account with a user ID
+func (s *SQLiteAccountStore) GetUser(userId string) (*models.User, error) {
+	user, _, err := scanUser(s.db.QueryRow(`SELECT `+userColumns+`, password_hash FROM users WHERE id = ?`, userId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userId)
+	}
+	return user, err
+}
+
+// FindUserByEmail returns the account registered with an email and its password hash
+func (s *SQLiteAccountStore) FindUserByEmail(email string) (*models.User, string, error) {
+	user, passwordHash, err := scanUser(s.db.QueryRow(`SELECT `+userColumns+`, password_hash FROM users WHERE email = ?`, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", fmt.Errorf("%w: %s", ErrUserNotFound, email)
+	}
+	return user, passwordHash, err
+}
+
+// ListUsers returns every account, oldest first
+func (s *SQLiteAccountStore) ListUsers() ([]models.User, error) {
+	rows, err := s.db.Query(`SELECT ` + userColumns + `, password_hash FROM users ORDER BY created_at, email`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, _, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// SetUserRole changes an account's role
+func (s *SQLiteAccountStore) SetUserRole(userId, role string) (*models.User, error) {
+	result, err := s.db.Exec(`UPDATE users SET role = ? WHERE id = ?`, role, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save account: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userId)
+	}
+	return s.GetUser(userId)
+}
+
+// CreateSession stores a login session
+func (s *SQLiteAccountStore) CreateSession(tokenHash, userId string, expiresAt time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO sessions (token_hash, user_id, expires_at) VALUES (?, ?, ?)`,
+		tokenHash, userId, formatSQLiteTime(expiresAt))
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// SessionUser returns the account a session belongs to
+func (s *SQLiteAccountStore) SessionUser(tokenHash string, now time.Time) (*models.User, error) {
+	user, _, err := scanUser(s.db.QueryRow(`SELECT u.id, u.email, u.name, u.role, u.created_at, u.password_hash
+		FROM sessions s JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = ? AND s.expires_at > ?`, tokenHash, formatSQLiteTime(now)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidSession
+	}
+	return user, err
+}
+
+// DeleteSession ends a session
+func (s *SQLiteAccountStore) DeleteSession(tokenHash string) error {
+	result, err := s.db.Exec(`DELETE FROM sessions WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInvalidSession
+	}
+	return nil
+}
+
+// DeleteExpiredSessions removes sessions that expired by now
+func (s *SQLiteAccountStore) DeleteExpiredSessions(now time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM sessions WHERE expires_at <= ?`, formatSQLiteTime(now)); err != nil {
+		return fmt.Errorf("failed to remove expired sessions: %w", err)
+	}
+	return nil
+}
+
+// scanUser reads a row selected with userColumns and the password hash,
+// passing sql.ErrNoRows through
+func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, string, error) {
+	var user models.User
+	var passwordHash, createdAt string
+	err := row.Scan(&user.ID, &user.Email, &user.Name, &user.Role, &createdAt, &passwordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", err
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read account: %w", err)
+	}
+	if user.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, "", fmt.Errorf("failed to read account: %w", err)
+	}
+	return &user, passwordHash, nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrEmailTaken is returned when signing up with an email that already has an account
+	ErrEmailTaken = errors.New("email already registered")
+	// ErrInvalidCredentials is returned when an email and password don't match an account
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrInvalidSession is returned for unknown, expired or logged out session tokens
+	ErrInvalidSession = errors.New("invalid session")
+	// ErrInvalidAccount is returned when signup details are malformed
+	ErrInvalidAccount = errors.New("invalid account")
+	// ErrUserNotFound is returned for unknown user IDs
+	ErrUserNotFound = errors.New("user not found")
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes, so longer passwords are refused
+	maxPasswordLength = 72
+	maxNameLength     = 100
+)
+
+// AccountService manages local user accounts and login sessions. Sessions are
+// opaque random tokens stored by their hash, so logging out revokes them at
+// once. Accounts and sessions are kept in the store and survive restarts.
+type AccountService struct {
+	store      AccountStore
+	sessionTTL time.Duration
+	now        func() time.Time
+}
+
+// NewAccountService creates an account service backed by store
+func NewAccountService(store AccountStore, sessionTTL time.Duration) *AccountService {
+	return &AccountService{
+		store:      store,
+		sessionTTL: sessionTTL,
+		now:        time.Now,
+	}
+}
+
+// normalizeEmail trims and lowercases an email address, checking that it is one
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", fmt.Errorf("%w: email address is not valid", ErrInvalidAccount)
+	}
+	return email, nil
+}
+
+// randomToken returns n random bytes, hex encoded
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Signup creates an account and signs it in
+func (s *AccountService) Signup(credentials models.Credentials) (*models.AuthSession, error) {
+	email, err := normalizeEmail(credentials.Email)
+	if err != nil {
+		return nil, err
+	}
+	if len(credentials.Password) < minPasswordLength || len(credentials.Password) > maxPasswordLength {
+		return nil, fmt.Errorf("%w: password must be %d to %d characters", ErrInvalidAccount, minPasswordLength, maxPasswordLength)
+	}
+	name := strings.TrimSpace(credentials.Name)
+	if len(name) > maxNameLength {
+		return nil, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidAccount, maxNameLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(credentials.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		ID:        id,
+		Email:     email,
+		Name:      name,
+		Role:      models.RoleStudent,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.store.CreateUser(user, string(hash)); err != nil {
+		return nil, err
+	}
+	return s.startSession(user)
+}
+
+// Login signs in with an email and password
+func (s *AccountService) Login(credentials models.Credentials) (*models.AuthSession, error) {
+	email := strings.ToLower(strings.TrimSpace(credentials.Email))
+
+	user, passwordHash, err := s.store.FindUserByEmail(email)
+	if errors.Is(err, ErrUserNotFound) {
+		// Compare anyway so unknown emails take as long as wrong passwords
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(credentials.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(credentials.Password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return s.startSession(*user)
+}
+
+// dummyPasswordHash is compared against when logging in to an unknown email
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("backend2lab-no-such-account"), bcrypt.DefaultCost)
+	return hash
+})
+
+// startSession creates a session for an account
+func (s *AccountService) startSession(user models.User) (*models.AuthSession, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop expired sessions so abandoned logins don't pile up
+	now := s.now()
+	if err := s.store.DeleteExpiredSessions(now); err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(s.sessionTTL).UTC()
+	if err := s.store.CreateSession(hashToken(token), user.ID, expiresAt); err != nil {
+		return nil, err
+	}
+	return &models.AuthSession{Token: token, ExpiresAt: expiresAt, User: user}, nil
+}
+
+// Logout ends the session of a token
+func (s *AccountService) Logout(token string) error {
+	return s.store.DeleteSession(hashToken(token))
+}
+
+// Authenticate returns the user a session token belongs to
+func (s *AccountService) Authenticate(token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidSession
+	}
+	return s.store.SessionUser(hashToken(token), s.now())
+}
+
+// ListUsers returns every account, oldest first
+func (s *AccountService) ListUsers() ([]models.User, error) {
+	return s.store.ListUsers()
+}
+
+// SetRole changes a user's role. It applies to their existing sessions at once.
+func (s *AccountService) SetRole(userId, role string) (*models.User, error) {
+	if !models.ValidRole(role) {
+		return nil, fmt.Errorf("%w: role must be %s, %s or %s", ErrInvalidAccount, models.RoleStudent, models.RoleInstructor, models.RoleAdmin)
+	}
+	return s.store.SetUserRole(userId, role)
+}
+
+// GetUser returns the account with a user ID
+func (s *AccountService) GetUser(userId string) (*models.User, error) {
+	return s.store.GetUser(userId)
+}
+
+// FindUserByEmail returns the account registered with an email address
+func (s *AccountService) FindUserByEmail(email string) (*models.User, error) {
+	user, _, err := s.store.FindUserByEmail(strings.ToLower(strings.TrimSpace(email)))
+	return user, err
+}
+
+// ProvisionUser creates an account without a password for a user who signs
+// in through another system such as an LMS. It returns ErrEmailTaken when the
+// email already has an account: other systems' emails aren't verified, so
+// existing accounts are never handed out.
+func (s *AccountService) ProvisionUser(email, name, role string) (*models.User, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if !models.ValidRole(role) {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidAccount, role)
+	}
+	name = strings.TrimSpace(name)
+	if len(name) > maxNameLength {
+		name = strings.ToValidUTF8(name[:maxNameLength], "")
+	}
+
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	user := models.User{
+		ID:        id,
+		Email:     email,
+		Name:      name,
+		Role:      role,
+		CreatedAt: s.now().UTC(),
+	}
+	// An empty password hash never matches, so the account can't log in
+	// with a password
+	if err := s.store.CreateUser(user, ""); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// StartSession signs in a user who was authenticated by another system
+func (s *AccountService) StartSession(userId string) (*models.AuthSession, error) {
+	user, err := s.store.GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.startSession(*user)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// newTestAccountService creates an account service keeping accounts in db
+func newTestAccountService(t *testing.T, db *sql.DB) *AccountService {
+	t.Helper()
+	store, err := NewSQLiteAccountStore(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return NewAccountService(store, time.Hour)
+}
+
+func TestAccountService_SignupAndLogin(t *testing.T) {
+	service := newTestAccountService(t, openTestDB(t, ":memory:"))
+
+	signup, err := service.Signup(models.Credentials{Email: " Ada@Example.com ", Password: "analytical", Name: "Ada"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if signup.User.Email != "ada@example.com" || signup.Token == "" {
+		t.Fatalf("Expected a session for the normalized email, got %+v", signup)
+	}
+
+	if _, err := service.Signup(models.Credentials{Email: "ada@example.com", Password: "analytical"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Expected ErrEmailTaken, got %v", err)
+	}
+	for _, credentials := range []models.Credentials{
+		{Email: "not an email", Password: "analytical"},
+		{Email: "grace@example.com", Password: "short"},
+	} {
+		if _, err := service.Signup(credentials); !errors.Is(err, ErrInvalidAccount) {
+			t.Errorf("Expected ErrInvalidAccount for %+v, got %v", credentials, err)
+		}
+	}
+
+	if _, err := service.Login(models.Credentials{Email: "ada@example.com", Password: "wrong password"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+	if _, err := service.Login(models.Credentials{Email: "nobody@example.com", Password: "analytical"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown email, got %v", err)
+	}
+
+	login, err := service.Login(models.Credentials{Email: "ADA@example.com", Password: "analytical"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	user, err := service.Authenticate(login.Token)
+	if err != nil || user.ID != signup.User.ID {
+		t.Fatalf("Expected the token to authenticate the account, got %+v, %v", user, err)
+	}
+
+	if err := service.Logout(login.Token); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.Authenticate(login.Token); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Expected a logged out token to be rejected, got %v", err)
+	}
+	if _, err := service.Authenticate(signup.Token); err != nil {
+		t.Errorf("Expected other sessions to stay valid, got %v", err)
+	}
+}
+
+func TestAccountService_SessionExpiry(t *testing.T) {
+	service := newTestAccountService(t, openTestDB(t, ":memory:"))
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	session, err := service.Signup(models.Credentials{Email: "ada@example.com", Password: "analytical"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	now = now.Add(59 * time.Minute)
+	if _, err := service.Authenticate(session.Token); err != nil {
+		t.Errorf("Expected the session to be valid before it expires, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := service.Authenticate(session.Token); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Expected an expired session to be rejected, got %v", err)
+	}
+}
+
+func TestAccountService_PersistsAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.db")
+	db := openTestDB(t, path)
+	service := newTestAccountService(t, db)
+	signup, err := service.Signup(models.Credentials{Email: "ada@example.com", Password: "analytical"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	db.Close()
+
+	reloaded := newTestAccountService(t, openTestDB(t, path))
+	login, err := reloaded.Login(models.Credentials{Email: "ada@example.com", Password: "analytical"})
+	if err != nil {
+		t.Fatalf("Expected the account to survive a restart, got %v", err)
+	}
+	if login.User.ID != signup.User.ID {
+		t.Errorf("Expected user ID %s, got %s", signup.User.ID, login.User.ID)
+	}
+	if user, err := reloaded.Authenticate(signup.Token); err != nil || user.ID != signup.User.ID {
+		t.Errorf("Expected sessions to survive a restart, got %+v, %v", user, err)
+	}
+}
+
+func TestAccountService_SetRole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.db")
+	db := openTestDB(t, path)
+	service := newTestAccountService(t, db)
+	session, err := service.Signup(models.Credentials{Email: "grace@example.com", Password: "compilers"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if session.User.Role != models.RoleStudent {
+		t.Errorf("Expected new accounts to be students, got %q", session.User.Role)
+	}
+
+	if _, err := service.SetRole(session.User.ID, models.RoleInstructor); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user, _ := service.Authenticate(session.Token); user == nil || user.Role != models.RoleInstructor {
+		t.Errorf("Expected the role to apply to the existing session, got %+v", user)
+	}
+	if _, err := service.SetRole(session.User.ID, "owner"); !errors.Is(err, ErrInvalidAccount) {
+		t.Errorf("Expected ErrInvalidAccount for an unknown role, got %v", err)
+	}
+	if _, err := service.SetRole("missing", models.RoleAdmin); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	db.Close()
+
+	reloaded := newTestAccountService(t, openTestDB(t, path))
+	if users, err := reloaded.ListUsers(); err != nil || len(users) != 1 || users[0].Role != models.RoleInstructor {
+		t.Errorf("Expected the role to survive a restart, got %+v, %v", users, err)
+	}
+}
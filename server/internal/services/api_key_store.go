@@ -0,0 +1,167 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// APIKeyStore persists API keys by the hash of their key
+type APIKeyStore interface {
+	CreateAPIKey(key models.APIKey, keyHash string) error
+	// ListAPIKeys returns a user's keys, oldest first
+	ListAPIKeys(userId string) ([]models.APIKey, error)
+	// FindAPIKey returns the key with a hash, or ErrAPIKeyNotFound
+	FindAPIKey(keyHash string) (*models.APIKey, error)
+	// ReplaceAPIKeyHash gives one of a user's keys a new key, or returns
+	// ErrAPIKeyNotFound
+	ReplaceAPIKeyHash(userId, id, keyHash, hint string, rotatedAt time.Time) (*models.APIKey, error)
+	// DeleteAPIKey deletes one of a user's keys, or returns ErrAPIKeyNotFound
+	DeleteAPIKey(userId, id string) error
+	SetAPIKeyUsed(id string, usedAt time.Time) error
+}
+
+const apiKeySchema = `
+CREATE TABLE IF NOT EXISTS api_keys (
+	id           TEXT PRIMARY KEY,
+	user_id      TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	hint         TEXT NOT NULL,
+	key_hash     TEXT NOT NULL UNIQUE,
+	scopes       TEXT NOT NULL,
+	created_at   TEXT NOT NULL,
+	rotated_at   TEXT,
+	last_used_at TEXT
+);
+CREATE INDEX IF NOT EXISTS api_keys_user ON api_keys (user_id);
+`
+
+const apiKeyColumns = `id, user_id, name, hint, scopes, created_at, rotated_at, last_used_at`
+
+// SQLiteAPIKeyStore keeps API keys in a SQLite database
+type SQLiteAPIKeyStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteAPIKeyStore keeps API keys in db, creating its table if needed
+func NewSQLiteAPIKeyStore(db *sql.DB) (*SQLiteAPIKeyStore, error) {
+	if _, err := db.Exec(apiKeySchema); err != nil {
+		return nil, fmt.Errorf("failed to create API key table: %w", err)
+	}
+	return &SQLiteAPIKeyStore{db: db}, nil
+}
+
+// CreateAPIKey stores a new key
+func (s *SQLiteAPIKeyStore) CreateAPIKey(key models.APIKey, keyHash string) error {
+	_, err := s.db.Exec(`INSERT INTO api_keys (id, user_id, name, hint, key_hash, scopes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key.ID, key.UserID, key.Name, key.Hint, keyHash, strings.Join(key.Scopes, ","), formatSQLiteTime(key.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+	return nil
+}
+
+// ListAPIKeys returns a user's keys, oldest first
+func (s *SQLiteAPIKeyStore) ListAPIKeys(userId string) ([]models.APIKey, error) {
+	rows, err := s.db.Query(`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY created_at, id`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// FindAPIKey returns the key with a hash
+func (s *SQLiteAPIKeyStore) FindAPIKey(keyHash string) (*models.APIKey, error) {
+	key, err := scanAPIKey(s.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// ReplaceAPIKeyHash gives one of a user's keys a new key
+func (s *SQLiteAPIKeyStore) ReplaceAPIKeyHash(userId, id, keyHash, hint string, rotatedAt time.Time) (*models.APIKey, error) {
+	result, err := s.db.Exec(`UPDATE api_keys SET key_hash = ?, hint = ?, rotated_at = ? WHERE id = ? AND user_id = ?`,
+		keyHash, hint, formatSQLiteTime(rotatedAt), id, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrAPIKeyNotFound, id)
+	}
+	return scanAPIKey(s.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id))
+}
+
+// DeleteAPIKey deletes one of a user's keys
+func (s *SQLiteAPIKeyStore) DeleteAPIKey(userId, id string) error {
+	result, err := s.db.Exec(`DELETE FROM api_keys WHERE id = ? AND user_id = ?`, id, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %s", ErrAPIKeyNotFound, id)
+	}
+	return nil
+}
+
+// SetAPIKeyUsed records when a key was last used
+func (s *SQLiteAPIKeyStore) SetAPIKeyUsed(id string, usedAt time.Time) error {
+	if _, err := s.db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, formatSQLiteTime(usedAt), id); err != nil {
+		return fmt.Errorf("failed to record API key use: %w", err)
+	}
+	return nil
+}
+
+// scanAPIKey reads a row selected with apiKeyColumns, passing sql.ErrNoRows through
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes, createdAt string
+	var rotatedAt, lastUsedAt sql.NullString
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Hint, &scopes, &createdAt, &rotatedAt, &lastUsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key: %w", err)
+	}
+
+	key.Scopes = []string{}
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, ",")
+	}
+	if key.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, fmt.Errorf("failed to read API key: %w", err)
+	}
+	for _, field := range []struct {
+		text sql.NullString
+		dest **time.Time
+	}{{rotatedAt, &key.RotatedAt}, {lastUsedAt, &key.LastUsedAt}} {
+		if !field.text.Valid {
+			continue
+		}
+		t, err := parseSQLiteTime(field.text.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API key: %w", err)
+		}
+		*field.dest = &t
+	}
+	return &key, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrAPIKeyNotFound is returned for unknown API key IDs, including other users' keys
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrInvalidAPIKey is returned for unknown, rotated or revoked API keys
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrInvalidAPIKeyRequest is returned when a key's name or scopes are malformed
+	ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+)
+
+const (
+	maxAPIKeyNameLength = 100
+	maxAPIKeysPerUser   = 25
+	// apiKeyHintLength is how much of a key is kept to tell keys apart
+	apiKeyHintLength = len(models.APIKeyPrefix) + 6
+	// apiKeyUsageInterval limits how often a key's last use is written
+	apiKeyUsageInterval = time.Minute
+)
+
+// APIKeyService issues users API keys for automated clients. Keys are random
+// tokens stored as SHA-256 hashes, so a leaked database doesn't leak keys.
+type APIKeyService struct {
+	accountService AccountServiceInterface
+	store          APIKeyStore
+	now            func() time.Time
+}
+
+// NewAPIKeyService creates an API key service for the users of
+// accountService, backed by store
+func NewAPIKeyService(accountService AccountServiceInterface, store APIKeyStore) *APIKeyService {
+	return &APIKeyService{
+		accountService: accountService,
+		store:          store,
+		now:            time.Now,
+	}
+}
+
+// CreateKey issues a user a key with a name and scopes. Only users whose
+// role may manage users, i.e. admins, can create keys with the admin scope.
+func (s *APIKeyService) CreateKey(userId string, request models.APIKeyRequest) (*models.IssuedAPIKey, error) {
+	user, err := s.accountService.GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidAPIKeyRequest, maxAPIKeyNameLength)
+	}
+	for _, scope := range request.Scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			request.Scopes = nil
+			break
+		}
+	}
+	if len(request.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: scopes must be some of %s", ErrInvalidAPIKeyRequest, strings.Join(models.APIKeyScopes, ", "))
+	}
+	// Scopes are kept once each, in a fixed order
+	var scopes []string
+	for _, scope := range models.APIKeyScopes {
+		if slices.Contains(request.Scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if slices.Contains(scopes, models.APIKeyScopeAdmin) && !models.RoleAllows(user.Role, models.PermissionManageUsers) {
+		return nil, fmt.Errorf("%w: only admins can create keys with the %s scope", ErrInvalidAPIKeyRequest, models.APIKeyScopeAdmin)
+	}
+
+	existing, err := s.store.ListAPIKeys(userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return nil, fmt.Errorf("%w: a user can have at most %d API keys", ErrInvalidAPIKeyRequest, maxAPIKeysPerUser)
+	}
+
+	id, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+	key := models.APIKey{
+		ID:        id,
+		UserID:    userId,
+		Name:      name,
+		Hint:      secret[:apiKeyHintLength],
+		Scopes:    scopes,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.store.CreateAPIKey(key, hashToken(secret)); err != nil {
+		return nil, err
+	}
+	return &models.IssuedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// newAPIKeySecret returns a random API key
+func newAPIKeySecret() (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return models.APIKeyPrefix + token, nil
+}
+
+// ListKeys returns a user's keys, oldest first, without the keys themselves
+func (s *APIKeyService) ListKeys(userId string) ([]models.APIKey, error) {
+	return s.store.ListAPIKeys(userId)
+}
+
+// RotateKey replaces the key of one of a user's API keys, keeping its name
+// and scopes. The old key stops working at once.
+func (s *APIKeyService) RotateKey(userId, id string) (*models.IssuedAPIKey, error) {
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.store.ReplaceAPIKeyHash(userId, id, hashToken(secret), secret[:apiKeyHintLength], s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &models.IssuedAPIKey{APIKey: *key, Key: secret}, nil
+}
+
+// RevokeKey deletes one of a user's API keys
+func (s *APIKeyService) RevokeKey(userId, id string) error {
+	return s.store.DeleteAPIKey(userId, id)
+}
+
+// AuthenticateKey returns the user an API key belongs to, with their current
+// role, and the key's details
+func (s *APIKeyService) AuthenticateKey(secret string) (*models.User, *models.APIKey, error) {
+	if !strings.HasPrefix(secret, models.APIKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	key, err := s.store.FindAPIKey(hashToken(secret))
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.accountService.GetUser(key.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := s.now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyUsageInterval {
+		if err := s.store.SetAPIKeyUsed(key.ID, now); err != nil {
+			logrus.Warnf("Failed to record use of API key %s: %v", key.ID, err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return user, key, nil
+}
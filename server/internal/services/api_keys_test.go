@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	db := openTestDB(t, ":memory:")
+	accounts := newTestAccountService(t, db)
+	student, err := accounts.Signup(models.Credentials{Email: "ta@example.com", Password: "permitted"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	store, err := NewSQLiteAPIKeyStore(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := NewAPIKeyService(accounts, store)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	userId := student.User.ID
+
+	for _, request := range []models.APIKeyRequest{
+		{Name: " ", Scopes: []string{models.APIKeyScopeRunCode}},
+		{Name: "grader"},
+		{Name: "grader", Scopes: []string{models.APIKeyScopeRunCode, "everything"}},
+		{Name: "grader", Scopes: []string{models.APIKeyScopeAdmin}},
+	} {
+		if _, err := service.CreateKey(userId, request); !errors.Is(err, ErrInvalidAPIKeyRequest) {
+			t.Errorf("Expected ErrInvalidAPIKeyRequest for %+v, got %v", request, err)
+		}
+	}
+	if _, err := service.CreateKey("missing", models.APIKeyRequest{Name: "grader", Scopes: []string{models.APIKeyScopeRunCode}}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	issued, err := service.CreateKey(userId, models.APIKeyRequest{
+		Name:   " Regrader ",
+		Scopes: []string{models.APIKeyScopeRunCode, models.APIKeyScopeReadContent, models.APIKeyScopeRunCode},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(issued.Key, models.APIKeyPrefix) || !strings.HasPrefix(issued.Key, issued.Hint) || issued.Name != "Regrader" {
+		t.Errorf("Unexpected key: %+v", issued)
+	}
+	if !reflect.DeepEqual(issued.Scopes, []string{models.APIKeyScopeReadContent, models.APIKeyScopeRunCode}) {
+		t.Errorf("Expected scopes once each in order, got %v", issued.Scopes)
+	}
+
+	// Only a hash of the key is stored
+	var stored string
+	db.QueryRow(`SELECT key_hash FROM api_keys WHERE id = ?`, issued.ID).Scan(&stored)
+	if stored == "" || strings.Contains(stored, issued.Key[len(models.APIKeyPrefix):]) {
+		t.Errorf("Expected the key to be stored hashed, got %q", stored)
+	}
+
+	user, key, err := service.AuthenticateKey(issued.Key)
+	if err != nil || user.ID != userId || key.ID != issued.ID || !key.HasScope(models.APIKeyScopeRunCode) {
+		t.Fatalf("Expected the key to sign its user in, got %+v, %+v, %v", user, key, err)
+	}
+	if key.LastUsedAt == nil || !key.LastUsedAt.Equal(now) {
+		t.Errorf("Expected the use to be recorded, got %v", key.LastUsedAt)
+	}
+	for _, secret := range []string{"", "b2l_unknown", issued.Key[len(models.APIKeyPrefix):]} {
+		if _, _, err := service.AuthenticateKey(secret); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Expected ErrInvalidAPIKey for %q, got %v", secret, err)
+		}
+	}
+
+	// Rotating keeps the key's details but replaces the key
+	now = now.Add(time.Hour)
+	rotated, err := service.RotateKey(userId, issued.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rotated.ID != issued.ID || rotated.Key == issued.Key || rotated.RotatedAt == nil || !reflect.DeepEqual(rotated.Scopes, issued.Scopes) {
+		t.Errorf("Unexpected rotated key: %+v", rotated)
+	}
+	if _, _, err := service.AuthenticateKey(issued.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected the old key to stop working, got %v", err)
+	}
+	if _, _, err := service.AuthenticateKey(rotated.Key); err != nil {
+		t.Errorf("Expected the new key to work, got %v", err)
+	}
+
+	// Only admins can create admin keys
+	if _, err := accounts.SetRole(userId, models.RoleInstructor); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.CreateKey(userId, models.APIKeyRequest{Name: "reports", Scopes: []string{models.APIKeyScopeAdmin}}); !errors.Is(err, ErrInvalidAPIKeyRequest) {
+		t.Errorf("Expected ErrInvalidAPIKeyRequest for an instructor, got %v", err)
+	}
+	if _, err := accounts.SetRole(userId, models.RoleAdmin); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.CreateKey(userId, models.APIKeyRequest{Name: "reports", Scopes: []string{models.APIKeyScopeAdmin}}); err != nil {
+		t.Errorf("Expected admins to create admin keys, got %v", err)
+	}
+	keys, err := service.ListKeys(userId)
+	if err != nil || len(keys) != 2 || keys[0].ID != issued.ID || keys[0].Hint != rotated.Hint {
+		t.Errorf("Expected both keys, oldest first, got %+v, %v", keys, err)
+	}
+
+	other, _ := accounts.Signup(models.Credentials{Email: "other@example.com", Password: "permitted"})
+	if _, err := service.RotateKey(other.User.ID, issued.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound for another user's key, got %v", err)
+	}
+	if err := service.RevokeKey(other.User.ID, issued.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound for another user's key, got %v", err)
+	}
+	if err := service.RevokeKey(userId, issued.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, _, err := service.AuthenticateKey(rotated.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected a revoked key to stop working, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+const (
+	// diffContext is how many unchanged lines surround each change
+	diffContext = 3
+	// maxDiffCells bounds the line comparison table; beyond it the changed
+	// middle of the code is shown as removed and re-added as a whole
+	maxDiffCells = 4 << 20
+)
+
+// diffLine is a line of a diff: ' ' unchanged, '-' removed or '+' added
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// diffCode compares two versions of code line by line, returning a unified
+// diff labelled with fromName and toName
+func diffCode(fromName, toName, from, to string) models.CodeDiff {
+	lines := diffLines(splitLines(from), splitLines(to))
+
+	diff := models.CodeDiff{}
+	for _, line := range lines {
+		switch line.kind {
+		case '+':
+			diff.Added++
+		case '-':
+			diff.Removed++
+		}
+	}
+	if diff.Added+diff.Removed > 0 {
+		diff.Unified = unifiedDiff(fromName, toName, lines)
+	}
+	return diff
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines finds a longest common subsequence of lines, after skipping the
+// common prefix and suffix
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	lines = append(lines, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	return lines
+}
+
+func diffMiddle(a, b []string) []diffLine {
+	var lines []diffLine
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, text := range a {
+			lines = append(lines, diffLine{'-', text})
+		}
+		for _, text := range b {
+			lines = append(lines, diffLine{'+', text})
+		}
+		return lines
+	}
+
+	// common[i][j] is the LCS length of a[i:] and b[j:]
+	width := len(b) + 1
+	common := make([]int, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i*width+j] = common[(i+1)*width+j+1] + 1
+			} else {
+				common[i*width+j] = max(common[(i+1)*width+j], common[i*width+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case common[(i+1)*width+j] >= common[i*width+j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+// unifiedDiff formats diff lines as hunks with diffContext lines of context,
+// merging changes that are close together
+func unifiedDiff(fromName, toName string, lines []diffLine) string {
+	// oldLines[k] and newLines[k] count the lines on each side before lines[k]
+	oldLines := make([]int, len(lines)+1)
+	newLines := make([]int, len(lines)+1)
+	for k, line := range lines {
+		oldLines[k+1], newLines[k+1] = oldLines[k], newLines[k]
+		if line.kind != '+' {
+			oldLines[k+1]++
+		}
+		if line.kind != '-' {
+			newLines[k+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for k := 0; k < len(lines); {
+		for k < len(lines) && lines[k].kind == ' ' {
+			k++
+		}
+		if k == len(lines) {
+			break
+		}
+
+		start := max(0, k-diffContext)
+		end := k + 1
+		for j := k + 1; j < len(lines); j++ {
+			if lines[j].kind != ' ' {
+				end = j + 1
+			} else if j-end+1 > 2*diffContext {
+				break
+			}
+		}
+		stop := min(len(lines), end+diffContext)
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(oldLines[start], oldLines[stop]), hunkRange(newLines[start], newLines[stop]))
+		for _, line := range lines[start:stop] {
+			out.WriteByte(line.kind)
+			out.WriteString(line.text)
+			out.WriteByte('\n')
+		}
+		k = stop
+	}
+	return out.String()
+}
+
+// hunkRange formats the lines after line `from` up to line `to` of one side
+// of a hunk. An empty range names the line before it, as diff does.
+func hunkRange(from, to int) string {
+	if to-from == 1 {
+		return fmt.Sprintf("%d", from+1)
+	}
+	if to == from {
+		return fmt.Sprintf("%d,0", from)
+	}
+	return fmt.Sprintf("%d,%d", from+1, to-from)
+}
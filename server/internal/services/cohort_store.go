@@ -0,0 +1,265 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// CohortStore persists cohorts, their members and assigned modules
+type CohortStore interface {
+	CreateCohort(cohort models.Cohort) error
+	// GetCohort returns a cohort with its members and assignments. Members
+	// only have their user ID, role and enrollment time.
+	GetCohort(id string) (*models.Cohort, error)
+	// ListCohorts returns cohorts with their assignments, oldest first: those
+	// userId is enrolled in, or every cohort when it is empty
+	ListCohorts(userId string) ([]models.Cohort, error)
+	DeleteCohort(id string) error
+	// SetMember enrolls a user or changes their role
+	SetMember(cohortId string, member models.CohortMember) error
+	RemoveMember(cohortId, userId string) error
+	// SetAssignment assigns a module or changes its due date
+	SetAssignment(cohortId string, assignment models.CohortAssignment) error
+	RemoveAssignment(cohortId, moduleId string) error
+}
+
+const cohortSchema = `
+CREATE TABLE IF NOT EXISTS cohorts (
+	id          TEXT PRIMARY KEY,
+	name        TEXT NOT NULL,
+	description TEXT NOT NULL,
+	created_at  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS cohort_members (
+	cohort_id   TEXT NOT NULL,
+	user_id     TEXT NOT NULL,
+	role        TEXT NOT NULL,
+	enrolled_at TEXT NOT NULL,
+	PRIMARY KEY (cohort_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS cohort_members_user ON cohort_members (user_id);
+CREATE TABLE IF NOT EXISTS cohort_assignments (
+	cohort_id   TEXT NOT NULL,
+	module_id   TEXT NOT NULL,
+	due_at      TEXT,
+	assigned_at TEXT NOT NULL,
+	PRIMARY KEY (cohort_id, module_id)
+);
+`
+
+// SQLiteCohortStore keeps cohorts in a SQLite database
+type SQLiteCohortStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteCohortStore keeps cohorts in db, creating its tables if needed
+func NewSQLiteCohortStore(db *sql.DB) (*SQLiteCohortStore, error) {
+	if _, err := db.Exec(cohortSchema); err != nil {
+		return nil, fmt.Errorf("failed to create cohort tables: %w", err)
+	}
+	return &SQLiteCohortStore{db: db}, nil
+}
+
+// CreateCohort stores a new cohort without members or assignments
+func (s *SQLiteCohortStore) CreateCohort(cohort models.Cohort) error {
+	_, err := s.db.Exec(`INSERT INTO cohorts (id, name, description, created_at) VALUES (?, ?, ?, ?)`,
+		cohort.ID, cohort.Name, cohort.Description, formatSQLiteTime(cohort.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create cohort: %w", err)
+	}
+	return nil
+}
+
+// GetCohort returns a cohort with its members and assignments
+func (s *SQLiteCohortStore) GetCohort(id string) (*models.Cohort, error) {
+	row := s.db.QueryRow(`SELECT id, name, description, created_at FROM cohorts WHERE id = ?`, id)
+	cohort, err := scanCohort(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrCohortNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cohort.Members, err = s.members(id); err != nil {
+		return nil, err
+	}
+	if cohort.Assignments, err = s.assignments(id); err != nil {
+		return nil, err
+	}
+	return cohort, nil
+}
+
+// ListCohorts returns the cohorts userId is enrolled in, or every cohort
+func (s *SQLiteCohortStore) ListCohorts(userId string) ([]models.Cohort, error) {
+	query := `SELECT id, name, description, created_at FROM cohorts`
+	var args []interface{}
+	if userId != "" {
+		query += ` WHERE id IN (SELECT cohort_id FROM cohort_members WHERE user_id = ?)`
+		args = append(args, userId)
+	}
+	rows, err := s.db.Query(query+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cohorts: %w", err)
+	}
+
+	cohorts := []models.Cohort{}
+	for rows.Next() {
+		cohort, err := scanCohort(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		cohorts = append(cohorts, *cohort)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Assignments are loaded once the cohort rows are closed, since the
+	// database only has one connection
+	for i := range cohorts {
+		if cohorts[i].Assignments, err = s.assignments(cohorts[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return cohorts, nil
+}
+
+func (s *SQLiteCohortStore) members(cohortId string) ([]models.CohortMember, error) {
+	rows, err := s.db.Query(`SELECT user_id, role, enrolled_at FROM cohort_members WHERE cohort_id = ? ORDER BY enrolled_at, user_id`, cohortId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cohort members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.CohortMember{}
+	for rows.Next() {
+		var member models.CohortMember
+		var enrolledAt string
+		if err := rows.Scan(&member.UserID, &member.Role, &enrolledAt); err != nil {
+			return nil, fmt.Errorf("failed to read cohort member: %w", err)
+		}
+		if member.EnrolledAt, err = parseSQLiteTime(enrolledAt); err != nil {
+			return nil, fmt.Errorf("failed to read cohort member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (s *SQLiteCohortStore) assignments(cohortId string) ([]models.CohortAssignment, error) {
+	rows, err := s.db.Query(`SELECT module_id, due_at, assigned_at FROM cohort_assignments WHERE cohort_id = ? ORDER BY assigned_at, module_id`, cohortId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cohort assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []models.CohortAssignment{}
+	for rows.Next() {
+		var assignment models.CohortAssignment
+		var dueAt sql.NullString
+		var assignedAt string
+		if err := rows.Scan(&assignment.ModuleID, &dueAt, &assignedAt); err != nil {
+			return nil, fmt.Errorf("failed to read cohort assignment: %w", err)
+		}
+		if assignment.AssignedAt, err = parseSQLiteTime(assignedAt); err != nil {
+			return nil, fmt.Errorf("failed to read cohort assignment: %w", err)
+		}
+		if dueAt.Valid {
+			due, err := parseSQLiteTime(dueAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cohort assignment: %w", err)
+			}
+			assignment.DueAt = &due
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// DeleteCohort deletes a cohort with its members and assignments
+func (s *SQLiteCohortStore) DeleteCohort(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM cohorts WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete cohort: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %s", ErrCohortNotFound, id)
+	}
+	for _, table := range []string{"cohort_members", "cohort_assignments"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE cohort_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete cohort: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SetMember enrolls a user or changes their role, keeping when they enrolled
+func (s *SQLiteCohortStore) SetMember(cohortId string, member models.CohortMember) error {
+	_, err := s.db.Exec(`INSERT INTO cohort_members (cohort_id, user_id, role, enrolled_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (cohort_id, user_id) DO UPDATE SET role = excluded.role`,
+		cohortId, member.UserID, member.Role, formatSQLiteTime(member.EnrolledAt))
+	if err != nil {
+		return fmt.Errorf("failed to enroll cohort member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember unenrolls a user; removing someone who isn't enrolled does nothing
+func (s *SQLiteCohortStore) RemoveMember(cohortId, userId string) error {
+	if _, err := s.db.Exec(`DELETE FROM cohort_members WHERE cohort_id = ? AND user_id = ?`, cohortId, userId); err != nil {
+		return fmt.Errorf("failed to remove cohort member: %w", err)
+	}
+	return nil
+}
+
+// SetAssignment assigns a module or changes its due date, keeping when it
+// was first assigned
+func (s *SQLiteCohortStore) SetAssignment(cohortId string, assignment models.CohortAssignment) error {
+	var dueAt interface{}
+	if assignment.DueAt != nil {
+		dueAt = formatSQLiteTime(*assignment.DueAt)
+	}
+	_, err := s.db.Exec(`INSERT INTO cohort_assignments (cohort_id, module_id, due_at, assigned_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (cohort_id, module_id) DO UPDATE SET due_at = excluded.due_at`,
+		cohortId, assignment.ModuleID, dueAt, formatSQLiteTime(assignment.AssignedAt))
+	if err != nil {
+		return fmt.Errorf("failed to assign module: %w", err)
+	}
+	return nil
+}
+
+// RemoveAssignment unassigns a module; unassigning one that isn't assigned does nothing
+func (s *SQLiteCohortStore) RemoveAssignment(cohortId, moduleId string) error {
+	if _, err := s.db.Exec(`DELETE FROM cohort_assignments WHERE cohort_id = ? AND module_id = ?`, cohortId, moduleId); err != nil {
+		return fmt.Errorf("failed to unassign module: %w", err)
+	}
+	return nil
+}
+
+func scanCohort(row interface{ Scan(...interface{}) error }) (*models.Cohort, error) {
+	var cohort models.Cohort
+	var createdAt string
+	if err := row.Scan(&cohort.ID, &cohort.Name, &cohort.Description, &createdAt); err != nil {
+		return nil, fmt.Errorf("failed to read cohort: %w", err)
+	}
+	t, err := parseSQLiteTime(createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cohort: %w", err)
+	}
+	cohort.CreatedAt = t
+	return &cohort, nil
+}
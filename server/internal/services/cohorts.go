@@ -0,0 +1,316 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+var (
+	// ErrCohortNotFound is returned for unknown cohort IDs
+	ErrCohortNotFound = errors.New("cohort not found")
+	// ErrInvalidCohort is returned when cohort details or memberships are malformed
+	ErrInvalidCohort = errors.New("invalid cohort")
+)
+
+const (
+	maxCohortNameLength        = 100
+	maxCohortDescriptionLength = 1000
+)
+
+// CohortService groups students into classes taught by instructors, assigns
+// them modules with due dates and reports how each student is doing
+type CohortService struct {
+	moduleService     ModuleServiceInterface
+	store             CohortStore
+	accountService    AccountServiceInterface
+	progressService   ProgressServiceInterface
+	submissionService SubmissionServiceInterface
+	now               func() time.Time
+}
+
+// NewCohortService creates a cohort service backed by store. Members are
+// looked up in accountService and reports are built from progressService
+// and submissionService.
+func NewCohortService(moduleService ModuleServiceInterface, store CohortStore, accountService AccountServiceInterface,
+	progressService ProgressServiceInterface, submissionService SubmissionServiceInterface) *CohortService {
+	return &CohortService{
+		moduleService:     moduleService,
+		store:             store,
+		accountService:    accountService,
+		progressService:   progressService,
+		submissionService: submissionService,
+		now:               time.Now,
+	}
+}
+
+// CreateCohort creates a cohort with the user creating it as its instructor.
+// creatorId is empty when it's created with the admin token.
+func (s *CohortService) CreateCohort(name, description, creatorId string) (*models.Cohort, error) {
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	if name == "" || len(name) > maxCohortNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidCohort, maxCohortNameLength)
+	}
+	if len(description) > maxCohortDescriptionLength {
+		return nil, fmt.Errorf("%w: description must be at most %d characters", ErrInvalidCohort, maxCohortDescriptionLength)
+	}
+
+	id, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if err := s.store.CreateCohort(models.Cohort{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+	}); err != nil {
+		return nil, err
+	}
+
+	if creatorId != "" {
+		if err := s.store.SetMember(id, models.CohortMember{
+			UserID:     creatorId,
+			Role:       models.RoleInstructor,
+			EnrolledAt: now,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetCohort(id)
+}
+
+// ListCohorts returns the cohorts a user is enrolled in, or every cohort when
+// userId is empty, without their members
+func (s *CohortService) ListCohorts(userId string) ([]models.Cohort, error) {
+	cohorts, err := s.store.ListCohorts(userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cohorts {
+		s.describeAssignments(cohorts[i].Assignments)
+	}
+	return cohorts, nil
+}
+
+// GetCohort returns a cohort with its members and assignments
+func (s *CohortService) GetCohort(cohortId string) (*models.Cohort, error) {
+	cohort, err := s.store.GetCohort(cohortId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, member := range cohort.Members {
+		// Members whose account is gone stay listed by ID
+		if user, err := s.accountService.GetUser(member.UserID); err == nil {
+			cohort.Members[i].Email = user.Email
+			cohort.Members[i].Name = user.Name
+		}
+	}
+	s.describeAssignments(cohort.Assignments)
+	return cohort, nil
+}
+
+// describeAssignments fills in the titles of assigned modules; modules that
+// were deleted since keep an empty title
+func (s *CohortService) describeAssignments(assignments []models.CohortAssignment) {
+	for i, assignment := range assignments {
+		if module, err := s.moduleService.GetModuleById(assignment.ModuleID); err == nil {
+			assignments[i].Title = module.Title
+		}
+	}
+}
+
+// DeleteCohort deletes a cohort. Its students keep their progress.
+func (s *CohortService) DeleteCohort(cohortId string) error {
+	return s.store.DeleteCohort(cohortId)
+}
+
+// EnrollMember enrolls the user registered with email as a student or
+// instructor of a cohort, or changes the role they have in it
+func (s *CohortService) EnrollMember(cohortId, email, role string) (*models.Cohort, error) {
+	if role != models.RoleStudent && role != models.RoleInstructor {
+		return nil, fmt.Errorf("%w: role must be %s or %s", ErrInvalidCohort, models.RoleStudent, models.RoleInstructor)
+	}
+	if _, err := s.store.GetCohort(cohortId); err != nil {
+		return nil, err
+	}
+	user, err := s.accountService.FindUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SetMember(cohortId, models.CohortMember{
+		UserID:     user.ID,
+		Role:       role,
+		EnrolledAt: s.now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetCohort(cohortId)
+}
+
+// RemoveMember unenrolls a user from a cohort
+func (s *CohortService) RemoveMember(cohortId, userId string) (*models.Cohort, error) {
+	if _, err := s.store.GetCohort(cohortId); err != nil {
+		return nil, err
+	}
+	if err := s.store.RemoveMember(cohortId, userId); err != nil {
+		return nil, err
+	}
+	return s.GetCohort(cohortId)
+}
+
+// AssignModule assigns a published module to a cohort, due by dueAt when it
+// isn't nil. Assigning it again changes its due date.
+func (s *CohortService) AssignModule(cohortId, moduleId string, dueAt *time.Time) (*models.Cohort, error) {
+	if _, err := s.store.GetCohort(cohortId); err != nil {
+		return nil, err
+	}
+	if _, err := s.moduleService.GetModuleById(moduleId); err != nil {
+		return nil, err
+	}
+	if dueAt != nil {
+		due := dueAt.UTC()
+		dueAt = &due
+	}
+
+	if err := s.store.SetAssignment(cohortId, models.CohortAssignment{
+		ModuleID:   moduleId,
+		DueAt:      dueAt,
+		AssignedAt: s.now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetCohort(cohortId)
+}
+
+// UnassignModule removes a module from a cohort's assignments
+func (s *CohortService) UnassignModule(cohortId, moduleId string) (*models.Cohort, error) {
+	if _, err := s.store.GetCohort(cohortId); err != nil {
+		return nil, err
+	}
+	if err := s.store.RemoveAssignment(cohortId, moduleId); err != nil {
+		return nil, err
+	}
+	return s.GetCohort(cohortId)
+}
+
+// IsInstructor reports whether a user teaches a cohort
+func (s *CohortService) IsInstructor(cohortId, userId string) (bool, error) {
+	cohort, err := s.store.GetCohort(cohortId)
+	if err != nil {
+		return false, err
+	}
+	for _, member := range cohort.Members {
+		if member.UserID == userId {
+			return member.Role == models.RoleInstructor, nil
+		}
+	}
+	return false, nil
+}
+
+// GetReport returns every student's progress and latest test results on
+// each module assigned to a cohort
+func (s *CohortService) GetReport(cohortId string) (*models.CohortReport, error) {
+	cohort, err := s.GetCohort(cohortId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	report := &models.CohortReport{
+		CohortID:    cohort.ID,
+		Name:        cohort.Name,
+		GeneratedAt: now,
+		Assignments: make([]models.AssignmentSummary, len(cohort.Assignments)),
+		Students:    []models.StudentReport{},
+	}
+	for i, assignment := range cohort.Assignments {
+		report.Assignments[i].CohortAssignment = assignment
+	}
+
+	for _, member := range cohort.Members {
+		if member.Role != models.RoleStudent {
+			continue
+		}
+		student := models.StudentReport{
+			UserID:      member.UserID,
+			Email:       member.Email,
+			Name:        member.Name,
+			Assignments: make([]models.StudentAssignmentReport, 0, len(cohort.Assignments)),
+		}
+
+		for i, assignment := range cohort.Assignments {
+			entry, err := s.assignmentReport(member.UserID, assignment, now)
+			if err != nil {
+				return nil, err
+			}
+			student.Assignments = append(student.Assignments, *entry)
+
+			summary := &report.Assignments[i]
+			if entry.Status != models.ProgressNotStarted {
+				summary.Started++
+			}
+			if entry.Status == models.ProgressCompleted {
+				summary.Completed++
+				student.Completed++
+			}
+			if entry.Overdue {
+				summary.Overdue++
+			}
+		}
+		report.Students = append(report.Students, student)
+	}
+	return report, nil
+}
+
+// assignmentReport looks up a student's progress on an assigned module and
+// their latest test run of it
+func (s *CohortService) assignmentReport(userId string, assignment models.CohortAssignment, now time.Time) (*models.StudentAssignmentReport, error) {
+	entry := &models.StudentAssignmentReport{
+		ModuleID: assignment.ModuleID,
+		Status:   models.ProgressNotStarted,
+	}
+
+	progress, err := s.progressService.GetModuleProgress(userId, assignment.ModuleID)
+	switch {
+	case errors.Is(err, ErrModuleNotFound):
+		// The module was deleted after being assigned; nothing to report
+		return entry, nil
+	case err != nil:
+		return nil, err
+	}
+	entry.Progress = progress
+	entry.Status = progress.Status
+
+	if assignment.DueAt != nil && now.After(*assignment.DueAt) {
+		entry.Overdue = progress.Status != models.ProgressCompleted ||
+			(progress.CompletedAt != nil && progress.CompletedAt.After(*assignment.DueAt))
+	}
+
+	latest, err := s.submissionService.List(models.SubmissionFilter{
+		LearnerID: userId,
+		ModuleID:  assignment.ModuleID,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) == 0 {
+		return entry, nil
+	}
+	submission, err := s.submissionService.Get(latest[0].ID)
+	if err != nil {
+		return nil, err
+	}
+	entry.LatestSubmissionID = submission.ID
+	entry.LatestSubmittedAt = &submission.SubmittedAt
+	entry.LatestResult = submission.Result
+	return entry, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func TestCohortService(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	moduleService := NewModuleServiceWithPath(modulesDir)
+	db := openTestDB(t, ":memory:")
+	progressStore, _ := NewSQLiteProgressStore(db)
+	submissionStore, _ := NewSQLiteSubmissionStore(db)
+	cohortStore, err := NewSQLiteCohortStore(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	accounts := newTestAccountService(t, db)
+	progressService := NewProgressService(moduleService, progressStore)
+	submissionService := NewSubmissionService(moduleService, submissionStore)
+	service := NewCohortService(moduleService, cohortStore, accounts, progressService, submissionService)
+
+	signup := func(email string) models.User {
+		session, err := accounts.Signup(models.Credentials{Email: email, Password: "correct horse"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return session.User
+	}
+	teacher := signup("teacher@example.com")
+	ada := signup("ada@example.com")
+	bob := signup("bob@example.com")
+
+	if _, err := service.CreateCohort("  ", "", teacher.ID); !errors.Is(err, ErrInvalidCohort) {
+		t.Errorf("Expected ErrInvalidCohort for a blank name, got %v", err)
+	}
+	cohort, err := service.CreateCohort("Spring 2026", "Evening class", teacher.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cohort.Members) != 1 || cohort.Members[0].Role != models.RoleInstructor || cohort.Members[0].Email != teacher.Email {
+		t.Errorf("Expected the creator to teach the cohort, got %+v", cohort.Members)
+	}
+
+	service.EnrollMember(cohort.ID, "ada@example.com", models.RoleStudent)
+	if _, err := service.EnrollMember(cohort.ID, "BOB@example.com ", models.RoleStudent); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.EnrollMember(cohort.ID, "nobody@example.com", models.RoleStudent); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := service.EnrollMember(cohort.ID, "bob@example.com", models.RoleAdmin); !errors.Is(err, ErrInvalidCohort) {
+		t.Errorf("Expected ErrInvalidCohort for an admin membership, got %v", err)
+	}
+	if _, err := service.EnrollMember("missing", "bob@example.com", models.RoleStudent); !errors.Is(err, ErrCohortNotFound) {
+		t.Errorf("Expected ErrCohortNotFound, got %v", err)
+	}
+
+	if teaches, _ := service.IsInstructor(cohort.ID, teacher.ID); !teaches {
+		t.Error("Expected the creator to be an instructor")
+	}
+	if teaches, _ := service.IsInstructor(cohort.ID, ada.ID); teaches {
+		t.Error("Expected a student not to be an instructor")
+	}
+
+	due := time.Now().Add(time.Hour)
+	if _, err := service.AssignModule(cohort.ID, "missing", &due); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("Expected ErrModuleNotFound, got %v", err)
+	}
+	cohort, err = service.AssignModule(cohort.ID, "module-3", &due)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cohort.Assignments) != 1 || cohort.Assignments[0].Title == "" || !cohort.Assignments[0].DueAt.Equal(due) {
+		t.Errorf("Expected the assigned module with its due date, got %+v", cohort.Assignments)
+	}
+
+	mine, err := service.ListCohorts(ada.ID)
+	if err != nil || len(mine) != 1 || mine[0].ID != cohort.ID || len(mine[0].Assignments) != 1 || mine[0].Members != nil {
+		t.Errorf("Expected the student's cohort without members, got %+v, %v", mine, err)
+	}
+	if others, _ := service.ListCohorts("someone-else"); len(others) != 0 {
+		t.Errorf("Expected no cohorts for a user who isn't enrolled, got %+v", others)
+	}
+
+	// Ada passes the first exercise; Bob hasn't started
+	result := &models.TestSuiteResult{TotalTests: 2, PassedTests: 2}
+	progressService.RecordResult(ada.ID, "module-3", "hashing", result)
+	submission, _ := submissionService.Record(ada.ID, "module-3", "hashing", "// ada", result)
+
+	service.now = func() time.Time { return due.Add(time.Minute) }
+	report, err := service.GetReport(cohort.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Students) != 2 || report.Students[0].UserID != ada.ID || report.Students[1].UserID != bob.ID {
+		t.Fatalf("Expected both students and not the instructor, got %+v", report.Students)
+	}
+	adaReport := report.Students[0].Assignments[0]
+	if adaReport.Status != models.ProgressInProgress || adaReport.LatestSubmissionID != submission.ID ||
+		adaReport.LatestResult == nil || adaReport.LatestResult.PassedTests != 2 || !adaReport.Overdue {
+		t.Errorf("Unexpected report for Ada: %+v", adaReport)
+	}
+	bobReport := report.Students[1].Assignments[0]
+	if bobReport.Status != models.ProgressNotStarted || bobReport.LatestResult != nil || !bobReport.Overdue {
+		t.Errorf("Unexpected report for Bob: %+v", bobReport)
+	}
+	summary := report.Assignments[0]
+	if summary.Started != 1 || summary.Completed != 0 || summary.Overdue != 2 {
+		t.Errorf("Unexpected assignment summary: %+v", summary)
+	}
+
+	cohort, err = service.RemoveMember(cohort.ID, bob.ID)
+	if err != nil || len(cohort.Members) != 2 {
+		t.Errorf("Expected Bob to be removed, got %+v, %v", cohort.Members, err)
+	}
+	cohort, err = service.UnassignModule(cohort.ID, "module-3")
+	if err != nil || len(cohort.Assignments) != 0 {
+		t.Errorf("Expected no assignments, got %+v, %v", cohort.Assignments, err)
+	}
+
+	if err := service.DeleteCohort(cohort.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.GetCohort(cohort.ID); !errors.Is(err, ErrCohortNotFound) {
+		t.Errorf("Expected ErrCohortNotFound after deleting, got %v", err)
+	}
+	if err := service.DeleteCohort(cohort.ID); !errors.Is(err, ErrCohortNotFound) {
+		t.Errorf("Expected ErrCohortNotFound deleting twice, got %v", err)
+	}
+	if mine, _ := service.ListCohorts(ada.ID); len(mine) != 0 {
+		t.Errorf("Expected memberships to be deleted with the cohort, got %+v", mine)
+	}
+}
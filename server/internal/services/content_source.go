@@ -0,0 +1,191 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ContentSource is where module content is read from: a directory on disk or
+// a filesystem compiled into the binary. Modules are the top-level module-*
+// directories of the filesystem.
+type ContentSource struct {
+	modules          fs.FS
+	dir              string
+	runnerDockerfile []byte
+
+	// Embedded modules are extracted here when they have to be run from disk
+	extractMu  sync.Mutex
+	extractDir string
+	extracted  map[string]string
+}
+
+// NewDirContentSource creates a content source backed by a directory on disk
+func NewDirContentSource(dir string) *ContentSource {
+	return &ContentSource{
+		modules:   os.DirFS(dir),
+		dir:       dir,
+		extracted: make(map[string]string),
+	}
+}
+
+// NewFSContentSource creates a content source backed by a filesystem such as
+// an embed.FS (already rooted at the modules directory)
+func NewFSContentSource(fsys fs.FS) *ContentSource {
+	return &ContentSource{
+		modules:   fsys,
+		extracted: make(map[string]string),
+	}
+}
+
+// WithRunnerDockerfile sets the Dockerfile used to build module runner images
+func (c *ContentSource) WithRunnerDockerfile(dockerfile []byte) *ContentSource {
+	c.runnerDockerfile = dockerfile
+	return c
+}
+
+// FS returns the filesystem holding the module directories
+func (c *ContentSource) FS() fs.FS {
+	return c.modules
+}
+
+// Dir returns the modules directory on disk, if the source is disk-backed
+func (c *ContentSource) Dir() (string, bool) {
+	return c.dir, c.dir != ""
+}
+
+// String describes the source for log messages
+func (c *ContentSource) String() string {
+	if c.dir != "" {
+		return c.dir
+	}
+	return "embedded content"
+}
+
+// RunnerDockerfile returns the Dockerfile used to build module runner images.
+// Disk-backed sources without an explicit Dockerfile fall back to the copy
+// next to the modules directory (server/Dockerfile.module-runner).
+func (c *ContentSource) RunnerDockerfile() ([]byte, error) {
+	if c.runnerDockerfile != nil {
+		return c.runnerDockerfile, nil
+	}
+	if c.dir == "" {
+		return nil, fmt.Errorf("no module runner Dockerfile configured")
+	}
+	return os.ReadFile(filepath.Join(c.dir, "..", "..", "Dockerfile.module-runner"))
+}
+
+// ModuleDir returns a directory on disk holding the module's files so code can
+// be run against them. Embedded modules are extracted to a temporary
+// directory the first time they are needed.
+func (c *ContentSource) ModuleDir(moduleId string) (string, error) {
+	if c.dir != "" {
+		return filepath.Join(c.dir, moduleId), nil
+	}
+
+	c.extractMu.Lock()
+	defer c.extractMu.Unlock()
+
+	if dir, ok := c.extracted[moduleId]; ok {
+		return dir, nil
+	}
+
+	if _, err := fs.Stat(c.modules, moduleId); err != nil {
+		return "", fmt.Errorf("module %s not found", moduleId)
+	}
+
+	if c.extractDir == "" {
+		extractDir, err := os.MkdirTemp("", "backend2lab-modules-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create extraction directory: %w", err)
+		}
+		c.extractDir = extractDir
+	}
+
+	dir := filepath.Join(c.extractDir, moduleId)
+	if err := extractFS(c.modules, moduleId, dir); err != nil {
+		return "", fmt.Errorf("failed to extract module %s: %w", moduleId, err)
+	}
+
+	c.extracted[moduleId] = dir
+	return dir, nil
+}
+
+// extractFS copies the tree at root in fsys to dest on disk
+func extractFS(fsys fs.FS, root, dest string) error {
+	return fs.WalkDir(fsys, root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(filepath.FromSlash(root), filepath.FromSlash(p))
+		target := filepath.Join(dest, rel)
+
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+}
+
+// readModuleFile reads a file referenced from module.json, rejecting paths
+// that would leave the module directory
+func (c *ContentSource) readModuleFile(moduleId, rel string) ([]byte, error) {
+	cleaned, ok := moduleRelativePath(rel)
+	if !ok {
+		return nil, fmt.Errorf("path traversal detected: %s", rel)
+	}
+	return fs.ReadFile(c.modules, path.Join(moduleId, cleaned))
+}
+
+// walkModuleFiles calls fn for every file in a module, in lexical order, with
+// its path relative to the module directory. Transient files written while
+// running code and node_modules are skipped.
+func walkModuleFiles(fsys fs.FS, moduleId string, fn func(rel string, data []byte) error) error {
+	return fs.WalkDir(fsys, moduleId, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == "node_modules" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if isTransientModuleFile(filePath) {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+		return fn(strings.TrimPrefix(filePath, moduleId+"/"), data)
+	})
+}
+
+// ModuleVersion returns a hash of every file in a module, so any change to
+// its configuration, instructions or tests produces a new version
+func (c *ContentSource) ModuleVersion(moduleId string) (string, error) {
+	hash := sha256.New()
+	err := walkModuleFiles(c.modules, moduleId, func(rel string, data []byte) error {
+		fmt.Fprintf(hash, "%s\x00%d\x00", rel, len(data))
+		hash.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash module %s: %w", moduleId, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestContentSource_EmbeddedModules(t *testing.T) {
+	fsys := fstest.MapFS{
+		"module-1/module.json":        {Data: []byte(validModuleJSON("module-1", ""))},
+		"module-1/lab/README.md":      {Data: []byte("# Lab")},
+		"module-1/exercise/server.js": {Data: []byte("// Server")},
+		"module-1/exercise/test.js":   {Data: []byte("// Test")},
+	}
+	source := NewFSContentSource(fsys).WithRunnerDockerfile([]byte("FROM node:20"))
+
+	service := NewModuleServiceWithSource(source)
+	content, err := service.GetModuleContent("module-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if content.LabContent != "# Lab" || content.ExerciseContent.EditorFiles.Server != "// Server" {
+		t.Errorf("Unexpected module content %+v", content)
+	}
+
+	if _, err := service.Watch(); err == nil {
+		t.Error("Expected embedded content not to be watchable")
+	}
+
+	dockerfile, err := source.RunnerDockerfile()
+	if err != nil || string(dockerfile) != "FROM node:20" {
+		t.Errorf("Expected configured Dockerfile, got %q (%v)", dockerfile, err)
+	}
+}
+
+func TestContentSource_ModuleDirExtractsEmbeddedContent(t *testing.T) {
+	fsys := fstest.MapFS{
+		"module-1/exercise/test.js": {Data: []byte("// Test")},
+	}
+	source := NewFSContentSource(fsys)
+
+	dir, err := source.ModuleDir("module-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer os.RemoveAll(filepath.Dir(dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "exercise", "test.js"))
+	if err != nil || string(data) != "// Test" {
+		t.Errorf("Expected extracted test file, got %q (%v)", data, err)
+	}
+
+	if _, err := source.ModuleDir("module-2"); err == nil {
+		t.Error("Expected an error for a missing module")
+	}
+}
+
+func TestContentSource_RejectsPathTraversal(t *testing.T) {
+	source := NewFSContentSource(fstest.MapFS{
+		"module-1/module.json": {Data: []byte("{}")},
+	})
+
+	if _, err := source.readModuleFile("module-1", "../module-2/exercise/solution.js"); err == nil {
+		t.Error("Expected path traversal to be rejected")
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,28 +20,28 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/sirupsen/logrus"
 )
 
 type DockerRunner struct {
 	dockerClient *client.Client
-	modulesPath  string
+	source       *ContentSource
 	config       *config.DockerConfig
 }
 
-// NewDockerRunner creates a new DockerRunner instance
-func NewDockerRunner() (*DockerRunner, error) {
+// NewDockerRunner creates a new DockerRunner instance for modules from a content source
+func NewDockerRunner(source *ContentSource) (*DockerRunner, error) {
 	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create docker client: %w", err)
 	}
 
-	modulesPath := filepath.Join("src", "modules")
 	dockerConfig := config.LoadDockerConfig()
 	
 	return &DockerRunner{
 		dockerClient: dockerClient,
-		modulesPath:  modulesPath,
+		source:       source,
 		config:       dockerConfig,
 	}, nil
 }
@@ -114,6 +114,7 @@ func (d *DockerRunner) RunTests(moduleId, inputCode string) (*models.TestSuiteRe
 	if err := d.buildModuleImage(moduleId, imageName); err != nil {
 		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
 			TotalTests:    0,
 			PassedTests:   0,
 			FailedTests:   0,
@@ -128,6 +129,7 @@ func (d *DockerRunner) RunTests(moduleId, inputCode string) (*models.TestSuiteRe
 	if err != nil {
 		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
 			TotalTests:    0,
 			PassedTests:   0,
 			FailedTests:   0,
@@ -139,30 +141,38 @@ func (d *DockerRunner) RunTests(moduleId, inputCode string) (*models.TestSuiteRe
 	// Ensure cleanup
 	defer d.cleanupContainer(containerID)
 
-	// Start and run the container
-	output, err := d.runContainer(containerID, time.Duration(d.config.ExecutionTimeout*2)*time.Second)
+	// Start the container and record reporter events as they are logged
+	collector := newMochaStreamCollector()
+	suiteTimeout := time.Duration(d.config.ExecutionTimeout*2) * time.Second
+	timedOut, err := d.runTestContainer(containerID, suiteTimeout, collector)
+
+	exercise, _, _ := findExercise(readModuleManifest(d.source, moduleId), "")
+	if suite, ok := collector.SuiteResult(moduleId, exerciseType(moduleId, exercise), time.Since(startTime), timedOut); ok {
+		return suite, nil
+	}
 
 	if err != nil {
 		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
 			TotalTests:    0,
 			PassedTests:   0,
 			FailedTests:   0,
-			Results:       []models.TestResult{{TestName: "Execution", Passed: false, Error: &[]string{fmt.Sprintf("Container execution failed: %s", err.Error())}[0]}},
+			Results:       []models.TestResult{{TestName: "Execution", Passed: false, TimedOut: timedOut, Error: &[]string{fmt.Sprintf("Container execution failed: %s", err.Error())}[0]}},
 			ExecutionTime: time.Since(startTime).Milliseconds(),
 		}, nil
 	}
 
-	// Parse test results
-	return d.parseTestResults(moduleId, output, time.Since(startTime))
+	// The reporter produced nothing; fall back to parsing the raw output
+	return d.parseTestResults(moduleId, collector.Output(), time.Since(startTime))
 }
 
 // buildModuleImage builds a Docker image for the module
 func (d *DockerRunner) buildModuleImage(moduleId, imageName string) error {
-	modulePath := filepath.Join(d.modulesPath, moduleId, "exercise")
+	modulePath := path.Join(moduleId, "exercise")
 	
 	// Check if module exists
-	if _, err := os.Stat(modulePath); os.IsNotExist(err) {
+	if _, err := fs.Stat(d.source.FS(), modulePath); err != nil {
 		return fmt.Errorf("module %s not found", moduleId)
 	}
 
@@ -216,41 +226,44 @@ func (d *DockerRunner) createBuildContext(modulePath string) (io.Reader, error)
 	tw := tar.NewWriter(&buf)
 
 	// Add all files from module directory
-	err := filepath.Walk(modulePath, func(path string, info os.FileInfo, err error) error {
+	fsys := d.source.FS()
+	err := fs.WalkDir(fsys, modulePath, func(filePath string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
 		// Skip directories
-		if info.IsDir() {
+		if entry.IsDir() {
 			return nil
 		}
 
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
 		// Skip large files and temporary files that might cause issues
 		if info.Size() > 10*1024*1024 { // Skip files larger than 10MB
-			fmt.Printf("Skipping large file: %s (size: %d bytes)\n", path, info.Size())
+			fmt.Printf("Skipping large file: %s (size: %d bytes)\n", filePath, info.Size())
 			return nil
 		}
 
 		// Skip common temporary and cache files
-		fileName := filepath.Base(path)
-		if fileName == "tmp-server.js" || fileName == ".DS_Store" || 
-		   filepath.Ext(fileName) == ".log" || filepath.Ext(fileName) == ".tmp" {
-			fmt.Printf("Skipping temporary file: %s\n", path)
+		fileName := path.Base(filePath)
+		if fileName == "tmp-server.js" || fileName == mochaReporterFile || fileName == ".DS_Store" || 
+		   path.Ext(fileName) == ".log" || path.Ext(fileName) == ".tmp" {
+			fmt.Printf("Skipping temporary file: %s\n", filePath)
 			return nil
 		}
 
 		// Read file content
-		content, err := os.ReadFile(path)
+		content, err := fs.ReadFile(fsys, filePath)
 		if err != nil {
 			return err
 		}
 
 		// Calculate relative path
-		relPath, err := filepath.Rel(modulePath, path)
-		if err != nil {
-			return err
-		}
+		relPath := strings.TrimPrefix(filePath, modulePath+"/")
 
 		// Add file to tar
 		header := &tar.Header{
@@ -275,8 +288,7 @@ func (d *DockerRunner) createBuildContext(modulePath string) (io.Reader, error)
 	}
 
 	// Add Dockerfile
-	dockerfilePath := filepath.Join(d.modulesPath, "../..", "Dockerfile.module-runner")
-	dockerfileContent, err := os.ReadFile(dockerfilePath)
+	dockerfileContent, err := d.source.RunnerDockerfile()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Dockerfile: %w", err)
 	}
@@ -355,7 +367,7 @@ func (d *DockerRunner) createTestContainer(containerName, imageName, inputCode,
 	// Create container config for testing
 	containerConfig := &container.Config{
 		Image: imageName,
-		Cmd:   []string{"sh", "-c", "echo 'Starting server...'; node tmp-server.js & SERVER_PID=$!; echo 'Server PID:' $SERVER_PID; sleep 3; echo 'Running tests...'; npm run test -- --reporter json; echo 'Stopping server...'; kill $SERVER_PID 2>/dev/null || true; echo 'Done'"},
+		Cmd:   []string{"sh", "-c", fmt.Sprintf("echo 'Starting server...'; node tmp-server.js & SERVER_PID=$!; echo 'Server PID:' $SERVER_PID; sleep 3; echo 'Running tests...'; npm run test -- --reporter ./%s --timeout %s; echo 'Stopping server...'; kill $SERVER_PID 2>/dev/null || true; echo 'Done'", mochaReporterFile, mochaTimeoutArg(d.perTestTimeout()))},
 		Env: []string{
 			"PORT=3000",
 		},
@@ -416,6 +428,21 @@ func (d *DockerRunner) copyCodeToContainer(containerID, inputCode string) error
 		return err
 	}
 
+	// Add the stream reporter used by the test command
+	reporterHeader := &tar.Header{
+		Name: mochaReporterFile,
+		Size: int64(len(mochaStreamReporter)),
+		Mode: 0644,
+	}
+
+	if err := tw.WriteHeader(reporterHeader); err != nil {
+		return err
+	}
+
+	if _, err := tw.Write(mochaStreamReporter); err != nil {
+		return err
+	}
+
 	if err := tw.Close(); err != nil {
 		return err
 	}
@@ -485,6 +512,73 @@ func (d *DockerRunner) runContainer(containerID string, timeout time.Duration) (
 	return strings.TrimSpace(output.String()), nil
 }
 
+// runTestContainer starts the container and streams its logs into the
+// collector until the container exits or the timeout expires. It reports
+// whether the timeout was hit so partial results can be labelled as such.
+func (d *DockerRunner) runTestContainer(containerID string, timeout time.Duration, collector *mochaStreamCollector) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Start container
+	if err := d.dockerClient.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+		return false, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	// Follow logs so events are recorded while the tests are still running
+	logs, err := d.dockerClient.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get container logs: %w", err)
+	}
+	defer logs.Close()
+
+	streamDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(collector, collector, logs)
+		streamDone <- err
+	}()
+
+	// Wait for container to finish
+	statusCh, errCh := d.dockerClient.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return true, fmt.Errorf("container wait timed out: %w", ctx.Err())
+			}
+			return false, fmt.Errorf("container wait error: %w", err)
+		}
+	case status := <-statusCh:
+		logrus.Debugf("Container %s finished with status: %+v", containerID, status)
+	case <-ctx.Done():
+		return true, fmt.Errorf("container wait timed out: %w", ctx.Err())
+	}
+
+	// Drain the remaining log output
+	select {
+	case err := <-streamDone:
+		if err != nil && err != io.EOF {
+			return false, fmt.Errorf("failed to read container logs: %w", err)
+		}
+	case <-ctx.Done():
+		return true, fmt.Errorf("reading container logs timed out: %w", ctx.Err())
+	}
+
+	return false, nil
+}
+
+// perTestTimeout returns the configured per-test timeout
+func (d *DockerRunner) perTestTimeout() time.Duration {
+	if d.config.TestTimeout <= 0 {
+		return defaultPerTestTimeout
+	}
+	return time.Duration(d.config.TestTimeout) * time.Millisecond
+}
+
 // parseTestResults parses Mocha JSON output into TestSuiteResult
 func (d *DockerRunner) parseTestResults(moduleId, output string, executionTime time.Duration) (*models.TestSuiteResult, error) {	
 	// Handle empty output
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// DraftStore persists users' drafts
+type DraftStore interface {
+	// GetDraft returns a saved draft, or nil when there is none
+	GetDraft(userId, moduleId, exerciseId, file string) (*models.Draft, error)
+	// ListDrafts returns a user's saved drafts of a module's files
+	ListDrafts(userId, moduleId string) ([]models.Draft, error)
+	// SaveDraft stores draft, which must have UpdatedAt set, as the revision
+	// after baseRevision. It fails with ErrDraftConflict when the saved draft
+	// is at another revision; a negative baseRevision overwrites it anyway.
+	SaveDraft(draft models.Draft, baseRevision int) (*models.Draft, error)
+}
+
+const draftSchema = `
+CREATE TABLE IF NOT EXISTS drafts (
+	user_id     TEXT    NOT NULL,
+	module_id   TEXT    NOT NULL,
+	exercise_id TEXT    NOT NULL,
+	file        TEXT    NOT NULL,
+	content     TEXT    NOT NULL,
+	revision    INTEGER NOT NULL,
+	updated_at  TEXT    NOT NULL,
+	PRIMARY KEY (user_id, module_id, exercise_id, file)
+);
+`
+
+// saveDraftSQL writes revision 1 of a new draft or the next revision of an
+// existing one. The WHERE clause skips the update unless the saved revision
+// is the one the caller based their changes on; saved drafts are never at
+// revision 0, so a first save doesn't overwrite one made elsewhere.
+const saveDraftSQL = `
+INSERT INTO drafts (user_id, module_id, exercise_id, file, content, revision, updated_at)
+VALUES (?, ?, ?, ?, ?, 1, ?)
+ON CONFLICT (user_id, module_id, exercise_id, file) DO UPDATE SET
+	content    = excluded.content,
+	revision   = revision + 1,
+	updated_at = excluded.updated_at
+WHERE ? < 0 OR revision = ?
+`
+
+const draftColumns = `user_id, module_id, exercise_id, file, content, revision, updated_at`
+
+// SQLiteDraftStore keeps drafts in a SQLite database
+type SQLiteDraftStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteDraftStore keeps drafts in db, creating its table if needed
+func NewSQLiteDraftStore(db *sql.DB) (*SQLiteDraftStore, error) {
+	if _, err := db.Exec(draftSchema); err != nil {
+		return nil, fmt.Errorf("failed to create draft tables: %w", err)
+	}
+	return &SQLiteDraftStore{db: db}, nil
+}
+
+// GetDraft returns a saved draft, or nil when there is none
+func (s *SQLiteDraftStore) GetDraft(userId, moduleId, exerciseId, file string) (*models.Draft, error) {
+	return s.getDraft(s.db, userId, moduleId, exerciseId, file)
+}
+
+func (s *SQLiteDraftStore) getDraft(q interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}, userId, moduleId, exerciseId, file string) (*models.Draft, error) {
+	row := q.QueryRow(`SELECT `+draftColumns+` FROM drafts WHERE user_id = ? AND module_id = ? AND exercise_id = ? AND file = ?`,
+		userId, moduleId, exerciseId, file)
+	draft, err := scanDraft(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return draft, err
+}
+
+// ListDrafts returns a user's saved drafts of a module's files
+func (s *SQLiteDraftStore) ListDrafts(userId, moduleId string) ([]models.Draft, error) {
+	rows, err := s.db.Query(`SELECT `+draftColumns+` FROM drafts WHERE user_id = ? AND module_id = ? ORDER BY exercise_id, file`,
+		userId, moduleId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load drafts: %w", err)
+	}
+	defer rows.Close()
+
+	drafts := []models.Draft{}
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, *draft)
+	}
+	return drafts, rows.Err()
+}
+
+// SaveDraft stores draft as the revision after baseRevision
+func (s *SQLiteDraftStore) SaveDraft(draft models.Draft, baseRevision int) (*models.Draft, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(saveDraftSQL,
+		draft.UserID, draft.ModuleID, draft.ExerciseID, draft.File, draft.Content, formatSQLiteTime(*draft.UpdatedAt),
+		baseRevision, baseRevision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save draft: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, fmt.Errorf("%w: revision %d is no longer the latest", ErrDraftConflict, baseRevision)
+	}
+
+	saved, err := s.getDraft(tx, draft.UserID, draft.ModuleID, draft.ExerciseID, draft.File)
+	if err != nil {
+		return nil, err
+	}
+	return saved, tx.Commit()
+}
+
+// scanDraft reads a row selected with draftColumns
+func scanDraft(row interface{ Scan(...interface{}) error }) (*models.Draft, error) {
+	var draft models.Draft
+	var updatedAt string
+	if err := row.Scan(&draft.UserID, &draft.ModuleID, &draft.ExerciseID, &draft.File, &draft.Content, &draft.Revision, &updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+
+	t, err := parseSQLiteTime(updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+	draft.UpdatedAt = &t
+	return &draft, nil
+}
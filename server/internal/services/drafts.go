@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+var (
+	// ErrDraftConflict is returned when saving over a revision that is no longer the latest
+	ErrDraftConflict = errors.New("draft was saved elsewhere")
+	// ErrInvalidDraftFile is returned for names that aren't editor files
+	ErrInvalidDraftFile = errors.New("not an editor file")
+	// ErrDraftTooLarge is returned for drafts over MaxDraftSize
+	ErrDraftTooLarge = errors.New("draft too large")
+)
+
+// MaxDraftSize is the largest draft that can be saved, in bytes
+const MaxDraftSize = 256 * 1024
+
+// DraftService autosaves users' editor files so their work follows them
+// between devices
+type DraftService struct {
+	moduleService ModuleServiceInterface
+	store         DraftStore
+	now           func() time.Time
+}
+
+// NewDraftService creates a draft service backed by store
+func NewDraftService(moduleService ModuleServiceInterface, store DraftStore) *DraftService {
+	return &DraftService{
+		moduleService: moduleService,
+		store:         store,
+		now:           time.Now,
+	}
+}
+
+// starter returns the ID of a module's exercise and the starter content of
+// one of its editor files. An empty exercise ID selects the first exercise.
+func (s *DraftService) starter(moduleId, exerciseId, file string) (string, string, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return "", "", err
+	}
+	exercise, _, err := findExercise(*module, exerciseId)
+	if err != nil {
+		return "", "", err
+	}
+	step, err := s.moduleService.GetModuleExercise(moduleId, exercise.ID)
+	if err != nil {
+		return "", "", err
+	}
+	content, ok := step.ExerciseContent.EditorFiles.File(file)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidDraftFile, file)
+	}
+	return exercise.ID, content, nil
+}
+
+// GetDraft returns a user's saved draft of an editor file, or the starter
+// file at revision 0 when they haven't saved one
+func (s *DraftService) GetDraft(userId, moduleId, exerciseId, file string) (*models.Draft, error) {
+	exerciseId, starter, err := s.starter(moduleId, exerciseId, file)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := s.store.GetDraft(userId, moduleId, exerciseId, file)
+	if err != nil || draft != nil {
+		return draft, err
+	}
+	return &models.Draft{
+		UserID:     userId,
+		ModuleID:   moduleId,
+		ExerciseID: exerciseId,
+		File:       file,
+		Content:    starter,
+	}, nil
+}
+
+// ListDrafts returns a user's saved drafts of a module's files
+func (s *DraftService) ListDrafts(userId, moduleId string) ([]models.Draft, error) {
+	if _, err := s.moduleService.GetModuleById(moduleId); err != nil {
+		return nil, err
+	}
+	return s.store.ListDrafts(userId, moduleId)
+}
+
+// SaveDraft saves content as the revision after the one the user last
+// loaded. When someone saved in the meantime it fails with ErrDraftConflict
+// and returns the latest draft.
+func (s *DraftService) SaveDraft(userId, moduleId, exerciseId, file, content string, revision int) (*models.Draft, error) {
+	if len(content) > MaxDraftSize {
+		return nil, fmt.Errorf("%w: limit is %d bytes", ErrDraftTooLarge, MaxDraftSize)
+	}
+	if revision < 0 {
+		return nil, fmt.Errorf("invalid draft revision %d", revision)
+	}
+	return s.save(userId, moduleId, exerciseId, file, content, revision)
+}
+
+// ResetDraft replaces a user's draft with the module's current starter file
+func (s *DraftService) ResetDraft(userId, moduleId, exerciseId, file string) (*models.Draft, error) {
+	return s.save(userId, moduleId, exerciseId, file, "", -1)
+}
+
+// save stores a draft based on revision; a negative revision resets the
+// draft to the starter file
+func (s *DraftService) save(userId, moduleId, exerciseId, file, content string, revision int) (*models.Draft, error) {
+	exerciseId, starter, err := s.starter(moduleId, exerciseId, file)
+	if err != nil {
+		return nil, err
+	}
+	if revision < 0 {
+		content = starter
+	}
+
+	now := s.now().UTC()
+	draft, err := s.store.SaveDraft(models.Draft{
+		UserID:     userId,
+		ModuleID:   moduleId,
+		ExerciseID: exerciseId,
+		File:       file,
+		Content:    content,
+		UpdatedAt:  &now,
+	}, revision)
+	if errors.Is(err, ErrDraftConflict) {
+		latest, latestErr := s.store.GetDraft(userId, moduleId, exerciseId, file)
+		if latestErr != nil {
+			return nil, latestErr
+		}
+		return latest, err
+	}
+	return draft, err
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDraftService(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	moduleService := NewModuleServiceWithPath(modulesDir)
+	store, err := NewSQLiteDraftStore(openTestDB(t, ":memory:"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := NewDraftService(moduleService, store)
+
+	draft, err := service.GetDraft("user-1", "module-3", "", "server")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if draft.ExerciseID != "hashing" || draft.Content != "// hashing starter" || draft.Revision != 0 || draft.UpdatedAt != nil {
+		t.Errorf("Expected the starter file before saving, got %+v", draft)
+	}
+
+	draft, err = service.SaveDraft("user-1", "module-3", "hashing", "server", "// first", 0)
+	if err != nil || draft.Revision != 1 || draft.Content != "// first" {
+		t.Fatalf("Expected revision 1, got %+v, %v", draft, err)
+	}
+	draft, err = service.SaveDraft("user-1", "module-3", "hashing", "server", "// second", 1)
+	if err != nil || draft.Revision != 2 {
+		t.Fatalf("Expected revision 2, got %+v, %v", draft, err)
+	}
+
+	// Saves based on an older revision, including first saves, conflict
+	for _, revision := range []int{0, 1} {
+		latest, err := service.SaveDraft("user-1", "module-3", "hashing", "server", "// stale", revision)
+		if !errors.Is(err, ErrDraftConflict) {
+			t.Errorf("Expected ErrDraftConflict for revision %d, got %v", revision, err)
+		}
+		if latest == nil || latest.Revision != 2 || latest.Content != "// second" {
+			t.Errorf("Expected the latest draft with the conflict, got %+v", latest)
+		}
+	}
+
+	if draft, _ := service.GetDraft("user-2", "module-3", "hashing", "server"); draft.Revision != 0 {
+		t.Errorf("Expected another user to get the starter file, got %+v", draft)
+	}
+	if _, err := service.GetDraft("user-1", "module-3", "hashing", "solution"); !errors.Is(err, ErrInvalidDraftFile) {
+		t.Errorf("Expected ErrInvalidDraftFile, got %v", err)
+	}
+	if _, err := service.SaveDraft("user-1", "module-3", "hashing", "server", strings.Repeat("x", MaxDraftSize+1), 2); !errors.Is(err, ErrDraftTooLarge) {
+		t.Errorf("Expected ErrDraftTooLarge, got %v", err)
+	}
+	if _, err := service.GetDraft("user-1", "module-3", "missing", "server"); !errors.Is(err, ErrExerciseNotFound) {
+		t.Errorf("Expected ErrExerciseNotFound, got %v", err)
+	}
+
+	// Resetting re-reads the starter file from the module
+	os.WriteFile(filepath.Join(modulesDir, "module-3", "exercises", "hashing", "server.js"), []byte("// new starter"), 0644)
+	if _, err := moduleService.Reload(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	draft, err = service.ResetDraft("user-1", "module-3", "hashing", "server")
+	if err != nil || draft.Revision != 3 || draft.Content != "// new starter" {
+		t.Errorf("Expected the reset draft at revision 3, got %+v, %v", draft, err)
+	}
+
+	service.SaveDraft("user-1", "module-3", "sessions", "server", "// sessions", 0)
+	drafts, err := service.ListDrafts("user-1", "module-3")
+	if err != nil || len(drafts) != 2 || drafts[0].ExerciseID != "hashing" || drafts[1].ExerciseID != "sessions" {
+		t.Errorf("Expected both saved drafts, got %+v, %v", drafts, err)
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// WriteGradebookCSV writes a cohort report as a spreadsheet with one row per
+// student and a status and score column per assigned module. Scores are the
+// student's best score on the module as a percentage.
+func WriteGradebookCSV(w io.Writer, report *models.CohortReport) error {
+	header := []string{"User ID", "Email", "Name", "Completed"}
+	for _, assignment := range report.Assignments {
+		header = append(header, assignment.ModuleID+" status", assignment.ModuleID+" score")
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, student := range report.Students {
+		row := []string{
+			student.UserID,
+			spreadsheetText(student.Email),
+			spreadsheetText(student.Name),
+			strconv.Itoa(student.Completed),
+		}
+		for _, assignment := range student.Assignments {
+			status := assignment.Status
+			if assignment.Overdue {
+				status += " (overdue)"
+			}
+			score := ""
+			if assignment.Progress != nil {
+				score = strconv.Itoa(assignment.Progress.BestScore)
+			}
+			row = append(row, status, score)
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// spreadsheetText keeps user-entered text from being read as a formula when
+// the CSV is opened in a spreadsheet
+func spreadsheetText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// junitTestSuites is the root of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	ID         string          `xml:"id,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+// junitProblem is a failure or error with a one-line message and the full
+// details as its text
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Details string `xml:",chardata"`
+}
+
+// WriteJUnitXML writes a submission's test results as a JUnit XML report.
+// Timed out tests and suites that failed to run are reported as errors, and
+// tests that never ran only count as skipped since their names are unknown.
+func WriteJUnitXML(w io.Writer, submission *models.Submission) error {
+	result := submission.Result
+	if result == nil {
+		result = &models.TestSuiteResult{}
+	}
+	name := submission.ModuleID + "/" + submission.ExerciseID
+	className := submission.ModuleID + "." + submission.ExerciseID
+
+	suite := junitTestSuite{
+		Name:      name,
+		ID:        submission.ID,
+		Tests:     result.TotalTests,
+		Skipped:   result.NotRunTests,
+		Time:      junitSeconds(result.ExecutionTime),
+		Timestamp: submission.SubmittedAt.UTC().Format(time.RFC3339),
+		Properties: []junitProperty{
+			{Name: "submissionId", Value: submission.ID},
+			{Name: "learnerId", Value: submission.LearnerID},
+			{Name: "moduleVersion", Value: submission.ModuleVersion},
+			{Name: "status", Value: submission.Status},
+		},
+		Cases: make([]junitTestCase, 0, len(result.Results)),
+	}
+
+	for _, test := range result.Results {
+		testCase := junitTestCase{
+			Name:      test.TestName,
+			ClassName: className,
+			Time:      junitSeconds(int64(test.Duration)),
+		}
+		if !test.Passed {
+			problem := junitFailure(test)
+			if test.TimedOut || result.Status == models.SuiteStatusError {
+				testCase.Error = problem
+				suite.Errors++
+			} else {
+				testCase.Failure = problem
+				suite.Failures++
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	report := junitTestSuites{
+		Name:     name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitFailure describes why a test failed: the first line of its error as
+// the message, and the whole error with the expected and actual values as
+// the details
+func junitFailure(test models.TestResult) *junitProblem {
+	problem := &junitProblem{Message: "Test failed"}
+	if test.TimedOut {
+		problem.Type = "timeout"
+	}
+
+	var details []string
+	if test.Error != nil && *test.Error != "" {
+		problem.Message, _, _ = strings.Cut(*test.Error, "\n")
+		details = append(details, *test.Error)
+	}
+	if test.Expected != nil || test.Actual != nil {
+		details = append(details, "expected: "+junitValue(test.Expected), "actual: "+junitValue(test.Actual))
+	}
+	problem.Details = strings.Join(details, "\n")
+	return problem
+}
+
+// junitValue formats an expected or actual value the way it was reported
+func junitValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// junitSeconds formats milliseconds as the seconds JUnit reports use
+func junitSeconds(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', 3, 64)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func TestWriteGradebookCSV(t *testing.T) {
+	report := &models.CohortReport{
+		Assignments: []models.AssignmentSummary{
+			{CohortAssignment: models.CohortAssignment{ModuleID: "module-1"}},
+			{CohortAssignment: models.CohortAssignment{ModuleID: "module-2"}},
+		},
+		Students: []models.StudentReport{
+			{
+				UserID: "u1", Email: "ada@example.com", Name: "Ada, Countess", Completed: 1,
+				Assignments: []models.StudentAssignmentReport{
+					{ModuleID: "module-1", Status: models.ProgressCompleted, Progress: &models.ModuleProgress{BestScore: 100}},
+					{ModuleID: "module-2", Status: models.ProgressInProgress, Overdue: true, Progress: &models.ModuleProgress{BestScore: 40}},
+				},
+			},
+			{
+				UserID: "u2", Email: "bob@example.com", Name: "=HYPERLINK(\"x\")",
+				Assignments: []models.StudentAssignmentReport{
+					{ModuleID: "module-1", Status: models.ProgressNotStarted, Progress: &models.ModuleProgress{}},
+					{ModuleID: "module-2", Status: models.ProgressNotStarted},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := WriteGradebookCSV(&out, report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "User ID,Email,Name,Completed,module-1 status,module-1 score,module-2 status,module-2 score\n" +
+		"u1,ada@example.com,\"Ada, Countess\",1,completed,100,in_progress (overdue),40\n" +
+		"u2,bob@example.com,\"'=HYPERLINK(\"\"x\"\")\",0,not_started,0,not_started,\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected gradebook:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestWriteJUnitXML(t *testing.T) {
+	failure := "AssertionError: expected 200 to equal 201\n    at Context.<anonymous>"
+	timeout := "Timeout of 2000ms exceeded"
+	submission := &models.Submission{
+		SubmissionSummary: models.SubmissionSummary{
+			ID: "abc123", LearnerID: "u1", ModuleID: "module-1", ExerciseID: "hashing",
+			ModuleVersion: "v1", Status: models.SuiteStatusCompleted,
+			SubmittedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		Result: &models.TestSuiteResult{
+			TotalTests: 4, PassedTests: 1, FailedTests: 2, TimedOutTests: 1, NotRunTests: 1,
+			ExecutionTime: 1500,
+			Results: []models.TestResult{
+				{TestName: "hashes", Passed: true, Duration: 12},
+				{TestName: "creates users", Passed: false, Error: &failure, Expected: 201, Actual: 200},
+				{TestName: "<slow> & steady", Passed: false, TimedOut: true, Error: &timeout},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := WriteJUnitXML(&out, submission); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "<?xml") {
+		t.Errorf("Expected an XML declaration, got %q", out.String())
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid XML, got %v\n%s", err, out.String())
+	}
+	if report.Tests != 4 || report.Failures != 1 || report.Errors != 1 || report.Skipped != 1 || report.Time != "1.500" {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if len(report.Suites) != 1 || len(report.Suites[0].Cases) != 3 {
+		t.Fatalf("Expected one suite with three test cases, got %+v", report.Suites)
+	}
+	suite := report.Suites[0]
+	if suite.Name != "module-1/hashing" || suite.ID != "abc123" || suite.Timestamp != "2026-03-01T12:00:00Z" {
+		t.Errorf("Unexpected suite: %+v", suite)
+	}
+
+	passed, failed, timedOut := suite.Cases[0], suite.Cases[1], suite.Cases[2]
+	if passed.Failure != nil || passed.Error != nil || passed.Time != "0.012" || passed.ClassName != "module-1.hashing" {
+		t.Errorf("Unexpected passing test case: %+v", passed)
+	}
+	if failed.Failure == nil || failed.Failure.Message != "AssertionError: expected 200 to equal 201" ||
+		!strings.Contains(failed.Failure.Details, "expected: 201\nactual: 200") {
+		t.Errorf("Unexpected failing test case: %+v", failed.Failure)
+	}
+	if timedOut.Name != "<slow> & steady" || timedOut.Error == nil || timedOut.Error.Type != "timeout" {
+		t.Errorf("Expected the timed out test as an error, got %+v", timedOut)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// HintStore persists which hints learners revealed and when, keyed by the
+// hint's position in its exercise
+type HintStore interface {
+	// RevealedHints returns the positions a learner has revealed on an
+	// exercise and when
+	RevealedHints(learnerId, moduleId, exerciseId string) (map[int]time.Time, error)
+	// RevealHint records that a learner revealed the hint at position and
+	// returns when it was first revealed, which is at unless it already was
+	RevealHint(learnerId, moduleId, exerciseId string, position int, at time.Time) (time.Time, error)
+	// ModuleReveals returns every learner's revealed positions for a module,
+	// by exercise
+	ModuleReveals(moduleId string) (map[string][]map[int]time.Time, error)
+}
+
+const hintSchema = `
+CREATE TABLE IF NOT EXISTS hint_reveals (
+	learner_id  TEXT    NOT NULL,
+	module_id   TEXT    NOT NULL,
+	exercise_id TEXT    NOT NULL,
+	position    INTEGER NOT NULL,
+	revealed_at TEXT    NOT NULL,
+	PRIMARY KEY (learner_id, module_id, exercise_id, position)
+);
+CREATE INDEX IF NOT EXISTS hint_reveals_module ON hint_reveals (module_id);
+`
+
+// SQLiteHintStore keeps hint reveals in a SQLite database
+type SQLiteHintStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteHintStore keeps hint reveals in db, creating its table if needed
+func NewSQLiteHintStore(db *sql.DB) (*SQLiteHintStore, error) {
+	if _, err := db.Exec(hintSchema); err != nil {
+		return nil, fmt.Errorf("failed to create hint tables: %w", err)
+	}
+	return &SQLiteHintStore{db: db}, nil
+}
+
+// RevealedHints returns the positions a learner has revealed on an exercise
+func (s *SQLiteHintStore) RevealedHints(learnerId, moduleId, exerciseId string) (map[int]time.Time, error) {
+	rows, err := s.db.Query(`SELECT position, revealed_at FROM hint_reveals WHERE learner_id = ? AND module_id = ? AND exercise_id = ?`,
+		learnerId, moduleId, exerciseId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hint reveals: %w", err)
+	}
+	defer rows.Close()
+
+	revealed := make(map[int]time.Time)
+	for rows.Next() {
+		position, at, err := scanHintReveal(rows)
+		if err != nil {
+			return nil, err
+		}
+		revealed[position] = at
+	}
+	return revealed, rows.Err()
+}
+
+// RevealHint records that a learner revealed a hint, keeping the first time
+func (s *SQLiteHintStore) RevealHint(learnerId, moduleId, exerciseId string, position int, at time.Time) (time.Time, error) {
+	_, err := s.db.Exec(`INSERT INTO hint_reveals (learner_id, module_id, exercise_id, position, revealed_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (learner_id, module_id, exercise_id, position) DO NOTHING`,
+		learnerId, moduleId, exerciseId, position, formatSQLiteTime(at))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to save hint reveal: %w", err)
+	}
+
+	var revealedAt string
+	err = s.db.QueryRow(`SELECT revealed_at FROM hint_reveals WHERE learner_id = ? AND module_id = ? AND exercise_id = ? AND position = ?`,
+		learnerId, moduleId, exerciseId, position).Scan(&revealedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load hint reveal: %w", err)
+	}
+	return parseSQLiteTime(revealedAt)
+}
+
+// ModuleReveals returns every learner's revealed positions for a module
+func (s *SQLiteHintStore) ModuleReveals(moduleId string) (map[string][]map[int]time.Time, error) {
+	rows, err := s.db.Query(`SELECT exercise_id, learner_id, position, revealed_at FROM hint_reveals WHERE module_id = ? ORDER BY exercise_id, learner_id`, moduleId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hint reveals: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[string][]map[int]time.Time)
+	var lastExercise, lastLearner string
+	for rows.Next() {
+		var exerciseId, learnerId, revealedAt string
+		var position int
+		if err := rows.Scan(&exerciseId, &learnerId, &position, &revealedAt); err != nil {
+			return nil, fmt.Errorf("failed to read hint reveal: %w", err)
+		}
+		at, err := parseSQLiteTime(revealedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hint reveal: %w", err)
+		}
+		// Rows are grouped by exercise and learner, so a new pair starts a new learner
+		if len(usage[exerciseId]) == 0 || exerciseId != lastExercise || learnerId != lastLearner {
+			usage[exerciseId] = append(usage[exerciseId], make(map[int]time.Time))
+			lastExercise, lastLearner = exerciseId, learnerId
+		}
+		usage[exerciseId][len(usage[exerciseId])-1][position] = at
+	}
+	return usage, rows.Err()
+}
+
+// scanHintReveal reads a row of position and revealed_at
+func scanHintReveal(row interface{ Scan(...interface{}) error }) (int, time.Time, error) {
+	var position int
+	var revealedAt string
+	if err := row.Scan(&position, &revealedAt); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to read hint reveal: %w", err)
+	}
+	at, err := parseSQLiteTime(revealedAt)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to read hint reveal: %w", err)
+	}
+	return position, at, nil
+}
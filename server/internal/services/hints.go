@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ErrLearnerRequired is returned when hint progress is requested without a learner ID
+var ErrLearnerRequired = errors.New("learner ID required")
+
+// HintService reveals exercise hints progressively and suggests hints for
+// failing tests
+type HintService struct {
+	moduleService ModuleServiceInterface
+	store         HintStore
+	now           func() time.Time
+}
+
+// NewHintService creates a hint service recording reveals in store
+func NewHintService(moduleService ModuleServiceInterface, store HintStore) *HintService {
+	return &HintService{
+		moduleService: moduleService,
+		store:         store,
+		now:           time.Now,
+	}
+}
+
+// exercise resolves a module's exercise; an empty ID selects the first one
+func (s *HintService) exercise(moduleId, exerciseId string) (models.Exercise, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return models.Exercise{}, err
+	}
+	exercise, _, err := findExercise(*module, exerciseId)
+	return exercise, err
+}
+
+// progress lists the hints revealed so far
+func (s *HintService) progress(moduleId string, exercise models.Exercise, revealed map[int]time.Time) *models.HintProgress {
+	progress := &models.HintProgress{
+		ModuleID:   moduleId,
+		ExerciseID: exercise.ID,
+		Total:      len(exercise.Hints),
+		Revealed:   []models.RevealedHint{},
+	}
+	for i, hint := range exercise.Hints {
+		at, ok := revealed[i+1]
+		if !ok {
+			progress.Remaining++
+			continue
+		}
+		progress.Revealed = append(progress.Revealed, revealedHint(i+1, hint, at))
+	}
+	return progress
+}
+
+func revealedHint(position int, hint models.Hint, at time.Time) models.RevealedHint {
+	return models.RevealedHint{Position: position, Text: hint.Text, Tests: hint.Tests, RevealedAt: at}
+}
+
+// GetHints returns the hints a learner has revealed for an exercise
+func (s *HintService) GetHints(moduleId, exerciseId, learnerId string) (*models.HintProgress, error) {
+	if learnerId == "" {
+		return nil, ErrLearnerRequired
+	}
+	exercise, err := s.exercise(moduleId, exerciseId)
+	if err != nil {
+		return nil, err
+	}
+	revealed, err := s.store.RevealedHints(learnerId, moduleId, exercise.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(moduleId, exercise, revealed), nil
+}
+
+// RevealNextHint reveals the first hint the learner hasn't seen yet. With a
+// test name only hints about that test are considered. Once every hint is
+// revealed the progress is returned without a new hint.
+func (s *HintService) RevealNextHint(moduleId, exerciseId, learnerId, test string) (*models.HintProgress, error) {
+	if learnerId == "" {
+		return nil, ErrLearnerRequired
+	}
+	exercise, err := s.exercise(moduleId, exerciseId)
+	if err != nil {
+		return nil, err
+	}
+
+	revealed, err := s.store.RevealedHints(learnerId, moduleId, exercise.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var next *models.RevealedHint
+	for i, hint := range exercise.Hints {
+		if _, ok := revealed[i+1]; ok || (test != "" && !hintCoversTest(hint, test)) {
+			continue
+		}
+		at, err := s.store.RevealHint(learnerId, moduleId, exercise.ID, i+1, s.now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		revealed[i+1] = at
+		shown := revealedHint(i+1, hint, revealed[i+1])
+		next = &shown
+		break
+	}
+
+	progress := s.progress(moduleId, exercise, revealed)
+	progress.Hint = next
+	return progress, nil
+}
+
+func hintCoversTest(hint models.Hint, test string) bool {
+	for _, name := range hint.Tests {
+		if name == test {
+			return true
+		}
+	}
+	return false
+}
+
+// SuggestHints adds a suggestion to a test result for every failing test that
+// has a hint, and how many hints the learner used. Without a learner ID no
+// hint counts as revealed.
+func (s *HintService) SuggestHints(moduleId, exerciseId, learnerId string, result *models.TestSuiteResult) error {
+	exercise, err := s.exercise(moduleId, exerciseId)
+	if err != nil {
+		return err
+	}
+
+	var revealed map[int]time.Time
+	if learnerId != "" {
+		if revealed, err = s.store.RevealedHints(learnerId, moduleId, exercise.ID); err != nil {
+			return err
+		}
+	}
+	result.HintsUsed = len(revealed)
+
+	for _, test := range result.Results {
+		if test.Passed {
+			continue
+		}
+		for i, hint := range exercise.Hints {
+			if !hintCoversTest(hint, test.TestName) {
+				continue
+			}
+			suggestion := models.HintSuggestion{Test: test.TestName, Position: i + 1}
+			if _, ok := revealed[i+1]; ok {
+				suggestion.Revealed = true
+				suggestion.Text = hint.Text
+			}
+			result.SuggestedHints = append(result.SuggestedHints, suggestion)
+			break
+		}
+	}
+	return nil
+}
+
+// UsageReport counts how often each hint of a module was revealed
+func (s *HintService) UsageReport(moduleId string) (*models.HintUsageReport, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.store.ModuleReveals(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.HintUsageReport{ModuleID: moduleId, Exercises: []models.ExerciseHintUsage{}}
+	for _, exercise := range module.ExerciseList() {
+		exerciseUsage := models.ExerciseHintUsage{
+			ExerciseID: exercise.ID,
+			Learners:   len(usage[exercise.ID]),
+			Reveals:    make([]int, len(exercise.Hints)),
+		}
+		for _, revealed := range usage[exercise.ID] {
+			for position := range revealed {
+				if position <= len(exercise.Hints) {
+					exerciseUsage.Reveals[position-1]++
+				}
+			}
+		}
+		report.Exercises = append(report.Exercises, exerciseUsage)
+	}
+	return report, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// writeHintedModule writes the stepped module with hints on its first exercise
+func writeHintedModule(t *testing.T, modulesDir string) {
+	t.Helper()
+	writeSteppedModule(t, modulesDir)
+	moduleJSON := strings.Replace(steppedModuleJSON, `"lab": "exercises/hashing/LAB.md",`, `"lab": "exercises/hashing/LAB.md",
+			"hints": [
+				{"text": "Use bcrypt"},
+				{"text": "Compare hashes, not passwords", "tests": ["verifies a password"]},
+				{"text": "Salt every hash", "tests": ["hashes differ", "verifies a password"]}
+			],`, 1)
+	os.WriteFile(filepath.Join(modulesDir, "module-3", "module.json"), []byte(moduleJSON), 0644)
+}
+
+func newTestHintService(t *testing.T, modulesDir string) *HintService {
+	t.Helper()
+	store, err := NewSQLiteHintStore(openTestDB(t, ":memory:"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return NewHintService(NewModuleServiceWithPath(modulesDir), store)
+}
+
+func TestHintService_RevealNextHint(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeHintedModule(t, modulesDir)
+	service := newTestHintService(t, modulesDir)
+
+	if _, err := service.RevealNextHint("module-3", "hashing", "", ""); !errors.Is(err, ErrLearnerRequired) {
+		t.Errorf("Expected ErrLearnerRequired, got %v", err)
+	}
+
+	progress, err := service.RevealNextHint("module-3", "hashing", "learner-1", "hashes differ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if progress.Hint == nil || progress.Hint.Position != 3 || progress.Remaining != 2 {
+		t.Fatalf("Expected the first hint about the test, got %+v", progress)
+	}
+
+	progress, err = service.RevealNextHint("module-3", "", "learner-1", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if progress.ExerciseID != "hashing" || progress.Hint == nil || progress.Hint.Position != 1 {
+		t.Fatalf("Expected the first unrevealed hint of the first exercise, got %+v", progress)
+	}
+
+	service.RevealNextHint("module-3", "hashing", "learner-1", "")
+	progress, err = service.RevealNextHint("module-3", "hashing", "learner-1", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if progress.Hint != nil || progress.Remaining != 0 || len(progress.Revealed) != 3 {
+		t.Errorf("Expected every hint revealed and none left, got %+v", progress)
+	}
+
+	other, err := service.GetHints("module-3", "hashing", "learner-2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(other.Revealed) != 0 || other.Remaining != 3 {
+		t.Errorf("Expected hints to be tracked per learner, got %+v", other)
+	}
+
+	if _, err := service.GetHints("module-3", "missing", "learner-1"); !errors.Is(err, ErrExerciseNotFound) {
+		t.Errorf("Expected ErrExerciseNotFound, got %v", err)
+	}
+}
+
+func TestHintService_SuggestHints(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeHintedModule(t, modulesDir)
+	service := newTestHintService(t, modulesDir)
+
+	service.RevealNextHint("module-3", "hashing", "learner-1", "hashes differ")
+
+	result := &models.TestSuiteResult{
+		Results: []models.TestResult{
+			{TestName: "hashes a password", Passed: true},
+			{TestName: "verifies a password", Passed: false},
+			{TestName: "hashes differ", Passed: false},
+			{TestName: "handles empty input", Passed: false},
+		},
+	}
+	if err := service.SuggestHints("module-3", "hashing", "learner-1", result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []models.HintSuggestion{
+		{Test: "verifies a password", Position: 2},
+		{Test: "hashes differ", Position: 3, Revealed: true, Text: "Salt every hash"},
+	}
+	if len(result.SuggestedHints) != len(expected) {
+		t.Fatalf("Expected %d suggestions, got %+v", len(expected), result.SuggestedHints)
+	}
+	for i, suggestion := range expected {
+		if result.SuggestedHints[i] != suggestion {
+			t.Errorf("Expected suggestion %+v, got %+v", suggestion, result.SuggestedHints[i])
+		}
+	}
+	if result.HintsUsed != 1 {
+		t.Errorf("Expected 1 hint used, got %d", result.HintsUsed)
+	}
+}
+
+func TestHintService_UsageReport(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeHintedModule(t, modulesDir)
+	service := newTestHintService(t, modulesDir)
+
+	service.RevealNextHint("module-3", "hashing", "learner-1", "")
+	service.RevealNextHint("module-3", "hashing", "learner-1", "")
+	service.RevealNextHint("module-3", "hashing", "learner-2", "")
+
+	report, err := service.UsageReport("module-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Exercises) != 2 {
+		t.Fatalf("Expected both exercises, got %+v", report.Exercises)
+	}
+	hashing := report.Exercises[0]
+	if hashing.ExerciseID != "hashing" || hashing.Learners != 2 || hashing.Reveals[0] != 2 || hashing.Reveals[1] != 1 || hashing.Reveals[2] != 0 {
+		t.Errorf("Unexpected hashing usage: %+v", hashing)
+	}
+	if report.Exercises[1].Learners != 0 || len(report.Exercises[1].Reveals) != 0 {
+		t.Errorf("Expected no usage for sessions, got %+v", report.Exercises[1])
+	}
+}
+
+func TestHintService_RevealsPersist(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeHintedModule(t, modulesDir)
+	dbPath := filepath.Join(t.TempDir(), "data", "progress.db")
+
+	store, err := NewSQLiteHintStore(openTestDB(t, dbPath))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first, err := NewHintService(NewModuleServiceWithPath(modulesDir), store).RevealNextHint("module-3", "hashing", "learner-1", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A restarted server sees the same reveal, and revealing again keeps its time
+	reopened, err := NewSQLiteHintStore(openTestDB(t, dbPath))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	progress, err := NewHintService(NewModuleServiceWithPath(modulesDir), reopened).GetHints("module-3", "hashing", "learner-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(progress.Revealed) != 1 || !progress.Revealed[0].RevealedAt.Equal(first.Hint.RevealedAt) {
+		t.Fatalf("Expected the persisted reveal, got %+v", progress)
+	}
+	at, err := reopened.RevealHint("learner-1", "module-3", "hashing", 1, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !at.Equal(first.Hint.RevealedAt) {
+		t.Errorf("Expected the first reveal time %v, got %v", first.Hint.RevealedAt, at)
+	}
+}
@@ -1,17 +1,144 @@
 package services
 
-import "github.com/backend2lab/backend2lab/server/internal/models"
+import (
+	"io"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
 
 // TestRunnerInterface defines the interface for running tests and code
 type TestRunnerInterface interface {
 	RunCode(moduleId, inputCode string) (*models.RunResult, error)
 	RunTests(moduleId, inputCode string) (*models.TestSuiteResult, error)
+	RunExerciseCode(moduleId, exerciseId, inputCode string) (*models.RunResult, error)
+	RunExerciseTests(moduleId, exerciseId, inputCode string) (*models.TestSuiteResult, error)
 }
 
 // ModuleServiceInterface defines the interface for module operations
 type ModuleServiceInterface interface {
 	GetAllModules() ([]models.Module, error)
+	SearchModules(query models.ModuleQuery) (*models.ModuleSearchPage, error)
 	GetModuleById(moduleId string) (*models.Module, error)
 	GetModuleContent(moduleId string) (*models.ModuleContent, error)
 	GetAvailableModules() ([]map[string]string, error)
+	GetModuleVersions(moduleId string) ([]models.ModuleVersion, error)
+	GetModuleVersion(moduleId, version string) (*models.ModuleContent, error)
+	GetModuleExercises(moduleId string) ([]models.ExerciseSummary, error)
+	GetModuleExercise(moduleId, exerciseId string) (*models.ExerciseStep, error)
+	RenderMarkdown(source string) *models.RenderedMarkdown
+	GetModuleAsset(moduleId, path string) (*models.ModuleAsset, error)
+	GetLearningPath() (*models.LearningPath, error)
+	GetModuleDependencies(moduleId string) (*models.ModuleDependencies, error)
+	RecommendNextModule(completed []string) (*models.NextModuleRecommendation, error)
+	Reload() (*models.CatalogStatus, error)
+	ExportModule(moduleId string, w io.Writer) error
+	ImportModule(r io.Reader, opts models.ImportOptions) (*models.ImportResult, error)
+}
+
+// ModuleAuthoringServiceInterface defines the interface for editing module content
+type ModuleAuthoringServiceInterface interface {
+	CreateModule(module models.Module) (*models.AuthoringResult, error)
+	UpdateModule(moduleId string, patch []byte) (*models.AuthoringResult, error)
+	SetModuleStatus(moduleId, status string) (*models.AuthoringResult, error)
+	DeleteModule(moduleId string) error
+	ReadModuleFile(moduleId, path string) ([]byte, error)
+	WriteModuleFile(moduleId, path string, data []byte) (*models.AuthoringResult, error)
+	DeleteModuleFile(moduleId, path string) (*models.AuthoringResult, error)
+}
+
+// SelfCheckServiceInterface defines the interface for verifying module solutions
+type SelfCheckServiceInterface interface {
+	Run(moduleIds []string) (*models.SelfCheckReport, error)
+}
+
+// HintServiceInterface defines the interface for revealing exercise hints
+type HintServiceInterface interface {
+	GetHints(moduleId, exerciseId, learnerId string) (*models.HintProgress, error)
+	RevealNextHint(moduleId, exerciseId, learnerId, test string) (*models.HintProgress, error)
+	SuggestHints(moduleId, exerciseId, learnerId string, result *models.TestSuiteResult) error
+	UsageReport(moduleId string) (*models.HintUsageReport, error)
+}
+
+// SolutionServiceInterface defines the interface for gating exercise solutions
+type SolutionServiceInterface interface {
+	RecordAttempt(moduleId, exerciseId, learnerId string, result *models.TestSuiteResult) error
+	GetSolution(moduleId, exerciseId, learnerId string) (*models.SolutionAccess, error)
+	SetSolutionReleased(moduleId, exerciseId string, released bool) (*models.SolutionAccess, error)
+}
+
+// AccountServiceInterface defines the interface for user accounts and sessions
+type AccountServiceInterface interface {
+	Signup(credentials models.Credentials) (*models.AuthSession, error)
+	Login(credentials models.Credentials) (*models.AuthSession, error)
+	Logout(token string) error
+	Authenticate(token string) (*models.User, error)
+	ListUsers() ([]models.User, error)
+	GetUser(userId string) (*models.User, error)
+	FindUserByEmail(email string) (*models.User, error)
+	SetRole(userId, role string) (*models.User, error)
+	ProvisionUser(email, name, role string) (*models.User, error)
+	StartSession(userId string) (*models.AuthSession, error)
+}
+
+// APIKeyServiceInterface defines the interface for users' API keys
+type APIKeyServiceInterface interface {
+	CreateKey(userId string, request models.APIKeyRequest) (*models.IssuedAPIKey, error)
+	ListKeys(userId string) ([]models.APIKey, error)
+	RotateKey(userId, id string) (*models.IssuedAPIKey, error)
+	RevokeKey(userId, id string) error
+	AuthenticateKey(secret string) (*models.User, *models.APIKey, error)
+}
+
+// ProgressServiceInterface defines the interface for tracking user progress
+type ProgressServiceInterface interface {
+	RecordResult(userId, moduleId, exerciseId string, result *models.TestSuiteResult) (*models.ExerciseProgress, error)
+	GetDashboard(userId string) (*models.ProgressDashboard, error)
+	GetModuleProgress(userId, moduleId string) (*models.ModuleProgress, error)
+	GetCompletionSummary(moduleId string) (*models.ModuleCompletionSummary, error)
+}
+
+// SubmissionServiceInterface defines the interface for the history of graded test runs
+type SubmissionServiceInterface interface {
+	Record(learnerId, moduleId, exerciseId, code string, result *models.TestSuiteResult) (*models.Submission, error)
+	List(filter models.SubmissionFilter) ([]models.SubmissionSummary, error)
+	Get(id string) (*models.Submission, error)
+	Diff(learnerId, fromId, toId string) (*models.SubmissionDiff, error)
+}
+
+// SimilarityServiceInterface defines the interface for finding copied submissions
+type SimilarityServiceInterface interface {
+	Detect(moduleId, exerciseId string, threshold float64) (*models.SimilarityReport, error)
+}
+
+// DraftServiceInterface defines the interface for autosaving users' editor files
+type DraftServiceInterface interface {
+	GetDraft(userId, moduleId, exerciseId, file string) (*models.Draft, error)
+	ListDrafts(userId, moduleId string) ([]models.Draft, error)
+	SaveDraft(userId, moduleId, exerciseId, file, content string, revision int) (*models.Draft, error)
+	ResetDraft(userId, moduleId, exerciseId, file string) (*models.Draft, error)
+}
+
+// CohortServiceInterface defines the interface for classes of students working through assigned modules
+type CohortServiceInterface interface {
+	CreateCohort(name, description, creatorId string) (*models.Cohort, error)
+	ListCohorts(userId string) ([]models.Cohort, error)
+	GetCohort(cohortId string) (*models.Cohort, error)
+	DeleteCohort(cohortId string) error
+	EnrollMember(cohortId, email, role string) (*models.Cohort, error)
+	RemoveMember(cohortId, userId string) (*models.Cohort, error)
+	AssignModule(cohortId, moduleId string, dueAt *time.Time) (*models.Cohort, error)
+	UnassignModule(cohortId, moduleId string) (*models.Cohort, error)
+	IsInstructor(cohortId, userId string) (bool, error)
+	GetReport(cohortId string) (*models.CohortReport, error)
+}
+
+// LTIServiceInterface defines the interface for launching modules from an LMS over LTI 1.3
+type LTIServiceInterface interface {
+	Enabled() bool
+	KeySet() models.JSONWebKeySet
+	BeginLogin(request models.LTILoginRequest) (*models.LTILogin, error)
+	Launch(idToken, state string) (*models.LTILaunch, error)
+	DeepLink(deepLinkId, userId string, moduleIds []string) (*models.LTIDeepLinkResponse, error)
+	PassbackScore(userId, moduleId string, result *models.TestSuiteResult) error
 }
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is the subset of JSON Schema (draft-07) used by the module schema:
+// type, properties, required, additionalProperties, items, enum, minLength,
+// minimum, pattern and local $ref into definitions
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []string               `json:"enum"`
+	MinLength            *int                   `json:"minLength"`
+	Minimum              *float64               `json:"minimum"`
+	Pattern              string                 `json:"pattern"`
+	Definitions          map[string]*jsonSchema `json:"definitions"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaViolation describes where and why a value does not match the schema
+type schemaViolation struct {
+	Path    string
+	Keyword string
+	Message string
+}
+
+// parseJSONSchema parses a schema document and compiles its patterns
+func parseJSONSchema(data []byte) (*jsonSchema, error) {
+	var root jsonSchema
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := root.compile(); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+func (s *jsonSchema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid schema pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for _, child := range s.Properties {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	for _, child := range s.Definitions {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	return s.Items.compile()
+}
+
+// Validate checks a decoded JSON value against the schema
+func (s *jsonSchema) Validate(value interface{}) []schemaViolation {
+	var violations []schemaViolation
+	s.validate(s, "", value, &violations)
+	return violations
+}
+
+func (s *jsonSchema) validate(root *jsonSchema, path string, value interface{}, violations *[]schemaViolation) {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		if def, ok := root.Definitions[name]; ok {
+			def.validate(root, path, value, violations)
+		}
+		return
+	}
+
+	report := func(keyword, format string, args ...interface{}) {
+		*violations = append(*violations, schemaViolation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			report("type", "must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				*violations = append(*violations, schemaViolation{Path: joinSchemaPath(path, name), Keyword: "required", Message: "is required"})
+			}
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			child, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*violations = append(*violations, schemaViolation{Path: joinSchemaPath(path, name), Keyword: "additionalProperties", Message: "is not a known field"})
+				}
+				continue
+			}
+			child.validate(root, joinSchemaPath(path, name), object[name], violations)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			report("type", "must be an array")
+			return
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				s.Items.validate(root, fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			report("type", "must be an integer")
+			return
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			report("minimum", "must be at least %v", *s.Minimum)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			report("type", "must be a string")
+			return
+		}
+		if s.MinLength != nil && utf8.RuneCountInString(str) < *s.MinLength {
+			report("minLength", "must not be empty")
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			report("pattern", "%q does not match %s", str, s.Pattern)
+		}
+		if len(s.Enum) > 0 {
+			for _, allowed := range s.Enum {
+				if str == allowed {
+					return
+				}
+			}
+			report("enum", "%q must be one of %s", str, strings.Join(s.Enum, ", "))
+		}
+	}
+}
+
+func joinSchemaPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ErrInvalidToken is returned for JWTs that are malformed, unsigned or signed
+// with an unknown key
+var ErrInvalidToken = errors.New("invalid token")
+
+// jwtHeader is the JOSE header of a JWT. Only RS256 is used by LTI 1.3.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// signJWT encodes claims as a JWT signed with RS256
+func signJWT(key *rsa.PrivateKey, kid string, claims interface{}) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "RS256", Typ: "JWT", Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseJWT checks a JWT's RS256 signature against the key keyFor returns for
+// its key ID and decodes its claims. Claims such as exp and aud are left to
+// the caller.
+func parseJWT(token string, keyFor func(kid string) (*rsa.PublicKey, error), claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return err
+	}
+	if header.Alg != "RS256" {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	key, err := keyFor(header.Kid)
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+	return decodeJWTPart(parts[1], claims)
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: malformed encoding", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: malformed JSON", ErrInvalidToken)
+	}
+	return nil
+}
+
+// publicJWK describes an RSA public key as a JSON Web Key
+func publicJWK(key *rsa.PublicKey, kid string) models.JSONWebKey {
+	return models.JSONWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// rsaPublicKey decodes an RSA JSON Web Key
+func rsaPublicKey(jwk models.JSONWebKey) (*rsa.PublicKey, error) {
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("%w: key %q is not an RSA key", ErrInvalidToken, jwk.Kid)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %q has a malformed modulus", ErrInvalidToken, jwk.Kid)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("%w: key %q has a malformed exponent", ErrInvalidToken, jwk.Kid)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+const (
+	// keySetTTL is how long a fetched key set is trusted before fetching it again
+	keySetTTL = time.Hour
+	// keySetRefetchInterval limits refetches for unknown key IDs, so tokens
+	// with made-up key IDs can't make the server hammer the key set URL
+	keySetRefetchInterval = time.Minute
+)
+
+// remoteKeySet caches the keys published at a JSON Web Key Set URL, fetching
+// them again once they expire or when a token names a key it doesn't have
+type remoteKeySet struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time // when keys were last fetched
+	attemptedAt time.Time // when a fetch was last tried, even if it failed
+}
+
+func newRemoteKeySet(url string, client *http.Client) *remoteKeySet {
+	return &remoteKeySet{url: url, client: client, now: time.Now}
+}
+
+// key returns the key with a key ID. Without a key ID, the set must hold a
+// single key.
+func (s *remoteKeySet) key(kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	stale := now.Sub(s.fetchedAt) > keySetTTL
+	if key, ok := s.lookup(kid); ok && !stale {
+		return key, nil
+	}
+	// Failed fetches count too, so an unreachable key set isn't retried on
+	// every request
+	if now.Sub(s.attemptedAt) > keySetRefetchInterval {
+		s.attemptedAt = now
+		if err := s.fetch(); err != nil {
+			// Keep trusting cached keys while the platform is unreachable
+			if key, ok := s.lookup(kid); ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		s.fetchedAt = now
+	}
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+}
+
+// lookup finds a cached key; the caller holds mu
+func (s *remoteKeySet) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok && kid != ""
+}
+
+// fetch replaces the cached keys with the published ones; the caller holds mu
+func (s *remoteKeySet) fetch() error {
+	response, err := s.client.Get(s.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch key set: %s", response.Status)
+	}
+
+	var set models.JSONWebKeySet
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("failed to parse key set: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := rsaPublicKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+	return nil
+}
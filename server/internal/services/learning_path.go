@@ -0,0 +1,296 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrPrerequisiteCycle is returned when a change would make module
+// prerequisites depend on each other in a loop
+var ErrPrerequisiteCycle = errors.New("prerequisite cycle")
+
+// learningPath is the prerequisite graph of the published modules, ordered so
+// every module comes after its prerequisites. Modules whose prerequisites
+// form a cycle can't be ordered among themselves; they are listed in cycles.
+type learningPath struct {
+	modules []models.PathModule
+	index   map[string]int
+	cycles  [][]string
+}
+
+// prerequisiteIDs returns the IDs of the modules a module's prerequisites
+// refer to, in order and without duplicates. Free-text prerequisites and
+// references to the module itself are skipped.
+func prerequisiteIDs(module models.Module) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, prerequisite := range module.Prerequisites {
+		id, isReference := prerequisiteModuleID(prerequisite)
+		if !isReference || id == module.ID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// orderByPrerequisites sorts modules so each one comes after the modules it
+// lists as prerequisites, otherwise keeping the given order. Prerequisites on
+// modules that aren't in the list are ignored. When prerequisites form a
+// cycle, the modules on it are placed together in the given order as soon as
+// nothing else can be, and the module IDs along the cycle are returned.
+func orderByPrerequisites(modules []models.Module) ([]models.Module, [][]string) {
+	present := make(map[string]bool, len(modules))
+	for _, module := range modules {
+		present[module.ID] = true
+	}
+
+	pending := make(map[string][]string, len(modules))
+	for _, module := range modules {
+		for _, id := range prerequisiteIDs(module) {
+			if present[id] {
+				pending[module.ID] = append(pending[module.ID], id)
+			}
+		}
+	}
+
+	ordered := make([]models.Module, 0, len(modules))
+	placed := make(map[string]bool, len(modules))
+	remaining := append([]models.Module(nil), modules...)
+	var cycles [][]string
+
+	for len(remaining) > 0 {
+		// Place the first module whose prerequisites have all been placed
+		next := -1
+		for i, module := range remaining {
+			if allPlaced(pending[module.ID], placed) {
+				next = i
+				break
+			}
+		}
+		if next >= 0 {
+			placed[remaining[next].ID] = true
+			ordered = append(ordered, remaining[next])
+			remaining = append(remaining[:next], remaining[next+1:]...)
+			continue
+		}
+
+		cycle := findCycle(remaining, pending, placed)
+		cycles = append(cycles, cycle)
+		onCycle := make(map[string]bool, len(cycle))
+		for _, id := range cycle {
+			onCycle[id] = true
+		}
+		unplaced := remaining[:0]
+		for _, module := range remaining {
+			if onCycle[module.ID] {
+				placed[module.ID] = true
+				ordered = append(ordered, module)
+			} else {
+				unplaced = append(unplaced, module)
+			}
+		}
+		remaining = unplaced
+	}
+
+	return ordered, cycles
+}
+
+func allPlaced(ids []string, placed map[string]bool) bool {
+	for _, id := range ids {
+		if !placed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// findCycle follows unplaced prerequisites from the first remaining module.
+// Every remaining module has one, so the walk must revisit a module.
+func findCycle(remaining []models.Module, pending map[string][]string, placed map[string]bool) []string {
+	var walk []string
+	visited := make(map[string]int)
+	id := remaining[0].ID
+	for {
+		if start, ok := visited[id]; ok {
+			return append(walk[start:], id)
+		}
+		visited[id] = len(walk)
+		walk = append(walk, id)
+		for _, prerequisite := range pending[id] {
+			if !placed[prerequisite] {
+				id = prerequisite
+				break
+			}
+		}
+	}
+}
+
+// buildLearningPath builds the prerequisite graph of the published modules.
+// Prerequisites on drafts or unknown modules are left out. A prerequisite
+// cycle is logged and only affects the order of the modules on it.
+func buildLearningPath(modules []models.Module) *learningPath {
+	published := make([]models.Module, 0, len(modules))
+	for _, module := range modules {
+		if !module.IsDraft() {
+			published = append(published, module)
+		}
+	}
+
+	ordered, cycles := orderByPrerequisites(published)
+	for _, cycle := range cycles {
+		logrus.Warnf("Module prerequisites form a cycle, ordering them as listed: %s", strings.Join(cycle, " -> "))
+	}
+
+	path := &learningPath{
+		modules: make([]models.PathModule, len(ordered)),
+		index:   make(map[string]int, len(ordered)),
+		cycles:  cycles,
+	}
+	for i, module := range ordered {
+		path.index[module.ID] = i
+		path.modules[i] = models.PathModule{
+			ID:            module.ID,
+			Title:         module.Title,
+			Difficulty:    module.Difficulty,
+			Position:      i + 1,
+			Prerequisites: []string{},
+			Dependents:    []string{},
+		}
+	}
+	for i, module := range ordered {
+		for _, id := range prerequisiteIDs(module) {
+			j, ok := path.index[id]
+			if !ok {
+				continue
+			}
+			path.modules[i].Prerequisites = append(path.modules[i].Prerequisites, id)
+			path.modules[j].Dependents = append(path.modules[j].Dependents, module.ID)
+		}
+	}
+
+	return path
+}
+
+// cycleWith returns the prerequisite cycle moduleId is on, if any
+func (p *learningPath) cycleWith(moduleId string) []string {
+	for _, cycle := range p.cycles {
+		if slices.Contains(cycle, moduleId) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// reachable returns the modules linked to moduleId through edges, transitively,
+// in learning path order
+func (p *learningPath) reachable(moduleId string, edges func(models.PathModule) []string) []string {
+	found := make(map[string]bool)
+	queue := []string{moduleId}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, linked := range edges(p.modules[p.index[id]]) {
+			if !found[linked] {
+				found[linked] = true
+				queue = append(queue, linked)
+			}
+		}
+	}
+
+	ids := []string{}
+	for _, module := range p.modules {
+		if found[module.ID] {
+			ids = append(ids, module.ID)
+		}
+	}
+	return ids
+}
+
+// currentLearningPath returns the learning path of the loaded catalog
+func (s *ModuleService) currentLearningPath() (*learningPath, error) {
+	catalog, err := s.currentCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return catalog.path, nil
+}
+
+// GetLearningPath returns the published modules ordered so every module comes
+// after its prerequisites
+func (s *ModuleService) GetLearningPath() (*models.LearningPath, error) {
+	path, err := s.currentLearningPath()
+	if err != nil {
+		return nil, err
+	}
+	learningPath := &models.LearningPath{Modules: append([]models.PathModule(nil), path.modules...)}
+	for _, cycle := range path.cycles {
+		learningPath.Cycles = append(learningPath.Cycles, append([]string(nil), cycle...))
+	}
+	return learningPath, nil
+}
+
+// GetModuleDependencies returns the modules a module builds on and the
+// modules that build on it
+func (s *ModuleService) GetModuleDependencies(moduleId string) (*models.ModuleDependencies, error) {
+	path, err := s.currentLearningPath()
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := path.index[moduleId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, moduleId)
+	}
+
+	return &models.ModuleDependencies{
+		Module:     path.modules[i],
+		Upstream:   path.reachable(moduleId, func(m models.PathModule) []string { return m.Prerequisites }),
+		Downstream: path.reachable(moduleId, func(m models.PathModule) []string { return m.Dependents }),
+	}, nil
+}
+
+// RecommendNextModule suggests the next module to study once the given
+// modules are completed. Unknown module IDs are ignored.
+func (s *ModuleService) RecommendNextModule(completed []string) (*models.NextModuleRecommendation, error) {
+	path, err := s.currentLearningPath()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[string]bool, len(completed))
+	for _, id := range completed {
+		if _, ok := path.index[id]; ok {
+			done[id] = true
+		}
+	}
+
+	recommendation := &models.NextModuleRecommendation{
+		Ready:     []string{},
+		Completed: []string{},
+	}
+	for _, module := range path.modules {
+		if done[module.ID] {
+			recommendation.Completed = append(recommendation.Completed, module.ID)
+			continue
+		}
+		recommendation.Remaining++
+		if !allPlaced(module.Prerequisites, done) {
+			continue
+		}
+		if recommendation.Next == nil {
+			next := module
+			recommendation.Next = &next
+		}
+		recommendation.Ready = append(recommendation.Ready, module.ID)
+	}
+
+	return recommendation, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// writePathModule writes a module.json with the given prerequisites and status
+func writePathModule(t *testing.T, modulesDir, id, prerequisites, status string) {
+	t.Helper()
+	moduleDir := filepath.Join(modulesDir, id)
+	os.MkdirAll(moduleDir, 0755)
+	moduleJSON := `{"id": "` + id + `", "title": "` + id + `", "difficulty": "Beginner",
+		"files": {"lab": {}, "exercise": {}}, "prerequisites": ` + prerequisites
+	if status != "" {
+		moduleJSON += `, "status": "` + status + `"`
+	}
+	os.WriteFile(filepath.Join(moduleDir, "module.json"), []byte(moduleJSON+"}"), 0644)
+}
+
+func pathModuleIDs(modules []models.PathModule) []string {
+	ids := make([]string, 0, len(modules))
+	for _, module := range modules {
+		ids = append(ids, module.ID)
+	}
+	return ids
+}
+
+func newPathService(t *testing.T) *ModuleService {
+	t.Helper()
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writePathModule(t, modulesDir, "module-1", `["Basic JavaScript knowledge"]`, "")
+	writePathModule(t, modulesDir, "module-2", `["module-3", "Module 1: Basics"]`, "")
+	writePathModule(t, modulesDir, "module-3", `["module-1", "module-1"]`, "")
+	writePathModule(t, modulesDir, "module-4", `["module-1"]`, "")
+	writePathModule(t, modulesDir, "module-5", `["module-2", "module-9"]`, "")
+	writePathModule(t, modulesDir, "module-6", `["module-4"]`, models.ModuleStatusDraft)
+	return NewModuleServiceWithPath(modulesDir)
+}
+
+func TestModuleService_GetLearningPath(t *testing.T) {
+	service := newPathService(t)
+
+	path, err := service.GetLearningPath()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// module-2 moves after module-3; the draft module-6 is left out
+	want := []string{"module-1", "module-3", "module-2", "module-4", "module-5"}
+	if got := pathModuleIDs(path.Modules); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected path %v, got %v", want, got)
+	}
+
+	module2 := path.Modules[2]
+	if module2.Position != 3 || !reflect.DeepEqual(module2.Prerequisites, []string{"module-3", "module-1"}) {
+		t.Errorf("Unexpected path entry for module-2: %+v", module2)
+	}
+	if !reflect.DeepEqual(path.Modules[0].Dependents, []string{"module-3", "module-2", "module-4"}) {
+		t.Errorf("Unexpected dependents of module-1: %v", path.Modules[0].Dependents)
+	}
+	if !reflect.DeepEqual(path.Modules[4].Prerequisites, []string{"module-2"}) {
+		t.Errorf("Expected unknown prerequisites to be ignored, got %v", path.Modules[4].Prerequisites)
+	}
+}
+
+func TestModuleService_GetModuleDependencies(t *testing.T) {
+	service := newPathService(t)
+
+	dependencies, err := service.GetModuleDependencies("module-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(dependencies.Upstream, []string{"module-1"}) {
+		t.Errorf("Expected module-1 upstream, got %v", dependencies.Upstream)
+	}
+	if !reflect.DeepEqual(dependencies.Downstream, []string{"module-2", "module-5"}) {
+		t.Errorf("Expected module-2 and module-5 downstream, got %v", dependencies.Downstream)
+	}
+
+	if _, err := service.GetModuleDependencies("module-6"); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("Expected drafts to be missing from the path, got %v", err)
+	}
+}
+
+func TestModuleService_RecommendNextModule(t *testing.T) {
+	service := newPathService(t)
+
+	recommendation, err := service.RecommendNextModule(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if recommendation.Next == nil || recommendation.Next.ID != "module-1" || recommendation.Remaining != 5 {
+		t.Errorf("Expected module-1 first, got %+v", recommendation)
+	}
+
+	recommendation, _ = service.RecommendNextModule([]string{"module-1", "module-4", "module-42"})
+	if recommendation.Next == nil || recommendation.Next.ID != "module-3" {
+		t.Errorf("Expected module-3 next, got %+v", recommendation.Next)
+	}
+	if !reflect.DeepEqual(recommendation.Ready, []string{"module-3"}) ||
+		!reflect.DeepEqual(recommendation.Completed, []string{"module-1", "module-4"}) {
+		t.Errorf("Unexpected recommendation: %+v", recommendation)
+	}
+
+	recommendation, _ = service.RecommendNextModule([]string{"module-1", "module-2", "module-3", "module-4", "module-5"})
+	if recommendation.Next != nil || recommendation.Remaining != 0 {
+		t.Errorf("Expected nothing left to study, got %+v", recommendation)
+	}
+}
+
+func TestModuleService_PrerequisiteCycleDoesNotBlockCatalog(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writePathModule(t, modulesDir, "module-1", `[]`, "")
+	writePathModule(t, modulesDir, "module-2", `["module-3"]`, "")
+	writePathModule(t, modulesDir, "module-3", `["module-2"]`, "")
+	writePathModule(t, modulesDir, "module-4", `["module-1", "module-3"]`, "")
+	service := NewModuleServiceWithPath(modulesDir)
+
+	status, err := service.Reload()
+	if err != nil {
+		t.Fatalf("Expected the catalog to load despite the cycle, got %v", err)
+	}
+	if status.Modules != 4 {
+		t.Errorf("Expected every module to be served, got %d", status.Modules)
+	}
+
+	path, err := service.GetLearningPath()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(path.Cycles, [][]string{{"module-2", "module-3", "module-2"}}) {
+		t.Errorf("Expected the cycle to be reported, got %v", path.Cycles)
+	}
+	var order []string
+	for _, module := range path.Modules {
+		order = append(order, module.ID)
+	}
+	if !reflect.DeepEqual(order, []string{"module-1", "module-2", "module-3", "module-4"}) {
+		t.Errorf("Expected modules on the cycle to be listed together before their dependents, got %v", order)
+	}
+
+	dependencies, err := service.GetModuleDependencies("module-4")
+	if err != nil || !reflect.DeepEqual(dependencies.Upstream, []string{"module-1", "module-2", "module-3"}) {
+		t.Errorf("Expected dependencies through the cycle, got %+v, %v", dependencies, err)
+	}
+	if _, err := service.GetModuleContent("module-1"); err != nil {
+		t.Errorf("Expected other modules to be served, got %v", err)
+	}
+}
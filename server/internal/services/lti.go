@@ -0,0 +1,678 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/config"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+var (
+	// ErrLTIDisabled is returned when no LTI platform is configured
+	ErrLTIDisabled = errors.New("LTI is not configured")
+	// ErrInvalidLTIRequest is returned for logins and launches that don't
+	// come from the configured platform or are missing required claims
+	ErrInvalidLTIRequest = errors.New("invalid LTI request")
+	// ErrDeepLinkNotFound is returned for unknown or expired deep linking
+	// requests, including other users' ones
+	ErrDeepLinkNotFound = errors.New("deep linking request not found")
+)
+
+const (
+	// ltiLoginTTL is how long the platform has to answer a login with a launch
+	ltiLoginTTL = 10 * time.Minute
+	// ltiDeepLinkTTL is how long an instructor has to pick modules to link
+	ltiDeepLinkTTL = time.Hour
+	// ltiClockSkew is how far the platform's clock may be off from ours
+	ltiClockSkew = time.Minute
+	// ltiMessageTTL is how long the messages the tool signs are valid
+	ltiMessageTTL = 5 * time.Minute
+)
+
+// ltiClaimPrefix starts the names of LTI claims
+const ltiClaimPrefix = "https://purl.imsglobal.org/spec/lti/claim/"
+
+// ltiInstructorRoles are the platform roles that teach; their users may
+// deep link modules and new accounts for them are instructors
+var ltiInstructorRoles = []string{
+	"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor",
+	"http://purl.imsglobal.org/vocab/lis/v2/membership/Instructor#TeachingAssistant",
+	"http://purl.imsglobal.org/vocab/lis/v2/membership#ContentDeveloper",
+	"http://purl.imsglobal.org/vocab/lis/v2/membership#Administrator",
+	"http://purl.imsglobal.org/vocab/lis/v2/institution/person#Administrator",
+	"http://purl.imsglobal.org/vocab/lis/v2/system/person#Administrator",
+}
+
+// audience is a JWT aud claim, which is either a string or a list of them
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// ltiLaunchClaims are the claims of a platform's id_token that the tool uses
+type ltiLaunchClaims struct {
+	Issuer          string                 `json:"iss"`
+	Subject         string                 `json:"sub"`
+	Audience        audience               `json:"aud"`
+	AuthorizedParty string                 `json:"azp"`
+	ExpiresAt       int64                  `json:"exp"`
+	IssuedAt        int64                  `json:"iat"`
+	Nonce           string                 `json:"nonce"`
+	Email           string                 `json:"email"`
+	Name            string                 `json:"name"`
+	MessageType     string                 `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version         string                 `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID    string                 `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	TargetLinkURI   string                 `json:"https://purl.imsglobal.org/spec/lti/claim/target_link_uri"`
+	Roles           []string               `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+	Custom          map[string]interface{} `json:"https://purl.imsglobal.org/spec/lti/claim/custom"`
+	Endpoint        *struct {
+		Scope    []string `json:"scope"`
+		LineItem string   `json:"lineitem"`
+	} `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"`
+	DeepLinking *struct {
+		ReturnURL      string `json:"deep_link_return_url"`
+		AcceptMultiple *bool  `json:"accept_multiple"`
+		Data           string `json:"data"`
+	} `json:"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"`
+}
+
+// pendingLogin is a login waiting for the platform's launch
+type pendingLogin struct {
+	nonce     string
+	expiresAt time.Time
+}
+
+// pendingDeepLink is a deep linking request waiting for an instructor to
+// pick modules
+type pendingDeepLink struct {
+	userId         string
+	deploymentId   string
+	returnURL      string
+	data           string
+	acceptMultiple bool
+	expiresAt      time.Time
+}
+
+// LTIService makes the server an LTI 1.3 tool of one platform: it answers
+// OIDC logins, validates launches against the platform's key set and signs
+// users in, returns deep links to modules and passes test scores back to the
+// platform's gradebook with Assignment and Grade Services
+type LTIService struct {
+	config         *config.LTIConfig
+	moduleService  ModuleServiceInterface
+	accountService AccountServiceInterface
+	store          LTIStore
+	client         *http.Client
+	platformKeys   *remoteKeySet
+	key            *rsa.PrivateKey
+	keyId          string
+	now            func() time.Time
+
+	mu          sync.Mutex
+	logins      map[string]pendingLogin    // by state
+	deepLinks   map[string]pendingDeepLink // by ID
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// NewLTIService creates an LTI tool for the platform in cfg. Its signing key
+// is read from cfg.PrivateKeyFile, or generated when there is none. Without
+// a platform, the service is disabled.
+func NewLTIService(cfg *config.LTIConfig, moduleService ModuleServiceInterface, accountService AccountServiceInterface, store LTIStore) (*LTIService, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	s := &LTIService{
+		config:         cfg,
+		moduleService:  moduleService,
+		accountService: accountService,
+		store:          store,
+		client:         client,
+		platformKeys:   newRemoteKeySet(cfg.KeySetURL, client),
+		now:            time.Now,
+		logins:         make(map[string]pendingLogin),
+		deepLinks:      make(map[string]pendingDeepLink),
+	}
+	if !cfg.Enabled() {
+		return s, nil
+	}
+
+	key, err := loadLTIKey(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key.N.Bytes())
+	s.key = key
+	s.keyId = hex.EncodeToString(sum[:8])
+	return s, nil
+}
+
+// loadLTIKey reads a PEM encoded RSA private key, or generates one when path is empty
+func loadLTIKey(path string) (*rsa.PrivateKey, error) {
+	if path == "" {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LTI private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("LTI private key %s is not PEM encoded", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse LTI private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("LTI private key %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// Enabled reports whether a platform is configured
+func (s *LTIService) Enabled() bool {
+	return s.config.Enabled()
+}
+
+// KeySet returns the tool's public key for the platform to verify the
+// messages it signs
+func (s *LTIService) KeySet() models.JSONWebKeySet {
+	if s.key == nil {
+		return models.JSONWebKeySet{Keys: []models.JSONWebKey{}}
+	}
+	return models.JSONWebKeySet{Keys: []models.JSONWebKey{publicJWK(&s.key.PublicKey, s.keyId)}}
+}
+
+// launchURL is where the platform posts launches to
+func (s *LTIService) launchURL() string {
+	return s.config.ToolURL + "/api/lti/launch"
+}
+
+// BeginLogin answers a platform's login initiation with the URL of the
+// platform's authorization endpoint to redirect the browser to, and the state
+// the launch has to come back with
+func (s *LTIService) BeginLogin(request models.LTILoginRequest) (*models.LTILogin, error) {
+	if !s.Enabled() {
+		return nil, ErrLTIDisabled
+	}
+	if request.Issuer != s.config.Issuer {
+		return nil, fmt.Errorf("%w: unknown issuer %q", ErrInvalidLTIRequest, request.Issuer)
+	}
+	if request.ClientID != "" && request.ClientID != s.config.ClientID {
+		return nil, fmt.Errorf("%w: unknown client ID %q", ErrInvalidLTIRequest, request.ClientID)
+	}
+	if request.LoginHint == "" {
+		return nil, fmt.Errorf("%w: login_hint is required", ErrInvalidLTIRequest)
+	}
+	if !strings.HasPrefix(request.TargetLinkURI, s.config.ToolURL+"/") {
+		return nil, fmt.Errorf("%w: target_link_uri is not on this tool", ErrInvalidLTIRequest)
+	}
+
+	state, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := s.now().Add(ltiLoginTTL)
+	s.mu.Lock()
+	s.pruneLocked()
+	s.logins[state] = pendingLogin{nonce: nonce, expiresAt: expiresAt}
+	s.mu.Unlock()
+
+	authURL, err := url.Parse(s.config.AuthURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LTI authorization URL: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("scope", "openid")
+	query.Set("response_type", "id_token")
+	query.Set("response_mode", "form_post")
+	query.Set("prompt", "none")
+	query.Set("client_id", s.config.ClientID)
+	query.Set("redirect_uri", s.launchURL())
+	query.Set("login_hint", request.LoginHint)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	if request.LTIMessageHint != "" {
+		query.Set("lti_message_hint", request.LTIMessageHint)
+	}
+	authURL.RawQuery = query.Encode()
+	return &models.LTILogin{RedirectURL: authURL.String(), State: state, ExpiresAt: expiresAt}, nil
+}
+
+// pruneLocked drops expired logins and deep linking requests; the caller holds mu
+func (s *LTIService) pruneLocked() {
+	now := s.now()
+	for state, login := range s.logins {
+		if now.After(login.expiresAt) {
+			delete(s.logins, state)
+		}
+	}
+	for id, deepLink := range s.deepLinks {
+		if now.After(deepLink.expiresAt) {
+			delete(s.deepLinks, id)
+		}
+	}
+}
+
+// Launch validates the id_token the platform posted for a login and signs
+// its user in. Resource link launches open a module and remember where to
+// pass scores back to; deep linking launches wait for modules to be picked.
+func (s *LTIService) Launch(idToken, state string) (*models.LTILaunch, error) {
+	if !s.Enabled() {
+		return nil, ErrLTIDisabled
+	}
+
+	s.mu.Lock()
+	login, ok := s.logins[state]
+	delete(s.logins, state)
+	s.mu.Unlock()
+	if !ok || s.now().After(login.expiresAt) {
+		return nil, fmt.Errorf("%w: unknown or expired state", ErrInvalidLTIRequest)
+	}
+
+	var claims ltiLaunchClaims
+	if err := parseJWT(idToken, s.platformKeys.key, &claims); err != nil {
+		return nil, err
+	}
+	if err := s.validateLaunch(claims, login.nonce); err != nil {
+		return nil, err
+	}
+
+	user, err := s.launchUser(claims)
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.accountService.StartSession(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	launch := &models.LTILaunch{AuthSession: *session, MessageType: claims.MessageType}
+	fragment := url.Values{"token": {session.Token}}
+
+	switch claims.MessageType {
+	case models.LTIResourceLinkRequest:
+		launch.ModuleID = launchModuleId(claims)
+		if launch.ModuleID == "" {
+			return nil, fmt.Errorf("%w: the launch doesn't name a module", ErrInvalidLTIRequest)
+		}
+		if _, err := s.moduleService.GetModuleById(launch.ModuleID); err != nil {
+			return nil, err
+		}
+		if claims.Endpoint != nil && claims.Endpoint.LineItem != "" && slices.Contains(claims.Endpoint.Scope, models.LTIScoreScope) {
+			if err := s.store.SaveGradeLink(models.LTIGradeLink{
+				UserID:    user.ID,
+				ModuleID:  launch.ModuleID,
+				Subject:   claims.Subject,
+				LineItem:  claims.Endpoint.LineItem,
+				UpdatedAt: s.now().UTC(),
+			}); err != nil {
+				return nil, err
+			}
+		}
+		launch.RedirectURL = s.config.AppURL + "?" + url.Values{"moduleId": {launch.ModuleID}}.Encode() + "#" + fragment.Encode()
+
+	case models.LTIDeepLinkingRequest:
+		if claims.DeepLinking == nil || claims.DeepLinking.ReturnURL == "" {
+			return nil, fmt.Errorf("%w: deep linking settings are missing", ErrInvalidLTIRequest)
+		}
+		if !ltiInstructor(claims.Roles) {
+			return nil, fmt.Errorf("%w: only instructors can link modules", ErrInvalidLTIRequest)
+		}
+		id, err := randomToken(16)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.deepLinks[id] = pendingDeepLink{
+			userId:         user.ID,
+			deploymentId:   claims.DeploymentID,
+			returnURL:      claims.DeepLinking.ReturnURL,
+			data:           claims.DeepLinking.Data,
+			acceptMultiple: claims.DeepLinking.AcceptMultiple == nil || *claims.DeepLinking.AcceptMultiple,
+			expiresAt:      s.now().Add(ltiDeepLinkTTL),
+		}
+		s.mu.Unlock()
+		launch.DeepLinkID = id
+		launch.RedirectURL = s.config.AppURL + "?" + url.Values{"deepLinkId": {id}}.Encode() + "#" + fragment.Encode()
+	}
+	return launch, nil
+}
+
+// validateLaunch checks that an id_token was issued by the configured
+// platform for this tool and login
+func (s *LTIService) validateLaunch(claims ltiLaunchClaims, nonce string) error {
+	now := s.now()
+	switch {
+	case claims.Issuer != s.config.Issuer:
+		return fmt.Errorf("%w: unknown issuer %q", ErrInvalidToken, claims.Issuer)
+	case !slices.Contains(claims.Audience, s.config.ClientID):
+		return fmt.Errorf("%w: not issued to this tool", ErrInvalidToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != s.config.ClientID:
+		return fmt.Errorf("%w: authorized party is not this tool", ErrInvalidToken)
+	case now.After(time.Unix(claims.ExpiresAt, 0).Add(ltiClockSkew)):
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	case time.Unix(claims.IssuedAt, 0).After(now.Add(ltiClockSkew)):
+		return fmt.Errorf("%w: issued in the future", ErrInvalidToken)
+	case claims.Nonce != nonce:
+		return fmt.Errorf("%w: nonce doesn't match the login", ErrInvalidToken)
+	case claims.Subject == "":
+		return fmt.Errorf("%w: anonymous launches aren't supported", ErrInvalidLTIRequest)
+	case claims.Version != models.LTIVersion:
+		return fmt.Errorf("%w: unsupported LTI version %q", ErrInvalidLTIRequest, claims.Version)
+	case claims.MessageType != models.LTIResourceLinkRequest && claims.MessageType != models.LTIDeepLinkingRequest:
+		return fmt.Errorf("%w: unsupported message type %q", ErrInvalidLTIRequest, claims.MessageType)
+	case claims.DeploymentID == "":
+		return fmt.Errorf("%w: deployment ID is missing", ErrInvalidLTIRequest)
+	case len(s.config.DeploymentIDs) > 0 && !slices.Contains(s.config.DeploymentIDs, claims.DeploymentID):
+		return fmt.Errorf("%w: unknown deployment %q", ErrInvalidLTIRequest, claims.DeploymentID)
+	}
+	return nil
+}
+
+// launchUser returns the account a platform user signs in as, creating a new
+// one on their first launch. Platform emails aren't verified, so a launch is
+// never matched to an existing account by email: platform users without an
+// email, or whose email already has an account, get a placeholder one.
+func (s *LTIService) launchUser(claims ltiLaunchClaims) (*models.User, error) {
+	userId, err := s.store.LinkedUser(claims.Issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if userId != "" {
+		return s.accountService.GetUser(userId)
+	}
+
+	role := models.RoleStudent
+	if ltiInstructor(claims.Roles) {
+		role = models.RoleInstructor
+	}
+	var user *models.User
+	if claims.Email != "" {
+		user, err = s.accountService.ProvisionUser(claims.Email, claims.Name, role)
+		if err != nil && !errors.Is(err, ErrEmailTaken) {
+			return nil, err
+		}
+	}
+	if user == nil {
+		placeholder, err := randomToken(8)
+		if err != nil {
+			return nil, err
+		}
+		user, err = s.accountService.ProvisionUser("lti-"+placeholder+"@lti.invalid", claims.Name, role)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.store.LinkUser(claims.Issuer, claims.Subject, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// launchModuleId reads the module a resource link opens from its moduleId
+// custom parameter, or else the moduleId query parameter of its target link
+func launchModuleId(claims ltiLaunchClaims) string {
+	if moduleId, ok := claims.Custom["moduleId"].(string); ok && moduleId != "" {
+		return moduleId
+	}
+	if target, err := url.Parse(claims.TargetLinkURI); err == nil {
+		return target.Query().Get("moduleId")
+	}
+	return ""
+}
+
+func ltiInstructor(roles []string) bool {
+	for _, role := range roles {
+		if slices.Contains(ltiInstructorRoles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ltiContentItem is a link to a module returned in a deep linking response
+type ltiContentItem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text,omitempty"`
+	URL      string            `json:"url"`
+	Custom   map[string]string `json:"custom"`
+	LineItem *ltiLineItem      `json:"lineItem,omitempty"`
+}
+
+type ltiLineItem struct {
+	ScoreMaximum float64 `json:"scoreMaximum"`
+	Label        string  `json:"label"`
+	ResourceID   string  `json:"resourceId"`
+}
+
+// DeepLink returns the signed deep linking response that links the picked
+// modules in the platform. Each link gets a line item scored out of 100.
+func (s *LTIService) DeepLink(deepLinkId, userId string, moduleIds []string) (*models.LTIDeepLinkResponse, error) {
+	if !s.Enabled() {
+		return nil, ErrLTIDisabled
+	}
+
+	s.mu.Lock()
+	request, ok := s.deepLinks[deepLinkId]
+	s.mu.Unlock()
+	if !ok || request.userId != userId || s.now().After(request.expiresAt) {
+		return nil, ErrDeepLinkNotFound
+	}
+	if len(moduleIds) == 0 {
+		return nil, fmt.Errorf("%w: pick at least one module", ErrInvalidLTIRequest)
+	}
+	if len(moduleIds) > 1 && !request.acceptMultiple {
+		return nil, fmt.Errorf("%w: the platform only accepts one module", ErrInvalidLTIRequest)
+	}
+
+	items := make([]ltiContentItem, 0, len(moduleIds))
+	for _, moduleId := range moduleIds {
+		module, err := s.moduleService.GetModuleById(moduleId)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, ltiContentItem{
+			Type:   models.LTIResourceLinkItemType,
+			Title:  module.Title,
+			Text:   module.Description,
+			URL:    s.launchURL(),
+			Custom: map[string]string{"moduleId": module.ID},
+			LineItem: &ltiLineItem{
+				ScoreMaximum: 100,
+				Label:        module.Title,
+				ResourceID:   module.ID,
+			},
+		})
+	}
+
+	nonce, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	claims := map[string]interface{}{
+		"iss":                            s.config.ClientID,
+		"aud":                            s.config.Issuer,
+		"iat":                            now.Unix(),
+		"exp":                            now.Add(ltiMessageTTL).Unix(),
+		"nonce":                          nonce,
+		ltiClaimPrefix + "message_type":  models.LTIDeepLinkingResponse,
+		ltiClaimPrefix + "version":       models.LTIVersion,
+		ltiClaimPrefix + "deployment_id": request.deploymentId,
+		"https://purl.imsglobal.org/spec/lti-dl/claim/content_items": items,
+	}
+	if request.data != "" {
+		claims["https://purl.imsglobal.org/spec/lti-dl/claim/data"] = request.data
+	}
+	jwt, err := signJWT(s.key, s.keyId, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.deepLinks, deepLinkId)
+	s.mu.Unlock()
+	return &models.LTIDeepLinkResponse{ReturnURL: request.returnURL, JWT: jwt}, nil
+}
+
+// PassbackScore posts a test run's score, the percentage of tests passed, to
+// the platform line item of the resource link the user launched the module
+// from. Users who didn't launch the module with grade passback are skipped.
+func (s *LTIService) PassbackScore(userId, moduleId string, result *models.TestSuiteResult) error {
+	if !s.Enabled() {
+		return nil
+	}
+	link, err := s.store.GradeLink(userId, moduleId)
+	if err != nil || link == nil {
+		return err
+	}
+
+	score := 0.0
+	if result.TotalTests > 0 {
+		score = 100 * float64(result.PassedTests) / float64(result.TotalTests)
+	}
+	activity := "InProgress"
+	if result.Passed() {
+		activity = "Completed"
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"userId":           link.Subject,
+		"scoreGiven":       score,
+		"scoreMaximum":     100,
+		"activityProgress": activity,
+		"gradingProgress":  "FullyGraded",
+		"timestamp":        s.now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		"comment":          fmt.Sprintf("%d of %d tests passed", result.PassedTests, result.TotalTests),
+	})
+	if err != nil {
+		return err
+	}
+
+	scoresURL, err := url.Parse(link.LineItem)
+	if err != nil {
+		return fmt.Errorf("invalid line item URL %q: %w", link.LineItem, err)
+	}
+	scoresURL.Path = strings.TrimSuffix(scoresURL.Path, "/") + "/scores"
+
+	token, err := s.platformAccessToken()
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, scoresURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", models.LTIScoreContentType)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to post score: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode == http.StatusUnauthorized {
+		// The platform may revoke tokens early; fetch a new one next time
+		s.mu.Lock()
+		s.accessToken = ""
+		s.mu.Unlock()
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("failed to post score: %s", response.Status)
+	}
+	return nil
+}
+
+// platformAccessToken returns an access token for posting scores, requesting
+// one with a signed client assertion when the cached one is about to expire
+func (s *LTIService) platformAccessToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.accessToken != "" && now.Add(time.Minute).Before(s.tokenExpiry) {
+		return s.accessToken, nil
+	}
+
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	assertion, err := signJWT(s.key, s.keyId, map[string]interface{}{
+		"iss": s.config.ClientID,
+		"sub": s.config.ClientID,
+		"aud": s.config.TokenURL,
+		"iat": now.Unix(),
+		"exp": now.Add(ltiMessageTTL).Unix(),
+		"jti": jti,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	response, err := s.client.PostForm(s.config.TokenURL, url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+		"scope":                 {models.LTIScoreScope},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to request access token: %s", response.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<16)).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("failed to parse access token response: %v", err)
+	}
+	if token.ExpiresIn <= 0 {
+		token.ExpiresIn = 3600
+	}
+	s.accessToken = token.AccessToken
+	s.tokenExpiry = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// LTIStore persists which account each platform user signs in as and where
+// their scores are passed back to
+type LTIStore interface {
+	// LinkedUser returns the user ID a platform user signs in as, or "" when
+	// they haven't launched before
+	LinkedUser(issuer, subject string) (string, error)
+	LinkUser(issuer, subject, userId string) error
+	// GradeLink returns where a user's scores on a module go, or nil when
+	// they weren't launched with grade passback
+	GradeLink(userId, moduleId string) (*models.LTIGradeLink, error)
+	// SaveGradeLink replaces the grade link of a user and module
+	SaveGradeLink(link models.LTIGradeLink) error
+}
+
+const ltiSchema = `
+CREATE TABLE IF NOT EXISTS lti_users (
+	issuer  TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	PRIMARY KEY (issuer, subject)
+);
+CREATE TABLE IF NOT EXISTS lti_grade_links (
+	user_id    TEXT NOT NULL,
+	module_id  TEXT NOT NULL,
+	subject    TEXT NOT NULL,
+	line_item  TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	PRIMARY KEY (user_id, module_id)
+);
+`
+
+// SQLiteLTIStore keeps LTI links in a SQLite database
+type SQLiteLTIStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteLTIStore keeps LTI links in db, creating its tables if needed
+func NewSQLiteLTIStore(db *sql.DB) (*SQLiteLTIStore, error) {
+	if _, err := db.Exec(ltiSchema); err != nil {
+		return nil, fmt.Errorf("failed to create LTI tables: %w", err)
+	}
+	return &SQLiteLTIStore{db: db}, nil
+}
+
+// LinkedUser returns the user ID a platform user signs in as
+func (s *SQLiteLTIStore) LinkedUser(issuer, subject string) (string, error) {
+	var userId string
+	err := s.db.QueryRow(`SELECT user_id FROM lti_users WHERE issuer = ? AND subject = ?`, issuer, subject).Scan(&userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load LTI user: %w", err)
+	}
+	return userId, nil
+}
+
+// LinkUser makes a platform user sign in as userId
+func (s *SQLiteLTIStore) LinkUser(issuer, subject, userId string) error {
+	_, err := s.db.Exec(`INSERT INTO lti_users (issuer, subject, user_id) VALUES (?, ?, ?)
+		ON CONFLICT (issuer, subject) DO UPDATE SET user_id = excluded.user_id`,
+		issuer, subject, userId)
+	if err != nil {
+		return fmt.Errorf("failed to link LTI user: %w", err)
+	}
+	return nil
+}
+
+// GradeLink returns where a user's scores on a module go, or nil
+func (s *SQLiteLTIStore) GradeLink(userId, moduleId string) (*models.LTIGradeLink, error) {
+	link := models.LTIGradeLink{UserID: userId, ModuleID: moduleId}
+	var updatedAt string
+	err := s.db.QueryRow(`SELECT subject, line_item, updated_at FROM lti_grade_links WHERE user_id = ? AND module_id = ?`,
+		userId, moduleId).Scan(&link.Subject, &link.LineItem, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load grade link: %w", err)
+	}
+	if link.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to load grade link: %w", err)
+	}
+	return &link, nil
+}
+
+// SaveGradeLink replaces the grade link of a user and module
+func (s *SQLiteLTIStore) SaveGradeLink(link models.LTIGradeLink) error {
+	_, err := s.db.Exec(`INSERT INTO lti_grade_links (user_id, module_id, subject, line_item, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, module_id) DO UPDATE SET
+			subject    = excluded.subject,
+			line_item  = excluded.line_item,
+			updated_at = excluded.updated_at`,
+		link.UserID, link.ModuleID, link.Subject, link.LineItem, formatSQLiteTime(link.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save grade link: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/config"
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// mockLTIPlatform is an LMS that publishes a key set, issues access tokens
+// to the tool and records the scores posted to its line item
+type mockLTIPlatform struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	tool   *LTIService
+	scores chan map[string]interface{}
+}
+
+func newMockLTIPlatform(t *testing.T) *mockLTIPlatform {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	platform := &mockLTIPlatform{t: t, key: key, scores: make(chan map[string]interface{}, 10)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.JSONWebKeySet{Keys: []models.JSONWebKey{publicJWK(&key.PublicKey, "platform-key")}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		var claims map[string]interface{}
+		keyFor := func(kid string) (*rsa.PublicKey, error) {
+			return rsaPublicKey(platform.tool.KeySet().Keys[0])
+		}
+		if err := parseJWT(r.PostFormValue("client_assertion"), keyFor, &claims); err != nil || claims["sub"] != "tool-client" {
+			http.Error(w, "bad client assertion", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "platform-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/lineitems/7/lineitem/scores", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer platform-token" || r.Header.Get("Content-Type") != models.LTIScoreContentType {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var score map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&score)
+		platform.scores <- score
+		w.WriteHeader(http.StatusNoContent)
+	})
+	platform.server = httptest.NewServer(mux)
+	t.Cleanup(platform.server.Close)
+	return platform
+}
+
+func (p *mockLTIPlatform) config() *config.LTIConfig {
+	return &config.LTIConfig{
+		Issuer:        "https://lms.example.edu",
+		ClientID:      "tool-client",
+		DeploymentIDs: []string{"deployment-1"},
+		AuthURL:       p.server.URL + "/auth",
+		KeySetURL:     p.server.URL + "/jwks",
+		TokenURL:      p.server.URL + "/token",
+		ToolURL:       "https://lab.example.edu",
+		AppURL:        "https://lab.example.edu/app",
+	}
+}
+
+// idToken signs launch claims with the platform key, filling in the claims
+// every launch has
+func (p *mockLTIPlatform) idToken(nonce string, claims map[string]interface{}) string {
+	p.t.Helper()
+	now := time.Now()
+	full := map[string]interface{}{
+		"iss":                            "https://lms.example.edu",
+		"aud":                            "tool-client",
+		"sub":                            "lms-user-1",
+		"iat":                            now.Unix(),
+		"exp":                            now.Add(5 * time.Minute).Unix(),
+		"nonce":                          nonce,
+		"email":                          "ada@example.edu",
+		"name":                           "Ada",
+		ltiClaimPrefix + "version":       models.LTIVersion,
+		ltiClaimPrefix + "message_type":  models.LTIResourceLinkRequest,
+		ltiClaimPrefix + "deployment_id": "deployment-1",
+		ltiClaimPrefix + "roles":         []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"},
+		ltiClaimPrefix + "custom":        map[string]string{"moduleId": "module-3"},
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(full, name)
+		} else {
+			full[name] = value
+		}
+	}
+	token, err := signJWT(p.key, "platform-key", full)
+	if err != nil {
+		p.t.Fatalf("Expected no error, got %v", err)
+	}
+	return token
+}
+
+// login starts a login and returns the state and nonce sent to the platform
+func login(t *testing.T, service *LTIService) (string, string) {
+	t.Helper()
+	started, err := service.BeginLogin(models.LTILoginRequest{
+		Issuer:        "https://lms.example.edu",
+		LoginHint:     "lms-user-1",
+		TargetLinkURI: "https://lab.example.edu/api/lti/launch",
+		ClientID:      "tool-client",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	parsed, _ := url.Parse(started.RedirectURL)
+	query := parsed.Query()
+	if query.Get("redirect_uri") != "https://lab.example.edu/api/lti/launch" || query.Get("response_mode") != "form_post" || query.Get("login_hint") != "lms-user-1" {
+		t.Errorf("Unexpected authorization request: %s", started.RedirectURL)
+	}
+	if query.Get("state") != started.State {
+		t.Errorf("Expected the state %s to be sent to the platform, got %s", started.State, query.Get("state"))
+	}
+	return query.Get("state"), query.Get("nonce")
+}
+
+func newTestLTIService(t *testing.T, platform *mockLTIPlatform) (*LTIService, *AccountService) {
+	t.Helper()
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	db := openTestDB(t, ":memory:")
+	store, err := NewSQLiteLTIStore(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	accounts := newTestAccountService(t, db)
+	service, err := NewLTIService(platform.config(), NewModuleServiceWithPath(modulesDir), accounts, store)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	platform.tool = service
+	return service, accounts
+}
+
+func TestLTIService_Launch(t *testing.T) {
+	platform := newMockLTIPlatform(t)
+	service, accounts := newTestLTIService(t, platform)
+
+	if _, err := service.BeginLogin(models.LTILoginRequest{Issuer: "https://other.example.edu", LoginHint: "x", TargetLinkURI: "https://lab.example.edu/"}); !errors.Is(err, ErrInvalidLTIRequest) {
+		t.Errorf("Expected ErrInvalidLTIRequest for another issuer, got %v", err)
+	}
+
+	state, nonce := login(t, service)
+	launch, err := service.Launch(platform.idToken(nonce, map[string]interface{}{
+		"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint": map[string]interface{}{
+			"scope":    []string{models.LTIScoreScope},
+			"lineitem": platform.server.URL + "/lineitems/7/lineitem?type=quiz",
+		},
+	}), state)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if launch.ModuleID != "module-3" || launch.User.Email != "ada@example.edu" || launch.User.Role != models.RoleStudent {
+		t.Errorf("Unexpected launch: %+v", launch)
+	}
+	if launch.RedirectURL != "https://lab.example.edu/app?moduleId=module-3#token="+launch.Token {
+		t.Errorf("Unexpected redirect: %s", launch.RedirectURL)
+	}
+	if user, err := accounts.Authenticate(launch.Token); err != nil || user.ID != launch.User.ID {
+		t.Errorf("Expected the launch to sign the user in, got %+v, %v", user, err)
+	}
+
+	// States are single use, so launches can't be replayed
+	if _, err := service.Launch(platform.idToken(nonce, nil), state); !errors.Is(err, ErrInvalidLTIRequest) {
+		t.Errorf("Expected ErrInvalidLTIRequest for a used state, got %v", err)
+	}
+
+	// The same platform user signs in as the same account
+	state, nonce = login(t, service)
+	again, err := service.Launch(platform.idToken(nonce, nil), state)
+	if err != nil || again.User.ID != launch.User.ID {
+		t.Errorf("Expected the same account, got %+v, %v", again, err)
+	}
+
+	// Platform emails aren't verified, so they never sign in as an existing
+	// account with that email
+	admin, err := accounts.Signup(models.Credentials{Email: "admin@example.edu", Password: "administrator"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := accounts.SetRole(admin.User.ID, models.RoleAdmin); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	state, nonce = login(t, service)
+	impostor, err := service.Launch(platform.idToken(nonce, map[string]interface{}{"sub": "lms-user-2", "email": "admin@example.edu"}), state)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if impostor.User.ID == admin.User.ID || impostor.User.Email == "admin@example.edu" || impostor.User.Role != models.RoleStudent {
+		t.Errorf("Expected a new student account with a placeholder email, got %+v", impostor.User)
+	}
+
+	if err := service.PassbackScore(launch.User.ID, "module-3", &models.TestSuiteResult{TotalTests: 4, PassedTests: 3}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	score := <-platform.scores
+	if score["userId"] != "lms-user-1" || score["scoreGiven"] != 75.0 || score["scoreMaximum"] != 100.0 || score["activityProgress"] != "InProgress" {
+		t.Errorf("Unexpected score: %+v", score)
+	}
+	if err := service.PassbackScore("someone-else", "module-3", &models.TestSuiteResult{}); err != nil {
+		t.Errorf("Expected users without a grade link to be skipped, got %v", err)
+	}
+}
+
+func TestLTIService_RejectsInvalidLaunches(t *testing.T) {
+	platform := newMockLTIPlatform(t)
+	service, _ := newTestLTIService(t, platform)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name   string
+		token  func(nonce string) string
+		expect error
+	}{
+		{"wrong signature", func(nonce string) string {
+			token, _ := signJWT(otherKey, "platform-key", map[string]interface{}{"nonce": nonce})
+			return token
+		}, ErrInvalidToken},
+		{"other audience", func(nonce string) string {
+			return platform.idToken(nonce, map[string]interface{}{"aud": "another-tool"})
+		}, ErrInvalidToken},
+		{"expired", func(nonce string) string {
+			return platform.idToken(nonce, map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})
+		}, ErrInvalidToken},
+		{"other nonce", func(nonce string) string {
+			return platform.idToken("not-the-nonce", nil)
+		}, ErrInvalidToken},
+		{"unknown deployment", func(nonce string) string {
+			return platform.idToken(nonce, map[string]interface{}{ltiClaimPrefix + "deployment_id": "deployment-2"})
+		}, ErrInvalidLTIRequest},
+		{"no module", func(nonce string) string {
+			return platform.idToken(nonce, map[string]interface{}{ltiClaimPrefix + "custom": nil})
+		}, ErrInvalidLTIRequest},
+		{"unknown module", func(nonce string) string {
+			return platform.idToken(nonce, map[string]interface{}{ltiClaimPrefix + "custom": map[string]string{"moduleId": "module-9"}})
+		}, ErrModuleNotFound},
+		{"learner deep linking", func(nonce string) string {
+			return platform.idToken(nonce, map[string]interface{}{
+				ltiClaimPrefix + "message_type":                                      models.LTIDeepLinkingRequest,
+				"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings": map[string]string{"deep_link_return_url": "https://lms.example.edu/return"},
+			})
+		}, ErrInvalidLTIRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, nonce := login(t, service)
+			if _, err := service.Launch(tt.token(nonce), state); !errors.Is(err, tt.expect) {
+				t.Errorf("Expected %v, got %v", tt.expect, err)
+			}
+		})
+	}
+}
+
+func TestLTIService_DeepLink(t *testing.T) {
+	platform := newMockLTIPlatform(t)
+	service, _ := newTestLTIService(t, platform)
+
+	state, nonce := login(t, service)
+	launch, err := service.Launch(platform.idToken(nonce, map[string]interface{}{
+		ltiClaimPrefix + "message_type": models.LTIDeepLinkingRequest,
+		ltiClaimPrefix + "roles":        []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"},
+		ltiClaimPrefix + "custom":       nil,
+		"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings": map[string]interface{}{
+			"deep_link_return_url": "https://lms.example.edu/return",
+			"accept_multiple":      false,
+			"data":                 "opaque",
+		},
+	}), state)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if launch.DeepLinkID == "" || launch.User.Role != models.RoleInstructor {
+		t.Fatalf("Expected an instructor deep linking launch, got %+v", launch)
+	}
+
+	if _, err := service.DeepLink(launch.DeepLinkID, "someone-else", []string{"module-3"}); !errors.Is(err, ErrDeepLinkNotFound) {
+		t.Errorf("Expected ErrDeepLinkNotFound for another user, got %v", err)
+	}
+	if _, err := service.DeepLink(launch.DeepLinkID, launch.User.ID, []string{"module-3", "module-3"}); !errors.Is(err, ErrInvalidLTIRequest) {
+		t.Errorf("Expected ErrInvalidLTIRequest for several modules, got %v", err)
+	}
+
+	response, err := service.DeepLink(launch.DeepLinkID, launch.User.ID, []string{"module-3"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if response.ReturnURL != "https://lms.example.edu/return" {
+		t.Errorf("Unexpected return URL: %s", response.ReturnURL)
+	}
+
+	var claims struct {
+		Audience     string `json:"aud"`
+		MessageType  string `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+		Data         string `json:"https://purl.imsglobal.org/spec/lti-dl/claim/data"`
+		ContentItems []struct {
+			Type   string            `json:"type"`
+			URL    string            `json:"url"`
+			Custom map[string]string `json:"custom"`
+		} `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+	}
+	keyFor := func(kid string) (*rsa.PublicKey, error) { return rsaPublicKey(service.KeySet().Keys[0]) }
+	if err := parseJWT(response.JWT, keyFor, &claims); err != nil {
+		t.Fatalf("Expected a JWT signed with the tool key, got %v", err)
+	}
+	if claims.Audience != "https://lms.example.edu" || claims.MessageType != models.LTIDeepLinkingResponse || claims.Data != "opaque" ||
+		len(claims.ContentItems) != 1 || claims.ContentItems[0].Custom["moduleId"] != "module-3" ||
+		claims.ContentItems[0].URL != "https://lab.example.edu/api/lti/launch" {
+		t.Errorf("Unexpected deep linking response: %+v", claims)
+	}
+
+	if _, err := service.DeepLink(launch.DeepLinkID, launch.User.ID, []string{"module-3"}); !errors.Is(err, ErrDeepLinkNotFound) {
+		t.Errorf("Expected deep linking requests to be answered once, got %v", err)
+	}
+}
+
+func TestRemoteKeySet_LimitsFailedFetches(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var fetches atomic.Int32
+	var available atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if !available.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(models.JSONWebKeySet{Keys: []models.JSONWebKey{publicJWK(&key.PublicKey, "platform-key")}})
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	keys := newRemoteKeySet(server.URL, server.Client())
+	keys.now = func() time.Time { return now }
+
+	// A failed fetch isn't retried until the refetch interval has passed
+	for i := 0; i < 3; i++ {
+		if _, err := keys.key("platform-key"); err == nil {
+			t.Fatal("Expected an error while the key set is unavailable")
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("Expected 1 fetch, got %d", n)
+	}
+
+	available.Store(true)
+	now = now.Add(keySetRefetchInterval + time.Second)
+	if _, err := keys.key("platform-key"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("Expected a second fetch after the interval, got %d", n)
+	}
+
+	// Unknown key IDs are limited the same way
+	keys.key("made-up")
+	keys.key("made-up")
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("Expected no fetch for unknown keys within the interval, got %d", n)
+	}
+}
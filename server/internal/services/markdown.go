@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"gopkg.in/yaml.v3"
+)
+
+// maxCachedMarkdown bounds how many rendered documents are kept in memory
+const maxCachedMarkdown = 512
+
+var (
+	// markdown parses CommonMark, giving headings unique IDs. Raw HTML is
+	// escaped rather than passed through.
+	markdown = goldmark.New(goldmark.WithParserOptions(parser.WithAutoHeadingID()))
+	// markdownPolicy strips anything unsafe from rendered HTML, keeping
+	// heading anchors and code block languages
+	markdownPolicy = func() *bluemonday.Policy {
+		policy := bluemonday.UGCPolicy()
+		policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+		return policy
+	}()
+)
+
+// MarkdownRenderer renders lab and exercise READMEs to sanitized HTML with a
+// table of contents, the code blocks they contain and their front matter.
+// Results are cached by source.
+type MarkdownRenderer struct {
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]*models.RenderedMarkdown
+}
+
+// NewMarkdownRenderer creates a renderer with an empty cache
+func NewMarkdownRenderer() *MarkdownRenderer {
+	return &MarkdownRenderer{cache: make(map[[sha256.Size]byte]*models.RenderedMarkdown)}
+}
+
+// Render returns the rendered form of a markdown document. The result is
+// shared between callers and must not be modified.
+func (r *MarkdownRenderer) Render(source string) *models.RenderedMarkdown {
+	key := sha256.Sum256([]byte(source))
+
+	r.mu.Lock()
+	rendered, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok {
+		return rendered
+	}
+
+	rendered = renderMarkdown(source)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cache) >= maxCachedMarkdown {
+		r.cache = make(map[[sha256.Size]byte]*models.RenderedMarkdown)
+	}
+	r.cache[key] = rendered
+	return rendered
+}
+
+// renderMarkdown renders a markdown document without caching
+func renderMarkdown(source string) *models.RenderedMarkdown {
+	source = strings.TrimPrefix(source, "\uFEFF")
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	frontMatter, body := splitFrontMatter(source)
+
+	rendered := &models.RenderedMarkdown{
+		TOC:         []models.TOCEntry{},
+		CodeBlocks:  []models.CodeBlock{},
+		FrontMatter: frontMatter,
+	}
+
+	src := []byte(body)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.Heading:
+			anchor, _ := n.AttributeString("id")
+			id, _ := anchor.([]byte)
+			rendered.TOC = append(rendered.TOC, models.TOCEntry{Level: n.Level, Text: plainText(n, src), Anchor: string(id)})
+		case *ast.FencedCodeBlock:
+			var code bytes.Buffer
+			for i := 0; i < n.Lines().Len(); i++ {
+				line := n.Lines().At(i)
+				code.Write(line.Value(src))
+			}
+			rendered.CodeBlocks = append(rendered.CodeBlocks, models.CodeBlock{Language: string(n.Language(src)), Code: code.String()})
+		}
+		return ast.WalkContinue, nil
+	})
+
+	var out bytes.Buffer
+	if err := markdown.Renderer().Render(&out, src, doc); err != nil {
+		// Rendering only fails when writing fails, which a buffer doesn't
+		return rendered
+	}
+	rendered.HTML = markdownPolicy.Sanitize(out.String())
+	return rendered
+}
+
+// plainText returns the text of a node's inline content without markup
+func plainText(node ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := n.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
+
+// splitFrontMatter separates a leading YAML block delimited by --- lines from
+// the document. Documents whose front matter isn't a YAML mapping are left
+// untouched.
+func splitFrontMatter(source string) (map[string]interface{}, string) {
+	if !strings.HasPrefix(source, "---\n") {
+		return nil, source
+	}
+	rest := source[len("---\n"):]
+
+	end, next := -1, -1
+	for offset := 0; offset <= len(rest); {
+		lineEnd := strings.IndexByte(rest[offset:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(rest) - offset
+		}
+		line := strings.TrimRight(rest[offset:offset+lineEnd], " \t")
+		if line == "---" || line == "..." {
+			end, next = offset, min(offset+lineEnd+1, len(rest))
+			break
+		}
+		offset += lineEnd + 1
+	}
+	if end < 0 {
+		return nil, source
+	}
+
+	var frontMatter map[string]interface{}
+	if err := yaml.Unmarshal([]byte(rest[:end]), &frontMatter); err != nil || frontMatter == nil {
+		return nil, source
+	}
+	return normalizeYAML(frontMatter).(map[string]interface{}), rest[next:]
+}
+
+// normalizeYAML converts mappings with non-string keys so front matter can be
+// encoded as JSON
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
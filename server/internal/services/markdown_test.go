@@ -0,0 +1,112 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func TestRenderMarkdown_Blocks(t *testing.T) {
+	source := "# Module 1: Basics\n\n" +
+		"Intro with `code`, **bold**, *emphasis* and a [link](https://nodejs.org \"Node\").\n" +
+		"Second line  \nafter a break.\n\n" +
+		"## Steps\n\n" +
+		"1. **Install**:\n   ```bash\n   npm install\n   ```\n" +
+		"2. Run it\n\n" +
+		"- one\n- two\n  - nested\n\n" +
+		"> Note\n\n---\n\n" +
+		"## Steps\n"
+
+	rendered := renderMarkdown(source)
+
+	want := `<h1 id="module-1-basics">Module 1: Basics</h1>
+<p>Intro with <code>code</code>, <strong>bold</strong>, <em>emphasis</em> and a <a href="https://nodejs.org" title="Node" rel="nofollow">link</a>.
+Second line<br>
+after a break.</p>
+<h2 id="steps">Steps</h2>
+<ol>
+<li><strong>Install</strong>:
+<pre><code class="language-bash">npm install
+</code></pre>
+</li>
+<li>Run it</li>
+</ol>
+<ul>
+<li>one</li>
+<li>two
+<ul>
+<li>nested</li>
+</ul>
+</li>
+</ul>
+<blockquote>
+<p>Note</p>
+</blockquote>
+<hr>
+<h2 id="steps-1">Steps</h2>
+`
+	if rendered.HTML != want {
+		t.Errorf("Unexpected HTML:\n%s\nwant:\n%s", rendered.HTML, want)
+	}
+
+	toc := []models.TOCEntry{
+		{Level: 1, Text: "Module 1: Basics", Anchor: "module-1-basics"},
+		{Level: 2, Text: "Steps", Anchor: "steps"},
+		{Level: 2, Text: "Steps", Anchor: "steps-1"},
+	}
+	if !reflect.DeepEqual(rendered.TOC, toc) {
+		t.Errorf("Expected TOC %+v, got %+v", toc, rendered.TOC)
+	}
+
+	codeBlocks := []models.CodeBlock{{Language: "bash", Code: "npm install\n"}}
+	if !reflect.DeepEqual(rendered.CodeBlocks, codeBlocks) {
+		t.Errorf("Expected code blocks %+v, got %+v", codeBlocks, rendered.CodeBlocks)
+	}
+}
+
+func TestRenderMarkdown_Sanitizes(t *testing.T) {
+	source := "<script>alert(1)</script>\n\n" +
+		"[click](javascript:void) ![logo](img/logo.png) <https://example.com> <img src=x onerror=alert(1)>\n"
+
+	want := `
+<p>click <img src="img/logo.png" alt="logo"> <a href="https://example.com" rel="nofollow">https://example.com</a> </p>
+`
+	if got := renderMarkdown(source).HTML; got != want {
+		t.Errorf("Unexpected HTML:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestRenderMarkdown_FrontMatter(t *testing.T) {
+	rendered := renderMarkdown("---\ntitle: Uploads\nminutes: 45\ntags: [multer, express]\n---\n# Uploads\n")
+
+	frontMatter := map[string]interface{}{
+		"title":   "Uploads",
+		"minutes": 45,
+		"tags":    []interface{}{"multer", "express"},
+	}
+	if !reflect.DeepEqual(rendered.FrontMatter, frontMatter) {
+		t.Errorf("Expected front matter %+v, got %+v", frontMatter, rendered.FrontMatter)
+	}
+	if rendered.HTML != "<h1 id=\"uploads\">Uploads</h1>\n" {
+		t.Errorf("Expected the front matter to be removed, got %q", rendered.HTML)
+	}
+
+	// A thematic break at the start isn't front matter
+	rendered = renderMarkdown("---\nJust text\n")
+	if rendered.FrontMatter != nil || rendered.HTML != "<hr>\n<p>Just text</p>\n" {
+		t.Errorf("Unexpected rendering: %+v", rendered)
+	}
+}
+
+func TestMarkdownRenderer_Caches(t *testing.T) {
+	renderer := NewMarkdownRenderer()
+
+	first := renderer.Render("# Title")
+	if second := renderer.Render("# Title"); first != second {
+		t.Error("Expected the cached result for unchanged content")
+	}
+	if other := renderer.Render("# Other"); other == first {
+		t.Error("Expected different content to be rendered again")
+	}
+}
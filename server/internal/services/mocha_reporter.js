@@ -0,0 +1,51 @@
+'use strict';
+
+// Mocha reporter that streams runner events as single-line JSON records.
+// Each record is prefixed so it can be told apart from anything the code
+// under test prints, and is written as soon as the event fires so a suite
+// that hangs still leaves a trail of the tests that finished.
+const PREFIX = '__B2L_EVENT__';
+
+function emit(record) {
+  process.stdout.write(PREFIX + JSON.stringify(record) + '\n');
+}
+
+function errorInfo(err) {
+  if (!err) {
+    return undefined;
+  }
+  return {
+    message: String(err.message || err),
+    code: err.code,
+  };
+}
+
+function StreamReporter(runner) {
+  runner.once('start', () => {
+    emit({ event: 'start', total: runner.total });
+  });
+
+  runner.on('test', (test) => {
+    emit({ event: 'begin', title: test.title, fullTitle: test.fullTitle() });
+  });
+
+  runner.on('pass', (test) => {
+    emit({ event: 'pass', title: test.title, fullTitle: test.fullTitle(), duration: test.duration });
+  });
+
+  runner.on('fail', (test, err) => {
+    emit({
+      event: 'fail',
+      title: test.title,
+      fullTitle: test.fullTitle(),
+      duration: test.duration,
+      err: errorInfo(err),
+    });
+  });
+
+  runner.once('end', () => {
+    emit({ event: 'end' });
+  });
+}
+
+module.exports = StreamReporter;
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// mochaStreamReporter is the Mocha reporter copied next to the code under test.
+//
+//go:embed mocha_reporter.js
+var mochaStreamReporter []byte
+
+const (
+	// mochaReporterFile is the file name the reporter is written to
+	mochaReporterFile = "tmp-reporter.js"
+	// mochaEventPrefix marks reporter output lines (must match mocha_reporter.js)
+	mochaEventPrefix = "__B2L_EVENT__"
+	// defaultPerTestTimeout bounds how long a single test may run before Mocha fails it
+	defaultPerTestTimeout = 5 * time.Second
+)
+
+var (
+	mochaExpectedPattern = regexp.MustCompile(`expected\s+(.+?)\s+to`)
+	mochaActualPattern   = regexp.MustCompile(`got\s+(.+?)$`)
+)
+
+// mochaEvent is a single record emitted by the stream reporter
+type mochaEvent struct {
+	Event     string `json:"event"`
+	Title     string `json:"title"`
+	FullTitle string `json:"fullTitle"`
+	Duration  int    `json:"duration"`
+	Total     int    `json:"total"`
+	Err       *struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	} `json:"err,omitempty"`
+}
+
+// mochaStreamCollector records reporter events as they are written so that a
+// suite interrupted by a timeout still reports the tests that finished.
+// It implements io.Writer and is safe for concurrent use.
+type mochaStreamCollector struct {
+	mu      sync.Mutex
+	pending []byte
+	raw     bytes.Buffer
+
+	started bool
+	ended   bool
+	total   int
+	current string
+	results []models.TestResult
+}
+
+func newMochaStreamCollector() *mochaStreamCollector {
+	return &mochaStreamCollector{}
+}
+
+// Write consumes process output, recording every complete reporter line
+func (c *mochaStreamCollector) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = append(c.pending, p...)
+	for {
+		idx := bytes.IndexByte(c.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		c.consumeLine(string(c.pending[:idx]))
+		c.pending = c.pending[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// Output returns everything written that was not a reporter event
+func (c *mochaStreamCollector) Output() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return strings.TrimSpace(c.raw.String() + string(c.pending))
+}
+
+// consumeLine handles a single line of output; the caller holds c.mu
+func (c *mochaStreamCollector) consumeLine(line string) {
+	idx := strings.Index(line, mochaEventPrefix)
+	if idx < 0 {
+		c.raw.WriteString(line)
+		c.raw.WriteByte('\n')
+		return
+	}
+
+	var event mochaEvent
+	if err := json.Unmarshal([]byte(line[idx+len(mochaEventPrefix):]), &event); err != nil {
+		c.raw.WriteString(line)
+		c.raw.WriteByte('\n')
+		return
+	}
+
+	switch event.Event {
+	case "start":
+		c.started = true
+		c.total = event.Total
+	case "begin":
+		c.started = true
+		c.current = event.Title
+	case "pass":
+		c.started = true
+		c.current = ""
+		c.results = append(c.results, models.TestResult{
+			TestName: event.Title,
+			Passed:   true,
+			Duration: event.Duration,
+		})
+	case "fail":
+		c.started = true
+		c.current = ""
+		result := models.TestResult{
+			TestName: event.Title,
+			Passed:   false,
+			Duration: event.Duration,
+		}
+		if event.Err != nil {
+			annotateFailure(&result, event.Err.Message)
+			result.TimedOut = event.Err.Code == "ERR_MOCHA_TIMEOUT" ||
+				strings.HasPrefix(event.Err.Message, "Timeout of ")
+		}
+		c.results = append(c.results, result)
+	case "end":
+		c.started = true
+		c.ended = true
+		c.current = ""
+	}
+}
+
+// SuiteResult builds a TestSuiteResult from the events seen so far.
+// It returns false when the reporter never produced any events, in which
+// case the caller should fall back to reporting the raw output.
+func (c *mochaStreamCollector) SuiteResult(moduleId, exerciseType string, elapsed time.Duration, timedOut bool) (*models.TestSuiteResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started {
+		return nil, false
+	}
+
+	results := append([]models.TestResult{}, c.results...)
+	suite := &models.TestSuiteResult{
+		ModuleID:      moduleId,
+		Status:        models.SuiteStatusCompleted,
+		ExecutionTime: elapsed.Milliseconds(),
+		ExerciseType:  exerciseType,
+	}
+
+	if !c.ended {
+		suite.Status = models.SuiteStatusError
+		if timedOut {
+			suite.Status = models.SuiteStatusTimedOut
+		}
+
+		if c.current != "" {
+			stoppedAt := c.current
+			suite.StoppedAt = &stoppedAt
+
+			message := "Execution stopped while this test was running"
+			if timedOut {
+				message = "Test did not finish before the suite timed out"
+			}
+			results = append(results, models.TestResult{
+				TestName: stoppedAt,
+				Passed:   false,
+				TimedOut: timedOut,
+				Error:    &message,
+			})
+		}
+	}
+
+	for _, result := range results {
+		if result.Passed {
+			suite.PassedTests++
+			continue
+		}
+		suite.FailedTests++
+		if result.TimedOut {
+			suite.TimedOutTests++
+		}
+	}
+
+	suite.Results = results
+	suite.TotalTests = len(results)
+	if c.total > len(results) {
+		suite.NotRunTests = c.total - len(results)
+		suite.TotalTests = c.total
+	}
+
+	return suite, true
+}
+
+// annotateFailure attaches the error message to a failed result and tries to
+// extract expected/actual values from it
+func annotateFailure(result *models.TestResult, errorMsg string) {
+	result.Error = &errorMsg
+
+	if match := mochaExpectedPattern.FindStringSubmatch(errorMsg); len(match) > 1 {
+		result.Expected = match[1]
+	}
+	if match := mochaActualPattern.FindStringSubmatch(errorMsg); len(match) > 1 {
+		result.Actual = match[1]
+	}
+}
+
+// mochaTimeoutArg formats a per-test timeout for Mocha's --timeout flag
+func mochaTimeoutArg(timeout time.Duration) string {
+	return fmt.Sprintf("%d", timeout.Milliseconds())
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func reporterLine(record string) string {
+	return mochaEventPrefix + record + "\n"
+}
+
+func TestMochaStreamCollector_CompletedSuite(t *testing.T) {
+	collector := newMochaStreamCollector()
+
+	fmt.Fprint(collector, "Server listening on 3000\n")
+	fmt.Fprint(collector, reporterLine(`{"event":"start","total":2}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"begin","title":"returns 200"}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"pass","title":"returns 200","duration":12}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"begin","title":"returns 404"}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"fail","title":"returns 404","err":{"message":"expected 200 to equal 404"}}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"end"}`))
+
+	suite, ok := collector.SuiteResult("module-2", "server", time.Second, false)
+	if !ok {
+		t.Fatal("Expected reporter events to be recognised")
+	}
+
+	if suite.Status != models.SuiteStatusCompleted {
+		t.Errorf("Expected status %q, got %q", models.SuiteStatusCompleted, suite.Status)
+	}
+	if suite.TotalTests != 2 || suite.PassedTests != 1 || suite.FailedTests != 1 {
+		t.Errorf("Unexpected counts: total=%d passed=%d failed=%d", suite.TotalTests, suite.PassedTests, suite.FailedTests)
+	}
+	if suite.StoppedAt != nil {
+		t.Errorf("Expected no stop point for a completed suite, got %q", *suite.StoppedAt)
+	}
+	if suite.Results[1].Expected != "200" {
+		t.Errorf("Expected extracted expected value '200', got %v", suite.Results[1].Expected)
+	}
+	if collector.Output() != "Server listening on 3000" {
+		t.Errorf("Expected non-event output to be kept, got %q", collector.Output())
+	}
+}
+
+func TestMochaStreamCollector_PartialResultsOnTimeout(t *testing.T) {
+	collector := newMochaStreamCollector()
+
+	// Events may arrive split across writes
+	line := reporterLine(`{"event":"start","total":4}`)
+	fmt.Fprint(collector, line[:10])
+	fmt.Fprint(collector, line[10:])
+	fmt.Fprint(collector, reporterLine(`{"event":"begin","title":"first"}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"pass","title":"first","duration":3}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"begin","title":"slow route"}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"fail","title":"slow route","err":{"message":"Timeout of 5000ms exceeded.","code":"ERR_MOCHA_TIMEOUT"}}`))
+	fmt.Fprint(collector, reporterLine(`{"event":"begin","title":"hanging hook"}`))
+
+	suite, ok := collector.SuiteResult("module-3", "server", 30*time.Second, true)
+	if !ok {
+		t.Fatal("Expected reporter events to be recognised")
+	}
+
+	if suite.Status != models.SuiteStatusTimedOut {
+		t.Errorf("Expected status %q, got %q", models.SuiteStatusTimedOut, suite.Status)
+	}
+	if suite.StoppedAt == nil || *suite.StoppedAt != "hanging hook" {
+		t.Fatalf("Expected execution to stop at 'hanging hook', got %v", suite.StoppedAt)
+	}
+	if suite.PassedTests != 1 {
+		t.Errorf("Expected 1 passed test, got %d", suite.PassedTests)
+	}
+	if suite.TimedOutTests != 2 {
+		t.Errorf("Expected 2 timed out tests, got %d", suite.TimedOutTests)
+	}
+	if suite.NotRunTests != 1 || suite.TotalTests != 4 {
+		t.Errorf("Expected 1 of 4 tests not run, got notRun=%d total=%d", suite.NotRunTests, suite.TotalTests)
+	}
+}
+
+func TestMochaStreamCollector_NoEvents(t *testing.T) {
+	collector := newMochaStreamCollector()
+	fmt.Fprint(collector, "Error: Cannot find module 'chai'\n")
+
+	if _, ok := collector.SuiteResult("module-1", "function", time.Second, false); ok {
+		t.Error("Expected no suite result without reporter events")
+	}
+	if collector.Output() != "Error: Cannot find module 'chai'" {
+		t.Errorf("Unexpected raw output %q", collector.Output())
+	}
+}
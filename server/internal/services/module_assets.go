@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// assetsDir is the module subdirectory served by the assets endpoint
+const assetsDir = "assets"
+
+// maxAssetSize limits the size of a single asset, both served and authored
+const maxAssetSize = 5 * 1024 * 1024
+
+// ErrAssetTooLarge is returned for assets over the size limit
+var ErrAssetTooLarge = errors.New("asset too large")
+
+// assetContentTypes maps the asset file extensions to their content types.
+// Other files are served as application/octet-stream.
+var assetContentTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+	".ico":  "image/x-icon",
+	".pdf":  "application/pdf",
+	".zip":  "application/zip",
+	".json": "application/json",
+	".txt":  "text/plain; charset=utf-8",
+	".csv":  "text/csv; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+}
+
+// markdownLinkPattern matches the destination of inline markdown links and
+// images: [text](destination) and ![alt](<destination>)
+var markdownLinkPattern = regexp.MustCompile(`(\]\(<?)([^)\s>]+)`)
+
+// assetContentType returns the content type an asset is served with
+func assetContentType(name string) string {
+	if contentType, ok := assetContentTypes[strings.ToLower(path.Ext(name))]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// assetURL returns the URL an asset of a module is served at
+func assetURL(moduleId, rel string) string {
+	segments := strings.Split(rel, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return "/api/modules/" + moduleId + "/assets/" + strings.Join(segments, "/")
+}
+
+// rewriteAssetLinks points relative links and images in a markdown document
+// that resolve into the module's assets folder at the assets endpoint.
+// docPath is the document's path in the module, so "../assets/diagram.png"
+// works from lab/README.md. Links inside fenced code are left alone.
+func rewriteAssetLinks(moduleId, docPath, markdown string) string {
+	if !strings.Contains(markdown, assetsDir+"/") {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[i] = markdownLinkPattern.ReplaceAllStringFunc(line, func(match string) string {
+			parts := markdownLinkPattern.FindStringSubmatch(match)
+			return parts[1] + resolveAssetLink(moduleId, docPath, parts[2])
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolveAssetLink returns the assets endpoint URL for a link destination
+// that resolves to a file in the assets folder, and the destination unchanged
+// otherwise
+func resolveAssetLink(moduleId, docPath, dest string) string {
+	target, suffix := dest, ""
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		target, suffix = dest[:i], dest[i:]
+	}
+	if target == "" || strings.HasPrefix(target, "/") || strings.Contains(target, ":") {
+		return dest
+	}
+
+	resolved := path.Join(path.Dir(docPath), target)
+	rel, ok := strings.CutPrefix(resolved, assetsDir+"/")
+	if !ok || !fs.ValidPath(resolved) {
+		return dest
+	}
+	return assetURL(moduleId, rel) + suffix
+}
+
+// GetModuleAsset reads a file from a module's assets folder. Hidden files
+// are not served.
+func (s *ModuleService) GetModuleAsset(moduleId, rel string) (*models.ModuleAsset, error) {
+	cleaned, ok := moduleRelativePath(rel)
+	if !ok || strings.HasPrefix(path.Base(cleaned), ".") {
+		return nil, fmt.Errorf("%w: %s/%s/%s", fs.ErrNotExist, moduleId, assetsDir, rel)
+	}
+
+	var (
+		info fs.FileInfo
+		data []byte
+		err  error
+	)
+	if dir, onDisk := s.source.Dir(); onDisk {
+		info, data, err = readDiskAsset(filepath.Join(dir, moduleId, assetsDir), cleaned)
+	} else {
+		info, data, err = readFSAsset(s.source.FS(), path.Join(moduleId, assetsDir, cleaned))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ModuleAsset{
+		Path:        cleaned,
+		ContentType: assetContentType(cleaned),
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		Data:        data,
+	}, nil
+}
+
+// readDiskAsset reads an asset below the assets directory on disk
+func readDiskAsset(dir, rel string) (fs.FileInfo, []byte, error) {
+	assetPath, err := safeJoin(dir, filepath.FromSlash(rel))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", fs.ErrNotExist, err)
+	}
+	info, err := os.Stat(assetPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkAsset(info, rel); err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(assetPath)
+	return info, data, err
+}
+
+// readFSAsset reads an asset from an embedded filesystem
+func readFSAsset(fsys fs.FS, name string) (fs.FileInfo, []byte, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkAsset(info, name); err != nil {
+		return nil, nil, err
+	}
+	data, err := fs.ReadFile(fsys, name)
+	return info, data, err
+}
+
+// checkAsset rejects directories and files over the asset size limit
+func checkAsset(info fs.FileInfo, name string) error {
+	if info.IsDir() {
+		return fmt.Errorf("%w: %s is a directory", fs.ErrNotExist, name)
+	}
+	if info.Size() > maxAssetSize {
+		return fmt.Errorf("%w: %s is %d bytes, the limit is %d", ErrAssetTooLarge, name, info.Size(), maxAssetSize)
+	}
+	return nil
+}
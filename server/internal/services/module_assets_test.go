@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRewriteAssetLinks(t *testing.T) {
+	markdown := "![Diagram](../assets/flow.png)\n" +
+		"[Slides](<../assets/slides.pdf#page=2>) [Docs](https://expressjs.com) [Next](../exercise/README.md)\n" +
+		"```md\n![Not rewritten](../assets/code.png)\n```\n" +
+		"[Outside](../../module-2/assets/x.png)"
+
+	got := rewriteAssetLinks("module-10", "lab/README.md", markdown)
+
+	want := "![Diagram](/api/modules/module-10/assets/flow.png)\n" +
+		"[Slides](</api/modules/module-10/assets/slides.pdf#page=2>) [Docs](https://expressjs.com) [Next](../exercise/README.md)\n" +
+		"```md\n![Not rewritten](../assets/code.png)\n```\n" +
+		"[Outside](../../module-2/assets/x.png)"
+	if got != want {
+		t.Errorf("Unexpected rewrite:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := resolveAssetLink("module-1", "README.md", "assets/img/a b.png"); got != "/api/modules/module-1/assets/img/a%20b.png" {
+		t.Errorf("Expected an escaped asset URL, got %q", got)
+	}
+}
+
+func TestModuleService_GetModuleAsset(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	assetsPath := filepath.Join(modulesDir, "module-1", "assets")
+	os.MkdirAll(filepath.Join(assetsPath, "img"), 0755)
+	os.WriteFile(filepath.Join(assetsPath, "img", "diagram.PNG"), []byte("png"), 0644)
+	os.WriteFile(filepath.Join(assetsPath, "notes.bin"), []byte("bin"), 0644)
+	os.WriteFile(filepath.Join(assetsPath, ".secret"), []byte("hidden"), 0644)
+	os.WriteFile(filepath.Join(assetsPath, "huge.zip"), make([]byte, maxAssetSize+1), 0644)
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "module.json"), []byte(`{"id": "module-1"}`), 0644)
+
+	service := NewModuleServiceWithPath(modulesDir)
+
+	asset, err := service.GetModuleAsset("module-1", "img/diagram.PNG")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if asset.ContentType != "image/png" || string(asset.Data) != "png" || asset.Size != 3 {
+		t.Errorf("Unexpected asset: %+v", asset)
+	}
+
+	if asset, _ := service.GetModuleAsset("module-1", "notes.bin"); asset == nil || asset.ContentType != "application/octet-stream" {
+		t.Errorf("Expected unknown types to be served as binary, got %+v", asset)
+	}
+
+	for _, rel := range []string{"../module.json", "img/../../module.json", ".secret", "img", "missing.png"} {
+		if _, err := service.GetModuleAsset("module-1", rel); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Expected %q not to be served, got %v", rel, err)
+		}
+	}
+
+	if _, err := service.GetModuleAsset("module-1", "huge.zip"); !errors.Is(err, ErrAssetTooLarge) {
+		t.Errorf("Expected the size limit to apply, got %v", err)
+	}
+}
+
+func TestModuleService_GetModuleAssetEmbedded(t *testing.T) {
+	service := NewModuleServiceWithSource(NewFSContentSource(fstest.MapFS{
+		"module-1/assets/logo.svg": {Data: []byte("<svg/>")},
+	}))
+
+	asset, err := service.GetModuleAsset("module-1", "logo.svg")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if asset.ContentType != "image/svg+xml" {
+		t.Errorf("Expected an SVG, got %q", asset.ContentType)
+	}
+}
+
+func TestModuleService_RewritesAssetLinksInContent(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	os.WriteFile(filepath.Join(modulesDir, "module-3", "lab", "README.md"), []byte("![Flow](../assets/flow.png)"), 0644)
+	os.WriteFile(filepath.Join(modulesDir, "module-3", "exercises", "hashing", "LAB.md"), []byte("![Hash](../../assets/hash.png)"), 0644)
+
+	service := NewModuleServiceWithPath(modulesDir)
+
+	content, err := service.GetModuleContent("module-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if content.LabContent != "![Flow](/api/modules/module-3/assets/flow.png)" {
+		t.Errorf("Expected the lab link to be rewritten, got %q", content.LabContent)
+	}
+
+	step, _ := service.GetModuleExercise("module-3", "hashing")
+	if !strings.Contains(step.LabContent, "/api/modules/module-3/assets/hash.png") {
+		t.Errorf("Expected the exercise lab link to be rewritten, got %q", step.LabContent)
+	}
+}
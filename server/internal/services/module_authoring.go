@@ -0,0 +1,392 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxAuthoredFileSize limits files written through the authoring API
+const maxAuthoredFileSize = 1024 * 1024
+
+// ErrInvalidModule is returned when an authoring change is rejected
+var ErrInvalidModule = errors.New("invalid module change")
+
+// authoredDirs are the module subdirectories authors can write files to
+var authoredDirs = []string{"lab", "exercise", "exercises", assetsDir}
+
+// defaultModuleFiles is the layout created by scripts/create-module.js
+func defaultModuleFiles() models.ModuleFiles {
+	file := func(p string) *string { return &p }
+	return models.ModuleFiles{
+		Lab: models.ModuleFile{Readme: file("lab/README.md")},
+		Exercise: models.ModuleFile{
+			Readme:   file("exercise/README.md"),
+			Server:   file("exercise/server.js"),
+			Test:     file("exercise/test.js"),
+			Solution: file("exercise/solution.js"),
+			Package:  file("exercise/package.json"),
+		},
+	}
+}
+
+// CreateModule creates a new draft module. Without an ID the next free
+// module number is used, and without files the standard layout is referenced
+// so the files can be written afterwards.
+func (s *ModuleService) CreateModule(module models.Module) (*models.AuthoringResult, error) {
+	modulesDir, ok := s.source.Dir()
+	if !ok {
+		return nil, ErrContentReadOnly
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	known, err := s.knownModuleIDs(modulesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if module.ID == "" {
+		module.ID = nextModuleID(known)
+	} else if !moduleIDPattern.MatchString(module.ID) {
+		return nil, fmt.Errorf("%w: invalid module ID %q", ErrInvalidModule, module.ID)
+	} else if known[module.ID] {
+		return nil, fmt.Errorf("%w: %s", ErrModuleExists, module.ID)
+	}
+
+	module.Status = models.ModuleStatusDraft
+	if module.Files == (models.ModuleFiles{}) {
+		module.Files = defaultModuleFiles()
+	}
+
+	moduleDir := filepath.Join(modulesDir, module.ID)
+	for _, dir := range authoredDirs {
+		if err := os.MkdirAll(filepath.Join(moduleDir, dir), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create module directory: %w", err)
+		}
+	}
+
+	report, err := s.commitModuleChange(modulesDir, module.ID, func() (func(), error) {
+		return func() { os.RemoveAll(moduleDir) }, writeModuleJSON(moduleDir, module)
+	})
+	return &models.AuthoringResult{ModuleID: module.ID, Module: &module, Validation: report}, err
+}
+
+// UpdateModule applies the fields in patch (a partial module.json) to a
+// module. The ID cannot be changed.
+func (s *ModuleService) UpdateModule(moduleId string, patch []byte) (*models.AuthoringResult, error) {
+	modulesDir, ok := s.source.Dir()
+	if !ok {
+		return nil, ErrContentReadOnly
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	moduleDir, err := existingModuleDir(modulesDir, moduleId)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := os.ReadFile(filepath.Join(moduleDir, "module.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module.json: %w", err)
+	}
+
+	var module models.Module
+	if err := json.Unmarshal(original, &module); err != nil {
+		return nil, fmt.Errorf("%w: module.json is not valid JSON: %v", ErrInvalidModule, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&module); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+	if module.ID != moduleId {
+		return nil, fmt.Errorf("%w: the module ID cannot be changed", ErrInvalidModule)
+	}
+
+	report, err := s.commitModuleChange(modulesDir, moduleId, func() (func(), error) {
+		restore := func() { writeFileAtomic(filepath.Join(moduleDir, "module.json"), original) }
+		return restore, writeModuleJSON(moduleDir, module)
+	})
+	return &models.AuthoringResult{ModuleID: moduleId, Module: &module, Validation: report}, err
+}
+
+// SetModuleStatus publishes or unpublishes a module. Publishing requires the
+// module to pass validation.
+func (s *ModuleService) SetModuleStatus(moduleId, status string) (*models.AuthoringResult, error) {
+	patch, err := json.Marshal(map[string]string{"status": status})
+	if err != nil {
+		return nil, err
+	}
+	return s.UpdateModule(moduleId, patch)
+}
+
+// DeleteModule removes a module and all of its files
+func (s *ModuleService) DeleteModule(moduleId string) error {
+	modulesDir, ok := s.source.Dir()
+	if !ok {
+		return ErrContentReadOnly
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	moduleDir, err := existingModuleDir(modulesDir, moduleId)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(moduleDir); err != nil {
+		return fmt.Errorf("failed to delete module %s: %w", moduleId, err)
+	}
+
+	_, err = s.Reload()
+	return err
+}
+
+// ReadModuleFile returns a file from a module, including drafts
+func (s *ModuleService) ReadModuleFile(moduleId, rel string) ([]byte, error) {
+	if !moduleIDPattern.MatchString(moduleId) {
+		return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, moduleId)
+	}
+	if _, ok := moduleRelativePath(rel); !ok {
+		return nil, fmt.Errorf("%w: invalid path %q", ErrInvalidModule, rel)
+	}
+	data, err := s.source.readModuleFile(moduleId, rel)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s/%s", fs.ErrNotExist, moduleId, rel)
+	}
+	return data, err
+}
+
+// WriteModuleFile creates or replaces a lab or exercise file
+func (s *ModuleService) WriteModuleFile(moduleId, rel string, data []byte) (*models.AuthoringResult, error) {
+	modulesDir, ok := s.source.Dir()
+	if !ok {
+		return nil, ErrContentReadOnly
+	}
+
+	rel, err := authoredPath(rel)
+	if err != nil {
+		return nil, err
+	}
+	limit := maxAuthoredFileSize
+	if strings.HasPrefix(rel, assetsDir+"/") {
+		limit = maxAssetSize
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("%w: %s exceeds the %d byte file limit", ErrInvalidModule, rel, limit)
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	moduleDir, err := existingModuleDir(modulesDir, moduleId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := safeJoin(moduleDir, filepath.FromSlash(rel))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+
+	report, err := s.commitModuleChange(modulesDir, moduleId, func() (func(), error) {
+		restore := restoreFileFunc(target)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return restore, err
+		}
+		return restore, writeFileAtomic(target, data)
+	})
+	return &models.AuthoringResult{ModuleID: moduleId, Path: rel, Size: int64(len(data)), Validation: report}, err
+}
+
+// DeleteModuleFile removes a lab or exercise file
+func (s *ModuleService) DeleteModuleFile(moduleId, rel string) (*models.AuthoringResult, error) {
+	modulesDir, ok := s.source.Dir()
+	if !ok {
+		return nil, ErrContentReadOnly
+	}
+
+	rel, err := authoredPath(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	moduleDir, err := existingModuleDir(modulesDir, moduleId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := safeJoin(moduleDir, filepath.FromSlash(rel))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModule, err)
+	}
+	if info, err := os.Stat(target); err != nil || info.IsDir() {
+		return nil, fmt.Errorf("%w: %s/%s", fs.ErrNotExist, moduleId, rel)
+	}
+
+	report, err := s.commitModuleChange(modulesDir, moduleId, func() (func(), error) {
+		return restoreFileFunc(target), os.Remove(target)
+	})
+	return &models.AuthoringResult{ModuleID: moduleId, Path: rel, Validation: report}, err
+}
+
+// commitModuleChange applies a change to a module on disk and validates the
+// result. Drafts may reference files that don't exist yet or unresolved
+// prerequisites; any other error, or any error at all in a published module,
+// rolls the change back. The catalog is reloaded once the change is kept.
+func (s *ModuleService) commitModuleChange(modulesDir, moduleId string, apply func() (restore func(), err error)) (*models.ValidationReport, error) {
+	restore, err := apply()
+	if err != nil {
+		restore()
+		return nil, fmt.Errorf("failed to update module %s: %w", moduleId, err)
+	}
+
+	known, err := s.knownModuleIDs(modulesDir)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+
+	validator, err := NewModuleValidator()
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	report := validator.ValidateModule(os.DirFS(modulesDir), moduleId, known)
+
+	if blocksChange(report, isDraftModule(modulesDir, moduleId)) {
+		restore()
+		return report, fmt.Errorf("%w: module %s failed validation", ErrInvalidModule, moduleId)
+	}
+
+	// Changes the catalog can't load are undone, as are changes putting the
+	// module on a prerequisite cycle, which the catalog only tolerates so
+	// content edited on disk can't take the server down
+	if _, err := s.Reload(); err != nil {
+		restore()
+		return report, err
+	}
+	if cycle := s.prerequisiteCycle(moduleId); cycle != nil {
+		restore()
+		if _, err := s.Reload(); err != nil {
+			logrus.Errorf("Failed to reload modules after undoing a change to %s: %v", moduleId, err)
+		}
+		return report, fmt.Errorf("%w: %w: %s", ErrInvalidModule, ErrPrerequisiteCycle, strings.Join(cycle, " -> "))
+	}
+	return report, nil
+}
+
+// prerequisiteCycle returns the prerequisite cycle a module is on in the
+// loaded catalog, if any
+func (s *ModuleService) prerequisiteCycle(moduleId string) []string {
+	path, err := s.currentLearningPath()
+	if err != nil {
+		return nil
+	}
+	return path.cycleWith(moduleId)
+}
+
+// blocksChange reports whether validation issues prevent a change from being kept
+func blocksChange(report *models.ValidationReport, draft bool) bool {
+	for _, issue := range report.Issues {
+		if issue.Severity != models.SeverityError {
+			continue
+		}
+		if draft && (issue.Code == models.IssueMissingFile || issue.Code == models.IssueUnresolvedPrerequisite) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// isDraftModule reports whether the module.json on disk marks the module as a draft
+func isDraftModule(modulesDir, moduleId string) bool {
+	data, err := os.ReadFile(filepath.Join(modulesDir, moduleId, "module.json"))
+	if err != nil {
+		return false
+	}
+	var module models.Module
+	if err := json.Unmarshal(data, &module); err != nil {
+		return false
+	}
+	return module.IsDraft()
+}
+
+// existingModuleDir returns the directory of a module that exists on disk
+func existingModuleDir(modulesDir, moduleId string) (string, error) {
+	if !moduleIDPattern.MatchString(moduleId) {
+		return "", fmt.Errorf("%w: %s", ErrModuleNotFound, moduleId)
+	}
+	moduleDir := filepath.Join(modulesDir, moduleId)
+	if info, err := os.Stat(moduleDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%w: %s", ErrModuleNotFound, moduleId)
+	}
+	return moduleDir, nil
+}
+
+// authoredPath cleans a module-relative path and checks that it points into
+// one of the directories authors can write to
+func authoredPath(rel string) (string, error) {
+	cleaned, ok := moduleRelativePath(rel)
+	if ok {
+		top, _, nested := strings.Cut(cleaned, "/")
+		for _, dir := range authoredDirs {
+			if nested && top == dir && !isTransientModuleFile(cleaned) {
+				return cleaned, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: files must be written below %s/, got %q",
+		ErrInvalidModule, strings.Join(authoredDirs, "/, "), rel)
+}
+
+// restoreFileFunc captures a file's current contents and returns a function
+// that puts them back, removing the file if it didn't exist
+func restoreFileFunc(target string) func() {
+	original, err := os.ReadFile(target)
+	if err != nil {
+		return func() { os.Remove(target) }
+	}
+	return func() { writeFileAtomic(target, original) }
+}
+
+// writeFileAtomic replaces a file by renaming a temporary copy over it, so
+// readers never see a partially written file
+func writeFileAtomic(target string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".authoring-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
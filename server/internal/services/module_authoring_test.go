@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func TestModuleService_CreateAndPublishModule(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+	service := NewModuleServiceWithPath(modulesDir)
+
+	result, err := service.CreateModule(models.Module{
+		Title:         "New Module",
+		Description:   "Written through the authoring API",
+		Difficulty:    "Beginner",
+		EstimatedTime: "20 minutes",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ModuleID != "module-2" || !result.Module.IsDraft() {
+		t.Fatalf("Expected draft module-2, got %+v", result.Module)
+	}
+
+	// The referenced files don't exist yet, which drafts allow but publishing doesn't
+	if _, err := service.SetModuleStatus("module-2", models.ModuleStatusPublished); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("Expected publishing an incomplete module to fail, got %v", err)
+	}
+
+	for _, file := range []string{"lab/README.md", "exercise/README.md", "exercise/server.js", "exercise/test.js", "exercise/solution.js", "exercise/package.json"} {
+		if _, err := service.WriteModuleFile("module-2", file, []byte("// "+file)); err != nil {
+			t.Fatalf("Expected no error writing %s, got %v", file, err)
+		}
+	}
+
+	result, err = service.SetModuleStatus("module-2", models.ModuleStatusPublished)
+	if err != nil {
+		t.Fatalf("Expected no error publishing, got %v", err)
+	}
+	if result.Module.IsDraft() || !result.Validation.OK {
+		t.Errorf("Expected a valid published module, got %+v", result)
+	}
+
+	content, err := service.GetModuleContent("module-2")
+	if err != nil {
+		t.Fatalf("Expected module to be loaded, got %v", err)
+	}
+	if content.ExerciseContent.EditorFiles.Server != "// exercise/server.js" {
+		t.Errorf("Expected written server code, got %q", content.ExerciseContent.EditorFiles.Server)
+	}
+
+	// Published modules can't lose referenced files
+	if _, err := service.DeleteModuleFile("module-2", "exercise/test.js"); !errors.Is(err, ErrInvalidModule) {
+		t.Errorf("Expected deleting a referenced file to fail, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(modulesDir, "module-2", "exercise", "test.js")); err != nil {
+		t.Errorf("Expected test.js to be restored, got %v", err)
+	}
+}
+
+func TestModuleService_UpdateModule(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+	service := NewModuleServiceWithPath(modulesDir)
+
+	result, err := service.UpdateModule("module-1", []byte(`{"title": "Renamed", "tags": ["a", "b"]}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Module.Title != "Renamed" || result.Module.Description != "A test module" {
+		t.Errorf("Expected only the given fields to change, got %+v", result.Module)
+	}
+
+	module, _ := service.GetModuleById("module-1")
+	if module.Title != "Renamed" {
+		t.Errorf("Expected catalog to be reloaded, got %q", module.Title)
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"invalid difficulty", `{"difficulty": "Expert"}`},
+		{"unknown field", `{"owner": "someone"}`},
+		{"changed ID", `{"id": "module-7"}`},
+	}
+	for _, tt := range tests {
+		if _, err := service.UpdateModule("module-1", []byte(tt.patch)); !errors.Is(err, ErrInvalidModule) {
+			t.Errorf("%s: expected ErrInvalidModule, got %v", tt.name, err)
+		}
+	}
+
+	module, _ = service.GetModuleById("module-1")
+	if module.Difficulty != "Beginner" {
+		t.Errorf("Expected rejected changes to be rolled back, got %q", module.Difficulty)
+	}
+}
+
+func TestModuleService_UpdateModuleRejectsPrerequisiteCycle(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+	writeBundleTestModule(t, modulesDir, "module-2", "// starter")
+	service := NewModuleServiceWithPath(modulesDir)
+	if _, err := service.UpdateModule("module-2", []byte(`{"prerequisites": ["module-1"]}`)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err := service.UpdateModule("module-1", []byte(`{"prerequisites": ["module-2"]}`))
+	if !errors.Is(err, ErrInvalidModule) || !errors.Is(err, ErrPrerequisiteCycle) {
+		t.Fatalf("Expected a prerequisite cycle to be rejected, got %v", err)
+	}
+
+	module, _ := service.GetModuleById("module-1")
+	if slices.Contains(module.Prerequisites, "module-2") {
+		t.Errorf("Expected the change to be rolled back, got %v", module.Prerequisites)
+	}
+	if path, _ := service.GetLearningPath(); len(path.Cycles) != 0 {
+		t.Errorf("Expected the catalog without the cycle, got %v", path.Cycles)
+	}
+}
+
+func TestModuleService_WriteModuleFileRejectsPaths(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+	service := NewModuleServiceWithPath(modulesDir)
+
+	for _, path := range []string{"../module-2/lab/README.md", "module.json", "lab", "/etc/passwd", "exercise/../../x.js", "exercise/tmp-server.js"} {
+		if _, err := service.WriteModuleFile("module-1", path, []byte("x")); !errors.Is(err, ErrInvalidModule) {
+			t.Errorf("Expected %q to be rejected, got %v", path, err)
+		}
+	}
+
+	if _, err := service.WriteModuleFile("module-9", "lab/README.md", []byte("x")); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("Expected ErrModuleNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+const (
+	// bundleManifestName is the manifest's name inside a bundle
+	bundleManifestName = "manifest.json"
+	// bundleModuleDir prefixes module files inside a bundle
+	bundleModuleDir = "module/"
+	// importStagingPrefix names the directories imports are staged in
+	importStagingPrefix = ".import-"
+
+	maxBundleFileSize  = 10 * 1024 * 1024
+	maxBundleTotalSize = 50 * 1024 * 1024
+	maxBundleFiles     = 500
+)
+
+var (
+	// ErrInvalidBundle is returned when a bundle is malformed or its module fails validation
+	ErrInvalidBundle = errors.New("invalid module bundle")
+	// ErrModuleExists is returned when an imported module ID is already taken
+	ErrModuleExists = errors.New("module already exists")
+	// ErrContentReadOnly is returned when writing to content that isn't on disk
+	ErrContentReadOnly = errors.New("module content is read-only")
+)
+
+// ExportModule writes a module as a bundle: a gzipped tar archive holding
+// manifest.json and the module directory under module/
+func (s *ModuleService) ExportModule(moduleId string, w io.Writer) error {
+	module, err := s.GetModuleById(moduleId)
+	if err != nil {
+		return err
+	}
+
+	fsys := s.source.FS()
+	manifest := models.BundleManifest{
+		FormatVersion: models.BundleFormatVersion,
+		ModuleID:      module.ID,
+		Title:         module.Title,
+		ExportedAt:    time.Now().UTC(),
+		Files:         []models.BundleFile{},
+	}
+	contents := make(map[string][]byte)
+
+	err = walkModuleFiles(fsys, moduleId, func(rel string, data []byte) error {
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, models.BundleFile{
+			Path:   rel,
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+		contents[rel] = data
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read module %s: %w", moduleId, err)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	writeEntry := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Size:    int64(len(data)),
+			Mode:    0644,
+			ModTime: manifest.ExportedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeEntry(bundleManifestName, manifestData); err != nil {
+		return err
+	}
+	for _, file := range manifest.Files {
+		if err := writeEntry(bundleModuleDir+file.Path, contents[file.Path]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// readBundle reads and verifies a bundle, returning its manifest and the
+// module files keyed by path relative to the module directory
+func readBundle(r io.Reader) (*models.BundleManifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: not a gzip archive: %v", ErrInvalidBundle, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var manifestData []byte
+	files := make(map[string][]byte)
+	var total int64
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, nil, fmt.Errorf("%w: %s is not a regular file", ErrInvalidBundle, header.Name)
+		}
+		if header.Size > maxBundleFileSize {
+			return nil, nil, fmt.Errorf("%w: %s exceeds the %d byte file limit", ErrInvalidBundle, header.Name, maxBundleFileSize)
+		}
+		total += header.Size
+		if total > maxBundleTotalSize || len(files) >= maxBundleFiles {
+			return nil, nil, fmt.Errorf("%w: bundle is too large", ErrInvalidBundle)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleFileSize+1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+
+		if header.Name == bundleManifestName {
+			manifestData = data
+			continue
+		}
+
+		rel, ok := moduleRelativePath(strings.TrimPrefix(header.Name, bundleModuleDir))
+		if !strings.HasPrefix(header.Name, bundleModuleDir) || !ok {
+			return nil, nil, fmt.Errorf("%w: unexpected entry %s", ErrInvalidBundle, header.Name)
+		}
+		if _, dup := files[rel]; dup {
+			return nil, nil, fmt.Errorf("%w: duplicate entry %s", ErrInvalidBundle, header.Name)
+		}
+		files[rel] = data
+	}
+
+	if manifestData == nil {
+		return nil, nil, fmt.Errorf("%w: missing %s", ErrInvalidBundle, bundleManifestName)
+	}
+
+	var manifest models.BundleManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid manifest: %v", ErrInvalidBundle, err)
+	}
+	if manifest.FormatVersion != models.BundleFormatVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidBundle, manifest.FormatVersion)
+	}
+
+	// Every file must be listed with a matching checksum, and nothing else may be present
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, file := range manifest.Files {
+		data, ok := files[file.Path]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %s is listed in the manifest but missing", ErrInvalidBundle, file.Path)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != file.SHA256 || int64(len(data)) != file.Size {
+			return nil, nil, fmt.Errorf("%w: checksum mismatch for %s", ErrInvalidBundle, file.Path)
+		}
+		listed[file.Path] = true
+	}
+	for rel := range files {
+		if !listed[rel] {
+			return nil, nil, fmt.Errorf("%w: %s is not listed in the manifest", ErrInvalidBundle, rel)
+		}
+	}
+	if _, ok := files["module.json"]; !ok {
+		return nil, nil, fmt.Errorf("%w: missing module.json", ErrInvalidBundle)
+	}
+
+	return &manifest, files, nil
+}
+
+// ImportModule installs a module from a bundle into the modules directory.
+// The module is validated before anything is installed; when validation
+// fails the result carries the report along with ErrInvalidBundle.
+func (s *ModuleService) ImportModule(r io.Reader, opts models.ImportOptions) (*models.ImportResult, error) {
+	modulesDir, ok := s.source.Dir()
+	if !ok {
+		return nil, ErrContentReadOnly
+	}
+
+	_, files, err := readBundle(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var module models.Module
+	if err := json.Unmarshal(files["module.json"], &module); err != nil {
+		return nil, fmt.Errorf("%w: invalid module.json: %v", ErrInvalidBundle, err)
+	}
+	if !moduleIDPattern.MatchString(module.ID) {
+		return nil, fmt.Errorf("%w: invalid module ID %q", ErrInvalidBundle, module.ID)
+	}
+
+	targetID := module.ID
+	if opts.TargetID != "" {
+		if !moduleIDPattern.MatchString(opts.TargetID) {
+			return nil, fmt.Errorf("%w: invalid target module ID %q", ErrInvalidBundle, opts.TargetID)
+		}
+		targetID = opts.TargetID
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	known, err := s.knownModuleIDs(modulesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{
+		ModuleID:   targetID,
+		OriginalID: module.ID,
+		Files:      len(files),
+	}
+
+	if known[targetID] {
+		switch opts.OnConflict {
+		case models.ConflictReplace:
+			result.Replaced = true
+		case models.ConflictRenumber:
+			targetID = nextModuleID(known)
+			result.ModuleID = targetID
+			result.Renumbered = true
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrModuleExists, targetID)
+		}
+	}
+
+	// Stage next to the modules so the final move is a rename on the same filesystem
+	staging, err := os.MkdirTemp(modulesDir, importStagingPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	stagedDir := filepath.Join(staging, module.ID)
+	if err := writeModuleFiles(stagedDir, files); err != nil {
+		return nil, err
+	}
+
+	// Validate under the bundle's own ID, resolving prerequisites against this instance
+	validator, err := NewModuleValidator()
+	if err != nil {
+		return nil, err
+	}
+	result.Validation = validator.ValidateModule(os.DirFS(staging), module.ID, known)
+	if !result.Validation.OK {
+		return result, fmt.Errorf("%w: module failed validation", ErrInvalidBundle)
+	}
+
+	if targetID != module.ID {
+		renamedDir := filepath.Join(staging, targetID)
+		if err := os.Rename(stagedDir, renamedDir); err != nil {
+			return nil, fmt.Errorf("failed to stage module: %w", err)
+		}
+		stagedDir = renamedDir
+
+		module.ID = targetID
+		if err := writeModuleJSON(stagedDir, module); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := installModuleDir(stagedDir, filepath.Join(modulesDir, targetID), filepath.Join(staging, ".previous")); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// knownModuleIDs returns the IDs of loaded modules and of module directories on disk
+func (s *ModuleService) knownModuleIDs(modulesDir string) (map[string]bool, error) {
+	modules, err := s.GetAllModules()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(modules))
+	for _, module := range modules {
+		known[module.ID] = true
+	}
+
+	entries, err := os.ReadDir(modulesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read modules directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && moduleIDPattern.MatchString(entry.Name()) {
+			known[entry.Name()] = true
+		}
+	}
+
+	return known, nil
+}
+
+// nextModuleID returns the module ID after the highest numbered known module
+func nextModuleID(known map[string]bool) string {
+	highest := 0
+	for id := range known {
+		if num := extractModuleNumber(id); num != math.MaxInt32 && num > highest {
+			highest = num
+		}
+	}
+	return fmt.Sprintf("module-%d", highest+1)
+}
+
+// writeModuleFiles writes files (keyed by module-relative path) below dir
+func writeModuleFiles(dir string, files map[string][]byte) error {
+	paths := make([]string, 0, len(files))
+	for rel := range files {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+
+	for _, rel := range paths {
+		target, err := safeJoin(dir, filepath.FromSlash(rel))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", path.Dir(rel), err)
+		}
+		if err := os.WriteFile(target, files[rel], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// writeModuleJSON writes module.json in the same layout as scripts/create-module.js
+func writeModuleJSON(dir string, module models.Module) error {
+	// The schema expects arrays, never null
+	for _, list := range []*[]string{&module.Tags, &module.LearningObjectives, &module.Prerequisites} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+
+	data, err := json.MarshalIndent(module, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, "module.json"), append(data, '\n'))
+}
+
+// installModuleDir moves a staged module into place, keeping the previous
+// version at backup until the move has succeeded
+func installModuleDir(staged, target, backup string) error {
+	replacing := false
+	if _, err := os.Stat(target); err == nil {
+		if err := os.Rename(target, backup); err != nil {
+			return fmt.Errorf("failed to move existing module aside: %w", err)
+		}
+		replacing = true
+	}
+
+	if err := os.Rename(staged, target); err != nil {
+		if replacing {
+			os.Rename(backup, target)
+		}
+		return fmt.Errorf("failed to install module: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// writeBundleTestModule writes a module that passes validation
+func writeBundleTestModule(t *testing.T, modulesDir, moduleId, server string) {
+	t.Helper()
+	moduleDir := filepath.Join(modulesDir, moduleId)
+	os.MkdirAll(filepath.Join(moduleDir, "lab"), 0755)
+	os.MkdirAll(filepath.Join(moduleDir, "exercise"), 0755)
+	os.WriteFile(filepath.Join(moduleDir, "module.json"), []byte(validModuleJSON(moduleId, "")), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "lab", "README.md"), []byte("# Lab"), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "exercise", "server.js"), []byte(server), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "exercise", "test.js"), []byte("// tests"), 0644)
+}
+
+func exportTestBundle(t *testing.T, service *ModuleService, moduleId string) []byte {
+	t.Helper()
+	var bundle bytes.Buffer
+	if err := service.ExportModule(moduleId, &bundle); err != nil {
+		t.Fatalf("Expected no error exporting %s, got %v", moduleId, err)
+	}
+	return bundle.Bytes()
+}
+
+// rewriteBundle copies a bundle, letting edit rename entries or replace their contents
+func rewriteBundle(t *testing.T, bundle []byte, edit func(header *tar.Header, data []byte) []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(bundle))
+	if err != nil {
+		t.Fatalf("Failed to read bundle: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read bundle: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		data = edit(header, data)
+		header.Size = int64(len(data))
+		tw.WriteHeader(header)
+		tw.Write(data)
+	}
+	tw.Close()
+	gw.Close()
+	return out.Bytes()
+}
+
+func TestModuleService_ExportImportRenumber(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+	writeBundleTestModule(t, modulesDir, "module-3", "// starter 3")
+	// Files written while running code must not end up in the bundle
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "tmp-server.js"), []byte("// tmp"), 0644)
+
+	service := NewModuleServiceWithPath(modulesDir)
+	bundle := exportTestBundle(t, service, "module-1")
+
+	result, err := service.ImportModule(bytes.NewReader(bundle), models.ImportOptions{OnConflict: models.ConflictRenumber})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ModuleID != "module-4" || result.OriginalID != "module-1" || !result.Renumbered {
+		t.Errorf("Expected module-1 renumbered to module-4, got %+v", result)
+	}
+	if result.Files != 4 {
+		t.Errorf("Expected 4 files, got %d", result.Files)
+	}
+
+	// The catalog is reloaded with the module under its new ID
+	content, err := service.GetModuleContent("module-4")
+	if err != nil {
+		t.Fatalf("Expected imported module to be loaded, got %v", err)
+	}
+	if content.Module.ID != "module-4" {
+		t.Errorf("Expected module.json ID to be rewritten, got %s", content.Module.ID)
+	}
+	if content.ExerciseContent.EditorFiles.Server != "// starter" {
+		t.Errorf("Expected server code to be imported, got %q", content.ExerciseContent.EditorFiles.Server)
+	}
+	if _, err := os.Stat(filepath.Join(modulesDir, "module-4", "exercise", "tmp-server.js")); !os.IsNotExist(err) {
+		t.Errorf("Expected transient files to be left out of the bundle")
+	}
+
+	// No staging directories are left behind
+	entries, _ := os.ReadDir(modulesDir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), importStagingPrefix) {
+			t.Errorf("Expected staging directory %s to be removed", entry.Name())
+		}
+	}
+}
+
+func TestModuleService_ImportConflict(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+
+	service := NewModuleServiceWithPath(modulesDir)
+	bundle := exportTestBundle(t, service, "module-1")
+
+	_, err := service.ImportModule(bytes.NewReader(bundle), models.ImportOptions{})
+	if !errors.Is(err, ErrModuleExists) {
+		t.Fatalf("Expected ErrModuleExists, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "server.js"), []byte("// changed"), 0644)
+
+	result, err := service.ImportModule(bytes.NewReader(bundle), models.ImportOptions{OnConflict: models.ConflictReplace})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Replaced || result.ModuleID != "module-1" {
+		t.Errorf("Expected module-1 to be replaced, got %+v", result)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(modulesDir, "module-1", "exercise", "server.js"))
+	if string(data) != "// starter" {
+		t.Errorf("Expected server code from the bundle, got %q", data)
+	}
+}
+
+func TestModuleService_ImportRejectsTamperedBundle(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+
+	service := NewModuleServiceWithPath(modulesDir)
+	bundle := exportTestBundle(t, service, "module-1")
+
+	tampered := rewriteBundle(t, bundle, func(header *tar.Header, data []byte) []byte {
+		if header.Name == "module/exercise/server.js" {
+			return []byte("// tampered")
+		}
+		return data
+	})
+
+	_, err := service.ImportModule(bytes.NewReader(tampered), models.ImportOptions{TargetID: "module-2"})
+	if !errors.Is(err, ErrInvalidBundle) || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("Expected checksum error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(modulesDir, "module-2")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be installed")
+	}
+}
+
+func TestModuleService_ImportValidatesModule(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+
+	service := NewModuleServiceWithPath(modulesDir)
+	bundle := exportTestBundle(t, service, "module-1")
+
+	// Rename the server code consistently in the archive and the manifest, so
+	// the bundle is intact but module.json references a missing file
+	broken := rewriteBundle(t, bundle, func(header *tar.Header, data []byte) []byte {
+		switch header.Name {
+		case bundleManifestName:
+			return []byte(strings.Replace(string(data), `"path": "exercise/server.js"`, `"path": "exercise/server.js.orig"`, 1))
+		case "module/exercise/server.js":
+			header.Name = "module/exercise/server.js.orig"
+		}
+		return data
+	})
+
+	result, err := service.ImportModule(bytes.NewReader(broken), models.ImportOptions{TargetID: "module-2"})
+	if !errors.Is(err, ErrInvalidBundle) {
+		t.Fatalf("Expected ErrInvalidBundle, got %v", err)
+	}
+	if result == nil || result.Validation == nil || !hasIssue(result.Validation, "module-1", models.IssueMissingFile) {
+		t.Errorf("Expected a missing file issue, got %+v", result)
+	}
+}
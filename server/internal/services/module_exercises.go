@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ErrExerciseNotFound is returned when a module has no exercise with the requested ID
+var ErrExerciseNotFound = errors.New("exercise not found")
+
+// exerciseType returns how an exercise's code is run. Exercises without a
+// type keep the behaviour from before exercises had types: module-1 is
+// function based and every other module starts a server.
+func exerciseType(moduleId string, exercise models.Exercise) string {
+	if exercise.Type != "" {
+		return exercise.Type
+	}
+	if moduleId == "module-1" {
+		return models.ExerciseTypeFunction
+	}
+	return models.ExerciseTypeServer
+}
+
+// findExercise returns the exercise with the given ID; an empty ID selects
+// the module's first exercise
+func findExercise(module models.Module, exerciseId string) (models.Exercise, int, error) {
+	exercises := module.ExerciseList()
+	if exerciseId == "" {
+		return exercises[0], 0, nil
+	}
+	for i, exercise := range exercises {
+		if exercise.ID == exerciseId {
+			return exercise, i, nil
+		}
+	}
+	return models.Exercise{}, 0, fmt.Errorf("%w: %s/%s", ErrExerciseNotFound, module.ID, exerciseId)
+}
+
+// exerciseSummaries lists a module's exercises in order
+func exerciseSummaries(module models.Module) []models.ExerciseSummary {
+	exercises := module.ExerciseList()
+	summaries := make([]models.ExerciseSummary, 0, len(exercises))
+	for _, exercise := range exercises {
+		summaries = append(summaries, models.ExerciseSummary{
+			ID:    exercise.ID,
+			Title: exercise.Title,
+			Type:  exerciseType(module.ID, exercise),
+			Hints: len(exercise.Hints),
+		})
+	}
+	return summaries
+}
+
+// loadExerciseSteps reads the content of every exercise in a module
+func (s *ModuleService) loadExerciseSteps(module models.Module) []*models.ExerciseStep {
+	summaries := exerciseSummaries(module)
+	steps := make([]*models.ExerciseStep, 0, len(summaries))
+	for i, exercise := range module.ExerciseList() {
+		name := fmt.Sprintf("exercise %s", exercise.ID)
+		steps = append(steps, &models.ExerciseStep{
+			ModuleID:        module.ID,
+			ExerciseSummary: summaries[i],
+			Position:        i + 1,
+			LabContent:      s.readMarkdownFile(module.ID, exercise.Lab, name+" lab"),
+			ExerciseContent: s.loadExerciseContent(module.ID, name, exercise.Files),
+		})
+	}
+	return steps
+}
+
+// GetModuleExercises lists a module's exercises in order
+func (s *ModuleService) GetModuleExercises(moduleId string) ([]models.ExerciseSummary, error) {
+	content, err := s.GetModuleContent(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	return content.Exercises, nil
+}
+
+// GetModuleExercise returns the full content of one of a module's exercises
+func (s *ModuleService) GetModuleExercise(moduleId, exerciseId string) (*models.ExerciseStep, error) {
+	catalog, err := s.currentCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	steps, ok := catalog.exercises[moduleId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, moduleId)
+	}
+	for _, step := range steps {
+		if step.ID == exerciseId {
+			copied := *step
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrExerciseNotFound, moduleId, exerciseId)
+}
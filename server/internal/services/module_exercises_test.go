@@ -0,0 +1,142 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+const steppedModuleJSON = `{
+	"id": "module-3",
+	"title": "Authentication",
+	"description": "Authentication in steps",
+	"difficulty": "Intermediate",
+	"estimatedTime": "2 hours",
+	"files": {"lab": {"readme": "lab/README.md"}, "exercise": {}},
+	"exercises": [
+		{
+			"id": "hashing",
+			"title": "Hash passwords",
+			"type": "function",
+			"lab": "exercises/hashing/LAB.md",
+			"files": {"readme": "exercises/hashing/README.md", "server": "exercises/hashing/server.js", "test": "exercises/hashing/test.js"}
+		},
+		{
+			"id": "sessions",
+			"title": "Sessions",
+			"files": {"server": "exercises/sessions/server.js", "test": "exercises/sessions/spec.js"}
+		}
+	]
+}`
+
+func writeSteppedModule(t *testing.T, modulesDir string) {
+	t.Helper()
+	moduleDir := filepath.Join(modulesDir, "module-3")
+	for _, dir := range []string{"lab", "exercises/hashing", "exercises/sessions"} {
+		os.MkdirAll(filepath.Join(moduleDir, dir), 0755)
+	}
+	files := map[string]string{
+		"module.json":                  steppedModuleJSON,
+		"lab/README.md":                "# Authentication",
+		"exercises/hashing/LAB.md":     "# Hashing",
+		"exercises/hashing/README.md":  "Hash the password",
+		"exercises/hashing/server.js":  "// hashing starter",
+		"exercises/hashing/test.js":    "// hashing tests",
+		"exercises/sessions/server.js": "// sessions starter",
+		"exercises/sessions/spec.js":   "// sessions tests",
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(moduleDir, filepath.FromSlash(name)), []byte(content), 0644)
+	}
+}
+
+func TestModuleService_GetModuleExercises(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	service := NewModuleServiceWithPath(modulesDir)
+
+	exercises, err := service.GetModuleExercises("module-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(exercises) != 2 || exercises[0].ID != "hashing" || exercises[1].ID != "sessions" {
+		t.Fatalf("Expected exercises in order, got %+v", exercises)
+	}
+	if exercises[0].Type != models.ExerciseTypeFunction || exercises[1].Type != models.ExerciseTypeServer {
+		t.Errorf("Expected function then server exercises, got %+v", exercises)
+	}
+
+	step, err := service.GetModuleExercise("module-3", "hashing")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if step.Position != 1 || step.LabContent != "# Hashing" || step.ExerciseContent.EditorFiles.Test != "// hashing tests" {
+		t.Errorf("Unexpected exercise content: %+v", step)
+	}
+
+	if _, err := service.GetModuleExercise("module-3", "missing"); err == nil {
+		t.Error("Expected an error for an unknown exercise")
+	}
+}
+
+func TestModuleService_DefaultExercise(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-2", "// starter")
+	service := NewModuleServiceWithPath(modulesDir)
+
+	step, err := service.GetModuleExercise("module-2", models.DefaultExerciseID)
+	if err != nil {
+		t.Fatalf("Expected the default exercise, got %v", err)
+	}
+	if step.Type != models.ExerciseTypeServer || step.LabContent != "# Lab" || step.ExerciseContent.EditorFiles.Server != "// starter" {
+		t.Errorf("Expected the module's lab and exercise files, got %+v", step)
+	}
+}
+
+func TestTestRunner_ResolveExercise(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	runner := &TestRunner{source: NewDirContentSource(modulesDir)}
+
+	first, err := runner.resolveExercise("module-3", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.id != "hashing" || first.exerciseType != models.ExerciseTypeFunction || first.testFile != "test.js" {
+		t.Errorf("Expected the first exercise by default, got %+v", first)
+	}
+
+	sessions, err := runner.resolveExercise("module-3", "sessions")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sessions.dir != filepath.Join(modulesDir, "module-3", "exercises", "sessions") || sessions.testFile != "spec.js" {
+		t.Errorf("Expected code to run next to spec.js, got %+v", sessions)
+	}
+
+	if _, err := runner.resolveExercise("module-3", "missing"); err == nil {
+		t.Error("Expected an error for an unknown exercise")
+	}
+}
+
+func TestModuleValidator_Exercises(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	validator, err := NewModuleValidator()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	report := validator.ValidateModule(os.DirFS(modulesDir), "module-3", nil)
+	if !report.OK {
+		t.Fatalf("Expected stepped module to be valid, got %+v", report.Issues)
+	}
+
+	os.Remove(filepath.Join(modulesDir, "module-3", "exercises", "sessions", "spec.js"))
+	report = validator.ValidateModule(os.DirFS(modulesDir), "module-3", nil)
+	if report.OK || !hasIssue(report, "module-3", models.IssueMissingFile) {
+		t.Errorf("Expected a missing file issue, got %+v", report.Issues)
+	}
+}
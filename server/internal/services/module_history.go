@@ -0,0 +1,159 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrVersionNotFound is returned when a module version was never recorded
+var ErrVersionNotFound = errors.New("module version not found")
+
+// historyEntry is a recorded version as stored on disk
+type historyEntry struct {
+	RecordedAt time.Time             `json:"recordedAt"`
+	Content    *models.ModuleContent `json:"content"`
+}
+
+// ModuleHistory keeps every version of module content the server has loaded,
+// so results graded against an older version can be looked up later. With a
+// directory, versions are also written to disk and survive restarts.
+type ModuleHistory struct {
+	dir string
+
+	mu       sync.Mutex
+	versions map[string][]models.ModuleVersion
+	contents map[string]*models.ModuleContent
+}
+
+// NewModuleHistory creates a history that is kept in memory only
+func NewModuleHistory() *ModuleHistory {
+	return &ModuleHistory{
+		versions: make(map[string][]models.ModuleVersion),
+		contents: make(map[string]*models.ModuleContent),
+	}
+}
+
+// NewModuleHistoryWithDir creates a history persisted to dir, loading any
+// versions already recorded there
+func NewModuleHistoryWithDir(dir string) (*ModuleHistory, error) {
+	h := NewModuleHistory()
+	h.dir = dir
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	moduleDirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	for _, moduleDir := range moduleDirs {
+		if !moduleDir.IsDir() || !moduleIDPattern.MatchString(moduleDir.Name()) {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(dir, moduleDir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history for %s: %w", moduleDir.Name(), err)
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), ".json") {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, moduleDir.Name(), file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			var entry historyEntry
+			if err := json.Unmarshal(data, &entry); err != nil || entry.Content == nil {
+				logrus.Warnf("Skipping unreadable module history %s/%s", moduleDir.Name(), file.Name())
+				continue
+			}
+			h.add(entry.Content.Module.ID, entry.Content, entry.RecordedAt)
+		}
+	}
+
+	for moduleId := range h.versions {
+		versions := h.versions[moduleId]
+		sort.Slice(versions, func(i, j int) bool { return versions[i].RecordedAt.Before(versions[j].RecordedAt) })
+	}
+
+	return h, nil
+}
+
+func historyKey(moduleId, version string) string {
+	return moduleId + "@" + version
+}
+
+// add stores a version in memory; the caller holds mu or owns h
+func (h *ModuleHistory) add(moduleId string, content *models.ModuleContent, recordedAt time.Time) {
+	h.versions[moduleId] = append(h.versions[moduleId], models.ModuleVersion{
+		Version:    content.Version,
+		RecordedAt: recordedAt,
+	})
+	h.contents[historyKey(moduleId, content.Version)] = content
+}
+
+// Record stores content as a new version of its module unless that version
+// was already recorded, as it is when content reverts to an earlier version
+func (h *ModuleHistory) Record(content *models.ModuleContent) error {
+	if content.Version == "" {
+		return nil
+	}
+	moduleId := content.Module.ID
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.contents[historyKey(moduleId, content.Version)]; ok {
+		return nil
+	}
+
+	recordedAt := time.Now().UTC()
+	h.add(moduleId, content, recordedAt)
+
+	if h.dir == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(historyEntry{RecordedAt: recordedAt, Content: content})
+	if err != nil {
+		return err
+	}
+	moduleDir := filepath.Join(h.dir, moduleId)
+	if err := os.MkdirAll(moduleDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(moduleDir, content.Version+".json"), data, 0644)
+}
+
+// Versions lists a module's recorded versions, oldest first
+func (h *ModuleHistory) Versions(moduleId string) []models.ModuleVersion {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]models.ModuleVersion{}, h.versions[moduleId]...)
+}
+
+// Get returns the content of a recorded version
+func (h *ModuleHistory) Get(moduleId, version string) (*models.ModuleContent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	content, ok := h.contents[historyKey(moduleId, version)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s@%s", ErrVersionNotFound, moduleId, version)
+	}
+	copied := *content
+	return &copied, nil
+}
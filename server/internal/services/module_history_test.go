@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModuleService_VersionsTrackContentChanges(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	historyDir := filepath.Join(t.TempDir(), "history")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+
+	history, err := NewModuleHistoryWithDir(historyDir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := NewModuleServiceWithPath(modulesDir).WithHistory(history)
+
+	original, err := service.GetModuleContent("module-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if original.Version == "" {
+		t.Fatal("Expected module content to have a version")
+	}
+
+	// Reloading unchanged content keeps the version
+	service.Reload()
+	if content, _ := service.GetModuleContent("module-1"); content.Version != original.Version {
+		t.Errorf("Expected version %s after reload, got %s", original.Version, content.Version)
+	}
+
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "test.js"), []byte("// changed tests"), 0644)
+	service.Reload()
+
+	changed, _ := service.GetModuleContent("module-1")
+	if changed.Version == original.Version {
+		t.Fatal("Expected a new version after test.js changed")
+	}
+
+	versions, err := service.GetModuleVersions("module-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != original.Version || versions[0].Current || !versions[1].Current {
+		t.Fatalf("Expected the original and current versions, got %+v", versions)
+	}
+
+	previous, err := service.GetModuleVersion("module-1", original.Version)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if previous.ExerciseContent.EditorFiles.Test != "// tests" {
+		t.Errorf("Expected the original tests, got %q", previous.ExerciseContent.EditorFiles.Test)
+	}
+
+	if _, err := service.GetModuleVersion("module-1", "0000000000000000"); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Expected ErrVersionNotFound, got %v", err)
+	}
+
+	// Reverting to the original content makes it current without recording it again
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "test.js"), []byte("// tests"), 0644)
+	service.Reload()
+	versions, _ = service.GetModuleVersions("module-1")
+	if len(versions) != 2 || !versions[0].Current || versions[1].Current {
+		t.Fatalf("Expected the original version to be current again, got %+v", versions)
+	}
+
+	// Versions persisted to disk survive a restart
+	reopened, err := NewModuleHistoryWithDir(historyDir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := reopened.Versions("module-1"); len(got) != 2 || got[0].Version != original.Version {
+		t.Errorf("Expected 2 persisted versions, got %+v", got)
+	}
+	if _, err := reopened.Get("module-1", original.Version); err != nil {
+		t.Errorf("Expected persisted version to be readable, got %v", err)
+	}
+}
+
+func TestContentSource_ModuleVersionIgnoresTransientFiles(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+	source := NewDirContentSource(modulesDir)
+
+	before, err := source.ModuleVersion("module-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "tmp-server.js"), []byte("// submitted"), 0644)
+	if after, _ := source.ModuleVersion("module-1"); after != before {
+		t.Errorf("Expected running code not to change the version, got %s and %s", before, after)
+	}
+}
+
+func TestTestRunner_StampsVersionOfFilesRun(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeBundleTestModule(t, modulesDir, "module-1", "// starter")
+	service := NewModuleServiceWithPath(modulesDir)
+	loaded, err := service.GetModuleContent("module-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	runner := (&TestRunner{source: NewDirContentSource(modulesDir)}).WithCatalog(service)
+
+	// The tests change on disk before the catalog is reloaded
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "test.js"), []byte("// changed tests"), 0644)
+	exercise, err := runner.resolveExercise("module-1", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if exercise.version == "" || exercise.version == loaded.Version {
+		t.Fatalf("Expected the version of the changed tests, got %q", exercise.version)
+	}
+
+	runner.recordVersion("module-1", exercise.version)
+	if _, err := service.GetModuleVersion("module-1", exercise.version); err != nil {
+		t.Errorf("Expected the graded version to be recorded, got %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// searchFields lists the indexed fields in the order highlights are returned,
+// with the weight a match in each field adds to a module's score
+var searchFields = []struct {
+	name   string
+	weight float64
+}{
+	{models.SearchFieldTitle, 5},
+	{models.SearchFieldTags, 4},
+	{models.SearchFieldDescription, 3},
+	{models.SearchFieldObjectives, 2},
+	{models.SearchFieldLab, 1},
+	{models.SearchFieldExercise, 1},
+}
+
+// snippetContext is roughly how many bytes of text surround a highlighted match
+const snippetContext = 60
+
+// searchIndex is a full-text index over module metadata and lab and exercise
+// instructions. It is built with the catalog and never modified.
+type searchIndex struct {
+	// terms holds every indexed word, sorted, so the words sharing a prefix
+	// are next to each other
+	terms []searchTerm
+	// texts holds the indexed text of each module's fields for snippets
+	texts map[string]map[string]string
+}
+
+// searchTerm is a lowercased word with how often it occurs in each field of
+// each module
+type searchTerm struct {
+	term     string
+	postings map[string]map[string]int // by module ID, then field
+}
+
+// searchMatch is how well a module matches a search
+type searchMatch struct {
+	score  float64
+	fields map[string]bool
+}
+
+// textToken is a word in a text and its byte offsets
+type textToken struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into lowercased words of letters and digits
+func tokenize(text string) []textToken {
+	var tokens []textToken
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			tokens = append(tokens, textToken{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, textToken{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+// moduleSearchTexts collects the searchable text of a module. Whitespace is
+// collapsed so snippets read as a single line.
+func moduleSearchTexts(module models.Module, content *models.ModuleContent, steps []*models.ExerciseStep) map[string]string {
+	lab := []string{}
+	exercise := []string{}
+	if content != nil {
+		lab = append(lab, content.LabContent)
+		exercise = append(exercise, content.ExerciseContent.Readme)
+	}
+	// A module without an exercises list has a single step made from the content above
+	if len(module.Exercises) == 0 {
+		steps = nil
+	}
+	for _, step := range steps {
+		lab = append(lab, step.LabContent)
+		exercise = append(exercise, step.ExerciseContent.Readme)
+	}
+
+	texts := map[string]string{
+		models.SearchFieldTitle:       module.Title,
+		models.SearchFieldTags:        strings.Join(module.Tags, ", "),
+		models.SearchFieldDescription: module.Description,
+		models.SearchFieldObjectives:  strings.Join(module.LearningObjectives, "; "),
+		models.SearchFieldLab:         strings.Join(lab, " "),
+		models.SearchFieldExercise:    strings.Join(exercise, " "),
+	}
+	for field, text := range texts {
+		texts[field] = strings.Join(strings.Fields(text), " ")
+	}
+	return texts
+}
+
+// buildSearchIndex indexes every module in the catalog
+func buildSearchIndex(catalog *moduleCatalog) *searchIndex {
+	index := &searchIndex{texts: make(map[string]map[string]string, len(catalog.modules))}
+
+	postings := make(map[string]map[string]map[string]int)
+	for _, module := range catalog.modules {
+		texts := moduleSearchTexts(module, catalog.contents[module.ID], catalog.exercises[module.ID])
+		index.texts[module.ID] = texts
+
+		for field, text := range texts {
+			for _, token := range tokenize(text) {
+				modules, ok := postings[token.term]
+				if !ok {
+					modules = make(map[string]map[string]int)
+					postings[token.term] = modules
+				}
+				if modules[module.ID] == nil {
+					modules[module.ID] = make(map[string]int)
+				}
+				modules[module.ID][field]++
+			}
+		}
+	}
+
+	index.terms = make([]searchTerm, 0, len(postings))
+	for term, modules := range postings {
+		index.terms = append(index.terms, searchTerm{term: term, postings: modules})
+	}
+	sort.Slice(index.terms, func(i, j int) bool {
+		return index.terms[i].term < index.terms[j].term
+	})
+	return index
+}
+
+// withPrefix returns the indexed words starting with prefix
+func (idx *searchIndex) withPrefix(prefix string) []searchTerm {
+	from := sort.Search(len(idx.terms), func(i int) bool {
+		return idx.terms[i].term >= prefix
+	})
+	to := from + sort.Search(len(idx.terms)-from, func(i int) bool {
+		return !strings.HasPrefix(idx.terms[from+i].term, prefix)
+	})
+	return idx.terms[from:to]
+}
+
+// match scores the modules matching the words of a search. Words match
+// indexed words they are a prefix of, so "auth" finds "authentication".
+// Modules have to match every word.
+func (idx *searchIndex) match(words []string) map[string]*searchMatch {
+	var matches map[string]*searchMatch
+	for i, word := range words {
+		found := make(map[string]*searchMatch)
+		for _, term := range idx.withPrefix(word) {
+			for moduleId, counts := range term.postings {
+				// Later words only narrow down the modules matching earlier ones
+				if i > 0 && matches[moduleId] == nil {
+					continue
+				}
+				m := found[moduleId]
+				if m == nil {
+					m = &searchMatch{fields: make(map[string]bool)}
+					if i > 0 {
+						m = matches[moduleId]
+					}
+					found[moduleId] = m
+				}
+				for _, field := range searchFields {
+					if count := counts[field.name]; count > 0 {
+						m.fields[field.name] = true
+						m.score += field.weight * (1 + math.Log(float64(count)))
+					}
+				}
+			}
+		}
+		matches = found
+	}
+	return matches
+}
+
+// highlights returns a snippet of each matching field of a module
+func (idx *searchIndex) highlights(moduleId string, words []string, fields map[string]bool) []models.SearchHighlight {
+	var highlights []models.SearchHighlight
+	for _, field := range searchFields {
+		if !fields[field.name] {
+			continue
+		}
+		if snippet, ok := highlightSnippet(idx.texts[moduleId][field.name], words); ok {
+			highlights = append(highlights, models.SearchHighlight{Field: field.name, Snippet: snippet})
+		}
+	}
+	return highlights
+}
+
+// highlightSnippet cuts an excerpt around the first word of text matching the
+// search and marks every matching word in it
+func highlightSnippet(text string, words []string) (string, bool) {
+	tokens := tokenize(text)
+	matches := func(term string) bool {
+		for _, word := range words {
+			if strings.HasPrefix(term, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	first := -1
+	for i, token := range tokens {
+		if matches(token.term) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	// Widen the excerpt word by word so it never cuts a word in half
+	from, to := first, first
+	for from > 0 && tokens[first].start-tokens[from-1].start <= snippetContext {
+		from--
+	}
+	for to < len(tokens)-1 && tokens[to+1].end-tokens[first].end <= 2*snippetContext {
+		to++
+	}
+	start, end := tokens[from].start, tokens[to].end
+	if from == 0 {
+		start = 0
+	}
+	if to == len(tokens)-1 {
+		end = len(text)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, token := range tokens[from : to+1] {
+		if !matches(token.term) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:token.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[token.start:token.end]))
+		b.WriteString("</mark>")
+		pos = token.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilters reports whether a module has one of the requested
+// difficulties and at least one of the requested tags
+func matchesFilters(module models.Module, query models.ModuleQuery) bool {
+	if len(query.Difficulty) > 0 && !containsFold(query.Difficulty, module.Difficulty) {
+		return false
+	}
+	if len(query.Tags) == 0 {
+		return true
+	}
+	for _, tag := range module.Tags {
+		if containsFold(query.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchModules returns the published modules matching a query. Text searches
+// are ordered by relevance, everything else by module number.
+func (s *ModuleService) SearchModules(query models.ModuleQuery) (*models.ModuleSearchPage, error) {
+	catalog, err := s.currentCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	for _, token := range tokenize(query.Text) {
+		words = append(words, token.term)
+	}
+
+	var matches map[string]*searchMatch
+	if len(words) > 0 {
+		matches = catalog.search.match(words)
+	}
+
+	results := []models.ModuleSearchResult{}
+	for _, module := range catalog.modules {
+		if module.IsDraft() || !matchesFilters(module, query) {
+			continue
+		}
+		result := models.ModuleSearchResult{Module: module}
+		if len(words) > 0 {
+			m, ok := matches[module.ID]
+			if !ok {
+				continue
+			}
+			result.Score = m.score
+			result.Highlights = catalog.search.highlights(module.ID, words, m.fields)
+		}
+		results = append(results, result)
+	}
+
+	if len(words) > 0 {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		})
+	}
+
+	page := &models.ModuleSearchPage{Total: len(results), Page: 1, PageSize: query.PageSize}
+	if query.Page > 1 {
+		page.Page = query.Page
+	}
+	if query.PageSize > 0 {
+		start := min((page.Page-1)*query.PageSize, len(results))
+		end := min(start+query.PageSize, len(results))
+		results = results[start:end]
+	}
+	page.Results = results
+
+	return page, nil
+}
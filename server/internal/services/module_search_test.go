@@ -0,0 +1,158 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// writeSearchModule writes a module with a lab README
+func writeSearchModule(t *testing.T, modulesDir, id, title, difficulty, tags, lab string) {
+	t.Helper()
+	moduleDir := filepath.Join(modulesDir, id)
+	os.MkdirAll(filepath.Join(moduleDir, "lab"), 0755)
+	moduleJSON := `{"id": "` + id + `", "title": "` + title + `", "description": "About ` + title + `",
+		"difficulty": "` + difficulty + `", "tags": ` + tags + `,
+		"learningObjectives": ["Understand ` + title + `"],
+		"files": {"lab": {"readme": "lab/README.md"}, "exercise": {}}}`
+	os.WriteFile(filepath.Join(moduleDir, "module.json"), []byte(moduleJSON), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "lab", "README.md"), []byte(lab), 0644)
+}
+
+func searchResultIDs(page *models.ModuleSearchPage) []string {
+	ids := []string{}
+	for _, result := range page.Results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func newSearchService(t *testing.T) *ModuleService {
+	t.Helper()
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSearchModule(t, modulesDir, "module-1", "Node.js Basics", "Beginner", `["node"]`,
+		"# Modules\n\nUse require() to load <modules>.")
+	writeSearchModule(t, modulesDir, "module-2", "File Uploads", "Intermediate", `["express", "uploads"]`,
+		"# Uploads\n\nHandle multipart forms with multer, then store what multer parsed.")
+	writeSearchModule(t, modulesDir, "module-3", "JWT Authentication", "Advanced", `["auth", "express"]`,
+		"# Tokens\n\nSign a JWT after checking the password.")
+	writeSearchModule(t, modulesDir, "module-4", "Sessions", "Intermediate", `["auth"]`,
+		"Sessions are an alternative to a JWT.")
+	writePathModule(t, modulesDir, "module-5", `[]`, models.ModuleStatusDraft)
+	return NewModuleServiceWithPath(modulesDir)
+}
+
+func TestModuleService_SearchModulesFilters(t *testing.T) {
+	service := newSearchService(t)
+
+	cases := []struct {
+		name  string
+		query models.ModuleQuery
+		want  []string
+	}{
+		{"everything but drafts", models.ModuleQuery{}, []string{"module-1", "module-2", "module-3", "module-4"}},
+		{"difficulty", models.ModuleQuery{Difficulty: []string{"intermediate"}}, []string{"module-2", "module-4"}},
+		{"any tag", models.ModuleQuery{Tags: []string{"uploads", "AUTH"}}, []string{"module-2", "module-3", "module-4"}},
+		{"tag and difficulty", models.ModuleQuery{Tags: []string{"auth"}, Difficulty: []string{"Advanced"}}, []string{"module-3"}},
+		{"second page", models.ModuleQuery{Page: 2, PageSize: 3}, []string{"module-4"}},
+		{"past the end", models.ModuleQuery{Page: 3, PageSize: 3}, []string{}},
+	}
+	for _, tc := range cases {
+		page, err := service.SearchModules(tc.query)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.name, err)
+		}
+		if got := searchResultIDs(page); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	page, _ := service.SearchModules(models.ModuleQuery{Page: 2, PageSize: 3})
+	if page.Total != 4 || page.Page != 2 {
+		t.Errorf("Expected page 2 of 4 modules, got %+v", page)
+	}
+}
+
+func TestModuleService_SearchModulesText(t *testing.T) {
+	service := newSearchService(t)
+
+	// The title match ranks module-3 above the lab-only match in module-4
+	page, err := service.SearchModules(models.ModuleQuery{Text: "jwt"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := searchResultIDs(page); !reflect.DeepEqual(got, []string{"module-3", "module-4"}) {
+		t.Fatalf("Expected module-3 then module-4, got %v", got)
+	}
+	if page.Results[0].Score <= page.Results[1].Score {
+		t.Errorf("Expected title matches to score higher, got %+v", page.Results)
+	}
+	if highlight := page.Results[0].Highlights[0]; highlight.Field != models.SearchFieldTitle || highlight.Snippet != "<mark>JWT</mark> Authentication" {
+		t.Errorf("Unexpected title highlight: %+v", highlight)
+	}
+
+	page, _ = service.SearchModules(models.ModuleQuery{Text: "Multer"})
+	if got := searchResultIDs(page); !reflect.DeepEqual(got, []string{"module-2"}) {
+		t.Fatalf("Expected module-2, got %v", got)
+	}
+	want := models.SearchHighlight{
+		Field:   models.SearchFieldLab,
+		Snippet: "# Uploads Handle multipart forms with <mark>multer</mark>, then store what <mark>multer</mark> parsed.",
+	}
+	if !reflect.DeepEqual(page.Results[0].Highlights, []models.SearchHighlight{want}) {
+		t.Errorf("Expected %+v, got %+v", want, page.Results[0].Highlights)
+	}
+
+	// Words match by prefix and all of them have to match
+	page, _ = service.SearchModules(models.ModuleQuery{Text: "auth sign"})
+	if got := searchResultIDs(page); !reflect.DeepEqual(got, []string{"module-3"}) {
+		t.Errorf("Expected module-3, got %v", got)
+	}
+
+	page, _ = service.SearchModules(models.ModuleQuery{Text: "modules"})
+	if len(page.Results) != 1 || !strings.Contains(page.Results[0].Highlights[0].Snippet, "&lt;<mark>modules</mark>&gt;") {
+		t.Errorf("Expected escaped snippet, got %+v", page.Results)
+	}
+}
+
+func TestHighlightSnippet_TrimsLongText(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "express router " + strings.Repeat("dolor sit ", 30)
+
+	snippet, ok := highlightSnippet(text, []string{"router"})
+	if !ok {
+		t.Fatal("Expected a match")
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, "express <mark>router</mark> dolor") {
+		t.Errorf("Unexpected snippet %q", snippet)
+	}
+	if len(snippet) > 4*snippetContext {
+		t.Errorf("Expected a short snippet, got %d bytes", len(snippet))
+	}
+}
+
+func TestSearchIndex_WithPrefix(t *testing.T) {
+	index := &searchIndex{}
+	for _, term := range []string{"api", "auth", "authentication", "authorization", "b", "express"} {
+		index.terms = append(index.terms, searchTerm{term: term})
+	}
+
+	for prefix, expected := range map[string][]string{
+		"auth":  {"auth", "authentication", "authorization"},
+		"autho": {"authorization"},
+		"a":     {"api", "auth", "authentication", "authorization"},
+		"c":     {},
+		"z":     {},
+	} {
+		terms := []string{}
+		for _, term := range index.withPrefix(prefix) {
+			terms = append(terms, term.term)
+		}
+		if !reflect.DeepEqual(terms, expected) {
+			t.Errorf("Expected %v for %q, got %v", expected, prefix, terms)
+		}
+	}
+}
@@ -2,20 +2,28 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"math"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/backend2lab/backend2lab/server/internal/models"
 
 	"github.com/sirupsen/logrus"
 )
 
+// ErrModuleNotFound is returned when no module has the requested ID
+var ErrModuleNotFound = errors.New("module not found")
+
 // safeJoin joins baseDir and rel, ensuring the result is within baseDir (prevents path traversal)
 func safeJoin(baseDir, rel string) (string, error) {
 	absBase, err := filepath.Abs(baseDir)
@@ -49,31 +57,168 @@ func extractModuleNumber(name string) int {
 	return num
 }
 
+// moduleCatalog is an immutable snapshot of all module metadata and content.
+// A new snapshot is built on reload and swapped in atomically, so readers
+// always see a consistent set of modules.
+type moduleCatalog struct {
+	modules   []models.Module
+	contents  map[string]*models.ModuleContent
+	exercises map[string][]*models.ExerciseStep
+	path      *learningPath
+	search    *searchIndex
+	loadedAt  time.Time
+}
+
 type ModuleService struct {
-	modulesPath string
+	source   *ContentSource
+	catalog  atomic.Pointer[moduleCatalog]
+	reloadMu sync.Mutex
+	// writeMu serializes changes to module content on disk
+	writeMu sync.Mutex
+	history  *ModuleHistory
+	markdown *MarkdownRenderer
 }
 
 func NewModuleService() *ModuleService {
 	// Use the modules directory from the current working directory
 	modulesPath := filepath.Join("src", "modules")
-	return &ModuleService{
-		modulesPath: modulesPath,
-	}
+	return NewModuleServiceWithSource(NewDirContentSource(modulesPath))
 }
 
 // NewModuleServiceWithPath creates a module service with a custom path (for testing)
 func NewModuleServiceWithPath(modulesPath string) *ModuleService {
+	return NewModuleServiceWithSource(NewDirContentSource(modulesPath))
+}
+
+// NewModuleServiceWithSource creates a module service reading from a content source
+func NewModuleServiceWithSource(source *ContentSource) *ModuleService {
 	return &ModuleService{
-		modulesPath: modulesPath,
+		source:   source,
+		history:  NewModuleHistory(),
+		markdown: NewMarkdownRenderer(),
+	}
+}
+
+// WithHistory sets where versions of module content are recorded
+func (s *ModuleService) WithHistory(history *ModuleHistory) *ModuleService {
+	s.history = history
+	return s
+}
+
+// Reload re-reads all modules from disk and swaps in the new catalog.
+// On failure the previous catalog stays in place.
+func (s *ModuleService) Reload() (*models.CatalogStatus, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	catalog, err := s.buildCatalog()
+	if err != nil {
+		return nil, err
+	}
+	s.storeCatalog(catalog)
+
+	logrus.Infof("Loaded %d modules from %s", len(catalog.modules), s.source)
+	return catalogStatus(catalog), nil
+}
+
+// Status reports the size and age of the current catalog
+func (s *ModuleService) Status() (*models.CatalogStatus, error) {
+	catalog, err := s.currentCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return catalogStatus(catalog), nil
+}
+
+func catalogStatus(catalog *moduleCatalog) *models.CatalogStatus {
+	return &models.CatalogStatus{
+		Modules:  len(catalog.modules),
+		LoadedAt: catalog.loadedAt,
+	}
+}
+
+// currentCatalog returns the loaded catalog, loading it on first use
+func (s *ModuleService) currentCatalog() (*moduleCatalog, error) {
+	if catalog := s.catalog.Load(); catalog != nil {
+		return catalog, nil
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	// Another caller may have loaded it while we waited
+	if catalog := s.catalog.Load(); catalog != nil {
+		return catalog, nil
+	}
+
+	catalog, err := s.buildCatalog()
+	if err != nil {
+		return nil, err
+	}
+	s.storeCatalog(catalog)
+	return catalog, nil
+}
+
+// storeCatalog swaps in a new catalog and records its module versions
+func (s *ModuleService) storeCatalog(catalog *moduleCatalog) {
+	s.catalog.Store(catalog)
+
+	for _, module := range catalog.modules {
+		content, ok := catalog.contents[module.ID]
+		if !ok {
+			continue
+		}
+		if err := s.history.Record(content); err != nil {
+			logrus.Warnf("Failed to record version of module %s: %v", module.ID, err)
+		}
+	}
+}
+
+// buildCatalog reads every module and its files from disk
+func (s *ModuleService) buildCatalog() (*moduleCatalog, error) {
+	modules, err := s.loadModules()
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &moduleCatalog{
+		modules:   modules,
+		contents:  make(map[string]*models.ModuleContent, len(modules)),
+		exercises: make(map[string][]*models.ExerciseStep, len(modules)),
+		path:      buildLearningPath(modules),
+		loadedAt:  time.Now(),
+	}
+
+	for _, module := range modules {
+		content, err := s.loadModuleContent(module)
+		if err != nil {
+			logrus.Errorf("Error loading content for module %s: %v", module.ID, err)
+			continue
+		}
+		catalog.contents[module.ID] = content
+		catalog.exercises[module.ID] = s.loadExerciseSteps(module)
 	}
+	catalog.search = buildSearchIndex(catalog)
+
+	return catalog, nil
 }
 
-// GetAllModules returns all available modules sorted by module number
+// GetAllModules returns all modules, including drafts, sorted by module number
 func (s *ModuleService) GetAllModules() ([]models.Module, error) {
+	catalog, err := s.currentCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]models.Module(nil), catalog.modules...), nil
+}
+
+// loadModules reads every module.json from disk, sorted by module number
+func (s *ModuleService) loadModules() ([]models.Module, error) {
 	var modules []models.Module
 
 	// Read the modules directory
-	entries, err := os.ReadDir(s.modulesPath)
+	entries, err := fs.ReadDir(s.source.FS(), ".")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read modules directory: %w", err)
 	}
@@ -105,9 +250,9 @@ func (s *ModuleService) GetAllModules() ([]models.Module, error) {
 
 	// Load each module
 	for _, moduleDir := range moduleDirs {
-		moduleConfigPath := filepath.Join(s.modulesPath, moduleDir.Name(), "module.json")
+		moduleConfigPath := path.Join(moduleDir.Name(), "module.json")
 		
-		moduleData, err := os.ReadFile(moduleConfigPath)
+		moduleData, err := fs.ReadFile(s.source.FS(), moduleConfigPath)
 		if err != nil {
 			logrus.Errorf("Error loading module %s: %v", moduleDir.Name(), err)
 			continue
@@ -127,103 +272,120 @@ func (s *ModuleService) GetAllModules() ([]models.Module, error) {
 
 // GetModuleById returns a specific module by ID
 func (s *ModuleService) GetModuleById(moduleId string) (*models.Module, error) {
-	modules, err := s.GetAllModules()
+	catalog, err := s.currentCatalog()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, module := range modules {
+	for _, module := range catalog.modules {
 		if module.ID == moduleId {
 			return &module, nil
 		}
 	}
 
-	return nil, fmt.Errorf("module %s not found", moduleId)
+	return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, moduleId)
 }
 
 // GetModuleContent returns the full content of a module including file contents
 func (s *ModuleService) GetModuleContent(moduleId string) (*models.ModuleContent, error) {
-	module, err := s.GetModuleById(moduleId)
+	catalog, err := s.currentCatalog()
 	if err != nil {
 		return nil, err
 	}
 
-	modulePath := filepath.Join(s.modulesPath, moduleId)
+	content, ok := catalog.contents[moduleId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModuleNotFound, moduleId)
+	}
+
+	// Hand out a copy so callers can't modify the shared catalog
+	contentCopy := *content
+	return &contentCopy, nil
+}
+
+// loadModuleContent reads the lab and exercise files of a module from the content source
+func (s *ModuleService) loadModuleContent(module models.Module) (*models.ModuleContent, error) {
+	moduleId := module.ID
 
 	// Check if module directory exists
-	if _, err := os.Stat(modulePath); os.IsNotExist(err) {
+	if _, err := fs.Stat(s.source.FS(), moduleId); err != nil {
 		return nil, fmt.Errorf("module directory does not exist: %s", moduleId)
 	}
 
-	// Read lab content
-	var labContent string
-	if module.Files.Lab.Readme != nil {
-		labReadmePath, err := safeJoin(modulePath, *module.Files.Lab.Readme)
-		if err != nil {
-			logrus.Warnf("Skipping lab readme due to path traversal: %v", err)
-		} else if content, err := os.ReadFile(labReadmePath); err == nil {
-			labContent = string(content)
-		}
+	version, err := s.source.ModuleVersion(moduleId)
+	if err != nil {
+		logrus.Warnf("Failed to compute version of module %s: %v", moduleId, err)
 	}
 
-	// Read exercise content
-	var exerciseContent models.ExerciseContent
+	return &models.ModuleContent{
+		Module:          module,
+		Version:         version,
+		LabContent:      s.readMarkdownFile(moduleId, module.Files.Lab.Readme, "lab readme"),
+		ExerciseContent: s.loadExerciseContent(moduleId, "exercise", module.Files.Exercise),
+		Exercises:       exerciseSummaries(module),
+	}, nil
+}
 
-	// Read exercise README
-	if module.Files.Exercise.Readme != nil {
-		exerciseReadmePath, err := safeJoin(modulePath, *module.Files.Exercise.Readme)
-		if err != nil {
-			logrus.Warnf("Skipping exercise readme due to path traversal: %v", err)
-		} else if content, err := os.ReadFile(exerciseReadmePath); err == nil {
-			exerciseContent.Readme = string(content)
-		}
+// readContentFile reads a file referenced from module.json, skipping it on error
+func (s *ModuleService) readContentFile(moduleId string, rel *string, description string) string {
+	if rel == nil {
+		return ""
+	}
+	content, err := s.source.readModuleFile(moduleId, *rel)
+	if err != nil {
+		logrus.Warnf("Skipping %s for module %s: %v", description, moduleId, err)
+		return ""
 	}
+	return string(content)
+}
 
-	// Read exercise server file
-	if module.Files.Exercise.Server != nil {
-		exerciseServerPath, err := safeJoin(modulePath, *module.Files.Exercise.Server)
-		if err != nil {
-			logrus.Warnf("Skipping exercise server file due to path traversal: %v", err)
-		} else if content, err := os.ReadFile(exerciseServerPath); err == nil {
-			exerciseContent.EditorFiles.Server = string(content)
-		}
+// readMarkdownFile reads instructions referenced from module.json, pointing
+// links to the module's assets at the assets endpoint
+func (s *ModuleService) readMarkdownFile(moduleId string, rel *string, description string) string {
+	content := s.readContentFile(moduleId, rel, description)
+	if content == "" {
+		return ""
 	}
+	return rewriteAssetLinks(moduleId, *rel, content)
+}
 
-	// Read exercise test file
-	if module.Files.Exercise.Test != nil {
-		exerciseTestPath, err := safeJoin(modulePath, *module.Files.Exercise.Test)
-		if err != nil {
-			logrus.Warnf("Skipping exercise test file due to path traversal: %v", err)
-		} else if content, err := os.ReadFile(exerciseTestPath); err == nil {
-			exerciseContent.EditorFiles.Test = string(content)
-		}
+// loadExerciseContent reads the instructions, editor files and solution of an exercise
+func (s *ModuleService) loadExerciseContent(moduleId, name string, files models.ModuleFile) models.ExerciseContent {
+	return models.ExerciseContent{
+		Readme: s.readMarkdownFile(moduleId, files.Readme, name+" readme"),
+		EditorFiles: models.EditorFiles{
+			Server:  s.readContentFile(moduleId, files.Server, name+" server file"),
+			Test:    s.readContentFile(moduleId, files.Test, name+" test file"),
+			Package: s.readContentFile(moduleId, files.Package, name+" package file"),
+		},
+		Solution: s.readContentFile(moduleId, files.Solution, name+" solution file"),
 	}
+}
 
-	// Read exercise package file
-	if module.Files.Exercise.Package != nil {
-		exercisePackagePath, err := safeJoin(modulePath, *module.Files.Exercise.Package)
-		if err != nil {
-			logrus.Warnf("Skipping exercise package file due to path traversal: %v", err)
-		} else if content, err := os.ReadFile(exercisePackagePath); err == nil {
-			exerciseContent.EditorFiles.Package = string(content)
-		}
+// GetModuleVersions lists the recorded versions of a module, oldest first,
+// marking the one currently loaded
+func (s *ModuleService) GetModuleVersions(moduleId string) ([]models.ModuleVersion, error) {
+	content, err := s.GetModuleContent(moduleId)
+	if err != nil {
+		return nil, err
 	}
 
-	// Read exercise solution
-	if module.Files.Exercise.Solution != nil {
-		exerciseSolutionPath, err := safeJoin(modulePath, *module.Files.Exercise.Solution)
-		if err != nil {
-			logrus.Warnf("Skipping exercise solution file due to path traversal: %v", err)
-		} else if content, err := os.ReadFile(exerciseSolutionPath); err == nil {
-			exerciseContent.Solution = string(content)
-		}
+	versions := s.history.Versions(moduleId)
+	for i := range versions {
+		versions[i].Current = versions[i].Version == content.Version
 	}
+	return versions, nil
+}
 
-	return &models.ModuleContent{
-		Module:          *module,
-		LabContent:      labContent,
-		ExerciseContent: exerciseContent,
-	}, nil
+// GetModuleVersion returns a module's content as it was at a recorded version
+func (s *ModuleService) GetModuleVersion(moduleId, version string) (*models.ModuleContent, error) {
+	return s.history.Get(moduleId, version)
+}
+
+// RenderMarkdown renders a lab or exercise README, reusing earlier results
+// for unchanged content
+func (s *ModuleService) RenderMarkdown(source string) *models.RenderedMarkdown {
+	return s.markdown.Render(source)
 }
 
 // GetAvailableModules returns a simplified list of modules
@@ -235,6 +397,9 @@ func (s *ModuleService) GetAvailableModules() ([]map[string]string, error) {
 
 	var availableModules []map[string]string
 	for _, module := range modules {
+		if module.IsDraft() {
+			continue
+		}
 		availableModules = append(availableModules, map[string]string{
 			"id":         module.ID,
 			"title":      module.Title,
@@ -244,3 +409,13 @@ func (s *ModuleService) GetAvailableModules() ([]map[string]string, error) {
 
 	return availableModules, nil
 }
+
+// Validate checks all module content against the module schema and reports
+// problems that GetAllModules and GetModuleContent would otherwise skip
+func (s *ModuleService) Validate() (*models.ValidationReport, error) {
+	validator, err := NewModuleValidator()
+	if err != nil {
+		return nil, err
+	}
+	return validator.Validate(s.source.FS())
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestModuleService_GetAllModules(t *testing.T) {
@@ -196,3 +197,68 @@ func TestModuleService_GetModuleContent(t *testing.T) {
 		t.Error("Expected test file content, got empty string")
 	}
 }
+
+func TestModuleService_ReloadSwapsCatalog(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSelfCheckModule(t, modulesDir, "module-1", "// starter v1", "// solution")
+
+	service := NewModuleServiceWithPath(modulesDir)
+
+	content, err := service.GetModuleContent("module-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if content.ExerciseContent.EditorFiles.Server != "// starter v1" {
+		t.Fatalf("Unexpected server content %q", content.ExerciseContent.EditorFiles.Server)
+	}
+
+	// Changes on disk are not visible until the catalog is reloaded
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "server.js"), []byte("// starter v2"), 0644)
+	writeSelfCheckModule(t, modulesDir, "module-2", "// starter", "// solution")
+
+	content, _ = service.GetModuleContent("module-1")
+	if content.ExerciseContent.EditorFiles.Server != "// starter v1" {
+		t.Errorf("Expected cached content before reload, got %q", content.ExerciseContent.EditorFiles.Server)
+	}
+
+	status, err := service.Reload()
+	if err != nil {
+		t.Fatalf("Expected no error reloading, got %v", err)
+	}
+	if status.Modules != 2 {
+		t.Errorf("Expected 2 modules after reload, got %d", status.Modules)
+	}
+
+	content, _ = service.GetModuleContent("module-1")
+	if content.ExerciseContent.EditorFiles.Server != "// starter v2" {
+		t.Errorf("Expected reloaded content, got %q", content.ExerciseContent.EditorFiles.Server)
+	}
+}
+
+func TestModuleService_WatchReloadsOnChange(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSelfCheckModule(t, modulesDir, "module-1", "// starter v1", "// solution")
+
+	service := NewModuleServiceWithPath(modulesDir)
+	if _, err := service.Reload(); err != nil {
+		t.Fatalf("Expected no error loading, got %v", err)
+	}
+
+	stop, err := service.Watch()
+	if err != nil {
+		t.Fatalf("Expected no error watching, got %v", err)
+	}
+	defer stop()
+
+	os.WriteFile(filepath.Join(modulesDir, "module-1", "exercise", "server.js"), []byte("// starter v2"), 0644)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		content, _ := service.GetModuleContent("module-1")
+		if content.ExerciseContent.EditorFiles.Server == "// starter v2" {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("Expected the catalog to reload after the file changed")
+}
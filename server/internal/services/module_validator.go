@@ -0,0 +1,331 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+	"github.com/backend2lab/backend2lab/server/schema"
+)
+
+var (
+	// moduleIDPattern matches module IDs, also used for prerequisites ("module-2")
+	moduleIDPattern = regexp.MustCompile(`^module-\d+$`)
+	// prerequisiteTitlePattern matches prerequisites naming a module ("Module 2: Express.js Fundamentals")
+	prerequisiteTitlePattern = regexp.MustCompile(`^Module (\d+):`)
+)
+
+// ModuleValidator checks module content against the module.json schema and
+// the files, IDs and prerequisites it references
+type ModuleValidator struct {
+	schema *jsonSchema
+}
+
+// NewModuleValidator creates a validator using the embedded module schema
+func NewModuleValidator() (*ModuleValidator, error) {
+	moduleSchema, err := parseJSONSchema(schema.ModuleSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &ModuleValidator{schema: moduleSchema}, nil
+}
+
+// moduleReport collects the issues found while validating
+type moduleReport struct {
+	report *models.ValidationReport
+}
+
+func (r *moduleReport) add(module, path, severity, code, format string, args ...interface{}) {
+	r.report.Issues = append(r.report.Issues, models.ValidationIssue{
+		Module:   module,
+		Path:     path,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	if severity == models.SeverityError {
+		r.report.Errors++
+	} else {
+		r.report.Warnings++
+	}
+}
+
+// Validate checks every module directory in fsys
+func (v *ModuleValidator) Validate(fsys fs.FS) (*models.ValidationReport, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read modules directory: %w", err)
+	}
+
+	r := &moduleReport{report: &models.ValidationReport{Issues: []models.ValidationIssue{}}}
+
+	idDirs := make(map[string][]string)
+	loaded := make(map[string]models.Module)
+	var dirs []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "module-") {
+			continue
+		}
+		dir := entry.Name()
+		r.report.Modules++
+
+		module, ok := v.validateModule(fsys, dir, r)
+		if !ok {
+			continue
+		}
+		dirs = append(dirs, dir)
+		loaded[dir] = module
+		if module.ID != "" {
+			idDirs[module.ID] = append(idDirs[module.ID], dir)
+		}
+	}
+
+	sort.Slice(dirs, func(i, j int) bool {
+		numI, numJ := extractModuleNumber(dirs[i]), extractModuleNumber(dirs[j])
+		if numI != numJ {
+			return numI < numJ
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	for _, dir := range dirs {
+		module := loaded[dir]
+
+		if others := idDirs[module.ID]; len(others) > 1 {
+			r.add(dir, "id", models.SeverityError, models.IssueDuplicateID,
+				"module ID %q is used by %s", module.ID, strings.Join(others, ", "))
+		}
+
+		checkPrerequisites(dir, module, func(id string) bool { return len(idDirs[id]) > 0 }, r)
+	}
+
+	checkPrerequisiteCycles(dirs, loaded, r)
+
+	r.report.OK = r.report.Errors == 0
+	return r.report, nil
+}
+
+// ValidateModule checks a single module directory in fsys, e.g. one staged
+// for import. Prerequisites are resolved against the IDs in knownIDs.
+func (v *ModuleValidator) ValidateModule(fsys fs.FS, dir string, knownIDs map[string]bool) *models.ValidationReport {
+	r := &moduleReport{report: &models.ValidationReport{Modules: 1, Issues: []models.ValidationIssue{}}}
+
+	if module, ok := v.validateModule(fsys, dir, r); ok {
+		checkPrerequisites(dir, module, func(id string) bool { return knownIDs[id] }, r)
+	}
+
+	r.report.OK = r.report.Errors == 0
+	return r.report
+}
+
+// checkPrerequisites reports prerequisites that name a module which does not exist
+func checkPrerequisites(dir string, module models.Module, exists func(id string) bool, r *moduleReport) {
+	for i, prerequisite := range module.Prerequisites {
+		target, isReference := prerequisiteModuleID(prerequisite)
+		if !isReference {
+			continue
+		}
+		field := fmt.Sprintf("prerequisites[%d]", i)
+		if target == module.ID {
+			r.add(dir, field, models.SeverityError, models.IssueUnresolvedPrerequisite,
+				"module cannot be its own prerequisite")
+		} else if !exists(target) {
+			r.add(dir, field, models.SeverityError, models.IssueUnresolvedPrerequisite,
+				"prerequisite %q refers to unknown module %s", prerequisite, target)
+		}
+	}
+}
+
+// checkPrerequisiteCycles reports modules whose prerequisites lead back to themselves
+func checkPrerequisiteCycles(dirs []string, loaded map[string]models.Module, r *moduleReport) {
+	modules := make([]models.Module, 0, len(dirs))
+	moduleDirs := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		module := loaded[dir]
+		if _, duplicate := moduleDirs[module.ID]; duplicate || module.ID == "" {
+			continue
+		}
+		moduleDirs[module.ID] = dir
+		modules = append(modules, module)
+	}
+
+	_, cycles := orderByPrerequisites(modules)
+	for _, cycle := range cycles {
+		for _, id := range cycle[:len(cycle)-1] {
+			r.add(moduleDirs[id], "prerequisites", models.SeverityError, models.IssuePrerequisiteCycle,
+				"prerequisites form a cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+}
+
+// validateModule checks a single module directory. It returns the parsed
+// module when module.json could be decoded.
+func (v *ModuleValidator) validateModule(fsys fs.FS, dir string, r *moduleReport) (models.Module, bool) {
+	var module models.Module
+
+	data, err := fs.ReadFile(fsys, path.Join(dir, "module.json"))
+	if err != nil {
+		r.add(dir, "module.json", models.SeverityError, models.IssueMissingFile, "module.json could not be read: %v", err)
+		return module, false
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		r.add(dir, "module.json", models.SeverityError, models.IssueInvalidJSON, "module.json is not valid JSON: %v", err)
+		return module, false
+	}
+
+	for _, violation := range v.schema.Validate(raw) {
+		code := models.IssueInvalidValue
+		switch {
+		case violation.Keyword == "additionalProperties":
+			code = models.IssueUnknownField
+		case violation.Keyword == "required":
+			code = models.IssueMissingField
+		case violation.Path == "difficulty" && violation.Keyword == "enum":
+			code = models.IssueInvalidDifficulty
+		}
+		r.add(dir, violation.Path, models.SeverityError, code, "%s %s", violation.Path, violation.Message)
+	}
+
+	if err := json.Unmarshal(data, &module); err != nil {
+		// Type errors were already reported against the schema
+		return module, false
+	}
+
+	if module.ID != "" && module.ID != dir {
+		r.add(dir, "id", models.SeverityError, models.IssueIDMismatch,
+			"module ID %q does not match directory %q", module.ID, dir)
+	}
+
+	checkModuleFiles(fsys, dir, "files.lab", module.Files.Lab, r)
+	checkModuleFiles(fsys, dir, "files.exercise", module.Files.Exercise, r)
+
+	if len(module.Exercises) == 0 && module.Files.Exercise.Test == nil {
+		r.add(dir, "files.exercise.test", models.SeverityWarning, models.IssueMissingField,
+			"exercise has no test file, so submissions cannot be graded")
+	}
+
+	checkExercises(fsys, dir, module.Exercises, r)
+	checkSolutionPolicy(dir, "solutionPolicy", module.SolutionPolicy, r)
+
+	return module, true
+}
+
+// checkExercises verifies the files of each exercise in a module's exercises
+// list and that exercise IDs are unique
+func checkExercises(fsys fs.FS, dir string, exercises []models.Exercise, r *moduleReport) {
+	seen := make(map[string]bool, len(exercises))
+	for i, exercise := range exercises {
+		field := fmt.Sprintf("exercises[%d]", i)
+
+		if seen[exercise.ID] {
+			r.add(dir, field+".id", models.SeverityError, models.IssueDuplicateID,
+				"exercise ID %q is used more than once", exercise.ID)
+		}
+		seen[exercise.ID] = true
+
+		checkModuleFiles(fsys, dir, field+".files", exercise.Files, r)
+		if exercise.Lab != nil {
+			checkModuleFile(fsys, dir, field+".lab", *exercise.Lab, r)
+		}
+
+		if exercise.Files.Test == nil {
+			r.add(dir, field+".files.test", models.SeverityWarning, models.IssueMissingField,
+				"exercise %q has no test file, so submissions cannot be graded", exercise.ID)
+		}
+
+		checkSolutionPolicy(dir, field+".solutionPolicy", exercise.SolutionPolicy, r)
+	}
+}
+
+// checkSolutionPolicy verifies that attempt and time policies say how many
+// attempts or how long learners have to wait
+func checkSolutionPolicy(dir, field string, policy *models.SolutionPolicy, r *moduleReport) {
+	if policy == nil {
+		return
+	}
+	switch policy.Release {
+	case models.SolutionReleaseAttempts:
+		if policy.Attempts == 0 {
+			r.add(dir, field+".attempts", models.SeverityError, models.IssueMissingField,
+				"attempts is required when solutions are released after attempts")
+		}
+	case models.SolutionReleaseTime:
+		if policy.After == "" {
+			r.add(dir, field+".after", models.SeverityError, models.IssueMissingField,
+				"after is required when solutions are released after a time limit")
+		}
+	}
+}
+
+// checkModuleFiles verifies that every file referenced by a ModuleFile exists
+// inside the module directory
+func checkModuleFiles(fsys fs.FS, dir, field string, files models.ModuleFile, r *moduleReport) {
+	refs := []struct {
+		name string
+		path *string
+	}{
+		{"readme", files.Readme},
+		{"server", files.Server},
+		{"test", files.Test},
+		{"solution", files.Solution},
+		{"package", files.Package},
+	}
+
+	for _, ref := range refs {
+		if ref.path == nil || *ref.path == "" {
+			continue
+		}
+		checkModuleFile(fsys, dir, field+"."+ref.name, *ref.path, r)
+	}
+}
+
+// checkModuleFile verifies that a file referenced from module.json exists
+// inside the module directory
+func checkModuleFile(fsys fs.FS, dir, fieldPath, filePath string, r *moduleReport) {
+	rel, ok := moduleRelativePath(filePath)
+	if !ok {
+		r.add(dir, fieldPath, models.SeverityError, models.IssueInvalidPath,
+			"%q must be a relative path inside the module directory", filePath)
+		return
+	}
+
+	info, err := fs.Stat(fsys, path.Join(dir, rel))
+	if err != nil {
+		r.add(dir, fieldPath, models.SeverityError, models.IssueMissingFile,
+			"referenced file %q does not exist", filePath)
+	} else if info.IsDir() {
+		r.add(dir, fieldPath, models.SeverityError, models.IssueMissingFile,
+			"referenced file %q is a directory", filePath)
+	}
+}
+
+// moduleRelativePath cleans a path from module.json and reports whether it
+// stays within the module directory
+func moduleRelativePath(p string) (string, bool) {
+	cleaned := path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == "." || !fs.ValidPath(cleaned) {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// prerequisiteModuleID returns the module ID a prerequisite refers to, if any.
+// Free-text prerequisites ("Basic JavaScript knowledge") do not refer to a module.
+func prerequisiteModuleID(prerequisite string) (string, bool) {
+	prerequisite = strings.TrimSpace(prerequisite)
+	if moduleIDPattern.MatchString(prerequisite) {
+		return prerequisite, true
+	}
+	if match := prerequisiteTitlePattern.FindStringSubmatch(prerequisite); match != nil {
+		return "module-" + match[1], true
+	}
+	return "", false
+}
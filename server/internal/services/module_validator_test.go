@@ -0,0 +1,209 @@
+package services
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func validModuleJSON(id string, extra string) string {
+	return `{
+		"id": "` + id + `",
+		"title": "Test Module",
+		"description": "A test module",
+		"difficulty": "Beginner",
+		"estimatedTime": "30 minutes",
+		"tags": ["test"],
+		"files": {
+			"lab": {"readme": "lab/README.md"},
+			"exercise": {"server": "exercise/server.js", "test": "exercise/test.js"}
+		},
+		"learningObjectives": ["Learn testing"],
+		"prerequisites": ["Basic knowledge"]` + extra + `
+	}`
+}
+
+func hasIssue(report *models.ValidationReport, module, code string) bool {
+	for _, issue := range report.Issues {
+		if issue.Module == module && issue.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestModuleValidator_ValidContent(t *testing.T) {
+	fsys := fstest.MapFS{
+		"module-1/module.json":        {Data: []byte(validModuleJSON("module-1", ""))},
+		"module-1/lab/README.md":      {Data: []byte("# Lab")},
+		"module-1/exercise/server.js": {Data: []byte("// Server")},
+		"module-1/exercise/test.js":   {Data: []byte("// Test")},
+	}
+
+	validator, err := NewModuleValidator()
+	if err != nil {
+		t.Fatalf("Expected no error creating validator, got %v", err)
+	}
+
+	report, err := validator.Validate(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !report.OK || len(report.Issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", report.Issues)
+	}
+}
+
+func TestModuleValidator_ReportsIssues(t *testing.T) {
+	fsys := fstest.MapFS{
+		// Unknown field, bad difficulty, missing test file and unresolved prerequisite
+		"module-1/module.json": {Data: []byte(`{
+			"id": "module-1",
+			"title": "Broken",
+			"description": "Broken module",
+			"difficulty": "Expert",
+			"estimatedTime": "10 minutes",
+			"files": {
+				"lab": {"readme": "lab/README.md", "slides": "lab/slides.pdf"},
+				"exercise": {"test": "exercise/test.js", "server": "../module-2/exercise/server.js"}
+			},
+			"prerequisites": ["module-7", "Basic JavaScript knowledge"]
+		}`)},
+		"module-1/lab/README.md": {Data: []byte("# Lab")},
+
+		// ID does not match its directory and duplicates module-3
+		"module-2/module.json":        {Data: []byte(validModuleJSON("module-3", ""))},
+		"module-2/lab/README.md":      {Data: []byte("# Lab")},
+		"module-2/exercise/server.js": {Data: []byte("// Server")},
+		"module-2/exercise/test.js":   {Data: []byte("// Test")},
+
+		"module-3/module.json":        {Data: []byte(validModuleJSON("module-3", ""))},
+		"module-3/lab/README.md":      {Data: []byte("# Lab")},
+		"module-3/exercise/server.js": {Data: []byte("// Server")},
+		"module-3/exercise/test.js":   {Data: []byte("// Test")},
+
+		"module-4/module.json": {Data: []byte(`{ not json`)},
+	}
+
+	validator, _ := NewModuleValidator()
+	report, err := validator.Validate(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.OK {
+		t.Fatal("Expected validation to fail")
+	}
+	if report.Modules != 4 {
+		t.Errorf("Expected 4 modules checked, got %d", report.Modules)
+	}
+
+	expected := []struct{ module, code string }{
+		{"module-1", models.IssueUnknownField},
+		{"module-1", models.IssueInvalidDifficulty},
+		{"module-1", models.IssueMissingFile},
+		{"module-1", models.IssueInvalidPath},
+		{"module-1", models.IssueUnresolvedPrerequisite},
+		{"module-2", models.IssueIDMismatch},
+		{"module-2", models.IssueDuplicateID},
+		{"module-3", models.IssueDuplicateID},
+		{"module-4", models.IssueInvalidJSON},
+	}
+	for _, want := range expected {
+		if !hasIssue(report, want.module, want.code) {
+			t.Errorf("Expected %s issue for %s, got %+v", want.code, want.module, report.Issues)
+		}
+	}
+}
+
+func TestPrerequisiteModuleID(t *testing.T) {
+	cases := map[string]string{
+		"module-2": "module-2",
+		"Module 1: Environment Setup & Node.js Basics": "module-1",
+		"Basic JavaScript knowledge":                   "",
+	}
+	for prerequisite, want := range cases {
+		got, _ := prerequisiteModuleID(prerequisite)
+		if got != want {
+			t.Errorf("prerequisiteModuleID(%q) = %q, want %q", prerequisite, got, want)
+		}
+	}
+}
+
+func TestModuleValidator_ReportsPrerequisiteCycle(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for id, prerequisites := range map[string]string{
+		"module-1": `["Basic knowledge"]`,
+		"module-2": `["module-4"]`,
+		"module-3": `["module-2"]`,
+		"module-4": `["module-3", "module-1"]`,
+	} {
+		fsys[id+"/module.json"] = &fstest.MapFile{Data: []byte(`{
+			"id": "` + id + `",
+			"title": "Test Module",
+			"description": "A test module",
+			"difficulty": "Beginner",
+			"estimatedTime": "30 minutes",
+			"files": {"lab": {}, "exercise": {"test": "exercise/test.js"}},
+			"prerequisites": ` + prerequisites + `
+		}`)}
+		fsys[id+"/exercise/test.js"] = &fstest.MapFile{Data: []byte("// Test")}
+	}
+
+	validator, _ := NewModuleValidator()
+	report, err := validator.Validate(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.OK {
+		t.Fatal("Expected validation to fail")
+	}
+	for _, module := range []string{"module-2", "module-3", "module-4"} {
+		if !hasIssue(report, module, models.IssuePrerequisiteCycle) {
+			t.Errorf("Expected a cycle issue for %s, got %+v", module, report.Issues)
+		}
+	}
+	if hasIssue(report, "module-1", models.IssuePrerequisiteCycle) {
+		t.Errorf("Expected no cycle issue for module-1, got %+v", report.Issues)
+	}
+}
+
+func TestModuleValidator_ChecksSolutionPolicy(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for id, policy := range map[string]string{
+		"module-1": `{"release": "time", "after": "1h30m"}`,
+		"module-2": `{"release": "attempts"}`,
+		"module-3": `{"release": "attempts", "attempts": 2.5}`,
+		"module-4": `{"release": "later"}`,
+	} {
+		fsys[id+"/module.json"] = &fstest.MapFile{Data: []byte(validModuleJSON(id, `,
+		"solutionPolicy": `+policy))}
+		fsys[id+"/lab/README.md"] = &fstest.MapFile{Data: []byte("# Lab")}
+		fsys[id+"/exercise/server.js"] = &fstest.MapFile{Data: []byte("// Server")}
+		fsys[id+"/exercise/test.js"] = &fstest.MapFile{Data: []byte("// Test")}
+	}
+
+	validator, _ := NewModuleValidator()
+	report, err := validator.Validate(fsys)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Module == "module-1" {
+			t.Errorf("Expected module-1 to be valid, got %+v", issue)
+		}
+	}
+	expected := []struct{ module, code string }{
+		{"module-2", models.IssueMissingField},
+		{"module-3", models.IssueInvalidValue},
+		{"module-4", models.IssueInvalidValue},
+	}
+	for _, want := range expected {
+		if !hasIssue(report, want.module, want.code) {
+			t.Errorf("Expected %s issue for %s, got %+v", want.code, want.module, report.Issues)
+		}
+	}
+}
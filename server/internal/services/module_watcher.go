@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// watchDebounce groups bursts of filesystem events (editors often write a
+// file several times when saving) into a single reload
+const watchDebounce = 300 * time.Millisecond
+
+// isTransientModuleFile reports whether a change to this file should be
+// ignored, e.g. code written by the test runner, editor swap files or
+// modules being staged for import
+func isTransientModuleFile(name string) bool {
+	if strings.Contains(filepath.ToSlash(name), "/"+importStagingPrefix) {
+		return true
+	}
+	base := filepath.Base(name)
+	switch base {
+	case "tmp-server.js", mochaReporterFile, ".DS_Store":
+		return true
+	}
+	return strings.HasSuffix(base, "~") ||
+		strings.HasSuffix(base, ".swp") ||
+		strings.HasSuffix(base, ".swx") ||
+		strings.HasSuffix(base, ".tmp")
+}
+
+// Watch reloads the catalog whenever module content changes on disk.
+// It returns a function that stops watching. Only disk-backed content
+// sources can be watched.
+func (s *ModuleService) Watch() (func(), error) {
+	modulesPath, ok := s.source.Dir()
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be watched", s.source)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	if err := addWatchDirs(watcher, modulesPath); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go s.watchLoop(watcher, done)
+
+	logrus.Infof("Watching %s for module changes", modulesPath)
+	return func() {
+		close(done)
+		watcher.Close()
+	}, nil
+}
+
+func (s *ModuleService) watchLoop(watcher *fsnotify.Watcher, done <-chan struct{}) {
+	reload := time.NewTimer(watchDebounce)
+	reload.Stop()
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if isTransientModuleFile(event.Name) {
+				continue
+			}
+
+			// New directories need their own watch since fsnotify isn't recursive
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchDirs(watcher, event.Name); err != nil {
+						logrus.Warnf("Failed to watch %s: %v", event.Name, err)
+					}
+				}
+			}
+
+			reload.Reset(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logrus.Warnf("Module watcher error: %v", err)
+		case <-reload.C:
+			if _, err := s.Reload(); err != nil {
+				logrus.Errorf("Failed to reload modules: %v", err)
+			}
+		}
+	}
+}
+
+// addWatchDirs watches root and every directory below it
+func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if entry.Name() == "node_modules" {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ProgressService records users' graded test runs and sums them up per
+// module
+type ProgressService struct {
+	moduleService ModuleServiceInterface
+	store         ProgressStore
+}
+
+// NewProgressService creates a progress service backed by store
+func NewProgressService(moduleService ModuleServiceInterface, store ProgressStore) *ProgressService {
+	return &ProgressService{
+		moduleService: moduleService,
+		store:         store,
+	}
+}
+
+// RecordResult records a test run of a user. An empty exercise ID selects
+// the module's first exercise.
+func (s *ProgressService) RecordResult(userId, moduleId, exerciseId string, result *models.TestSuiteResult) (*models.ExerciseProgress, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	exercise, _, err := findExercise(*module, exerciseId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.RecordAttempt(models.ProgressAttempt{
+		UserID:      userId,
+		ModuleID:    moduleId,
+		ExerciseID:  exercise.ID,
+		TestsPassed: result.PassedTests,
+		TotalTests:  result.TotalTests,
+		Passed:      result.Passed(),
+		At:          time.Now(),
+	})
+}
+
+// moduleProgress sums up a user's progress on a module. progress holds the
+// user's exercise progress by exercise ID; exercises that were removed from
+// the module since are left out.
+func moduleProgress(module models.Module, progress map[string]models.ExerciseProgress) models.ModuleProgress {
+	exercises := module.ExerciseList()
+	summary := models.ModuleProgress{
+		ModuleID:       module.ID,
+		Title:          module.Title,
+		Status:         models.ProgressNotStarted,
+		TotalExercises: len(exercises),
+		Exercises:      []models.ExerciseProgress{},
+	}
+
+	scores := 0
+	for _, exercise := range exercises {
+		p, ok := progress[exercise.ID]
+		if !ok {
+			continue
+		}
+		summary.Exercises = append(summary.Exercises, p)
+		summary.Attempts += p.Attempts
+		summary.TestsPassed += p.TestsPassed
+		summary.TotalTests += p.TotalTests
+		scores += p.BestScore
+		summary.StartedAt = earliest(summary.StartedAt, p.FirstAttemptAt)
+		summary.LastAttemptAt = latest(summary.LastAttemptAt, p.LastAttemptAt)
+		if p.CompletedAt != nil {
+			summary.CompletedExercises++
+			summary.CompletedAt = latest(summary.CompletedAt, p.CompletedAt)
+		}
+	}
+
+	if len(summary.Exercises) > 0 {
+		summary.Status = models.ProgressInProgress
+		summary.BestScore = scores / len(exercises)
+	}
+	if summary.CompletedExercises == len(exercises) {
+		summary.Status = models.ProgressCompleted
+	} else {
+		summary.CompletedAt = nil
+	}
+	return summary
+}
+
+func earliest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
+
+func latest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+// GetDashboard returns a user's progress on every published module, in
+// catalog order
+func (s *ProgressService) GetDashboard(userId string) (*models.ProgressDashboard, error) {
+	modules, err := s.moduleService.GetAllModules()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.store.UserProgress(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	byModule := make(map[string]map[string]models.ExerciseProgress)
+	for _, p := range stored {
+		if byModule[p.ModuleID] == nil {
+			byModule[p.ModuleID] = make(map[string]models.ExerciseProgress)
+		}
+		byModule[p.ModuleID][p.ExerciseID] = p
+	}
+
+	dashboard := &models.ProgressDashboard{UserID: userId, Modules: []models.ModuleProgress{}}
+	for _, module := range modules {
+		if module.IsDraft() {
+			continue
+		}
+		summary := moduleProgress(module, byModule[module.ID])
+		dashboard.TotalModules++
+		switch summary.Status {
+		case models.ProgressCompleted:
+			dashboard.CompletedModules++
+		case models.ProgressInProgress:
+			dashboard.StartedModules++
+		}
+		dashboard.Modules = append(dashboard.Modules, summary)
+	}
+	return dashboard, nil
+}
+
+// GetModuleProgress returns a user's progress on one module
+func (s *ProgressService) GetModuleProgress(userId, moduleId string) (*models.ModuleProgress, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.store.UserProgress(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[string]models.ExerciseProgress)
+	for _, p := range stored {
+		if p.ModuleID == moduleId {
+			progress[p.ExerciseID] = p
+		}
+	}
+	summary := moduleProgress(*module, progress)
+	return &summary, nil
+}
+
+// GetCompletionSummary counts how many users started and completed a module
+// and each of its exercises
+func (s *ProgressService) GetCompletionSummary(moduleId string) (*models.ModuleCompletionSummary, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.store.ModuleProgress(moduleId)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string]map[string]models.ExerciseProgress)
+	for _, p := range stored {
+		if byUser[p.UserID] == nil {
+			byUser[p.UserID] = make(map[string]models.ExerciseProgress)
+		}
+		byUser[p.UserID][p.ExerciseID] = p
+	}
+
+	summary := &models.ModuleCompletionSummary{ModuleID: moduleId, Exercises: []models.ExerciseCompletion{}}
+	for _, progress := range byUser {
+		userSummary := moduleProgress(*module, progress)
+		if userSummary.Status == models.ProgressNotStarted {
+			continue
+		}
+		summary.Started++
+		if userSummary.Status == models.ProgressCompleted {
+			summary.Completed++
+		}
+	}
+	if summary.Started > 0 {
+		summary.CompletionRate = float64(summary.Completed) / float64(summary.Started)
+	}
+
+	for _, exercise := range module.ExerciseList() {
+		completion := models.ExerciseCompletion{ExerciseID: exercise.ID}
+		attempts, scores := 0, 0
+		for _, progress := range byUser {
+			p, ok := progress[exercise.ID]
+			if !ok {
+				continue
+			}
+			completion.Started++
+			attempts += p.Attempts
+			scores += p.BestScore
+			if p.CompletedAt != nil {
+				completion.Completed++
+			}
+		}
+		if completion.Started > 0 {
+			completion.AverageAttempts = float64(attempts) / float64(completion.Started)
+			completion.AverageBestScore = float64(scores) / float64(completion.Started)
+		}
+		summary.Exercises = append(summary.Exercises, completion)
+	}
+	return summary, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ProgressStore persists what users have achieved on each exercise
+type ProgressStore interface {
+	// RecordAttempt adds a graded test run to the user's progress on the
+	// exercise, keeping the best score, and returns the updated progress
+	RecordAttempt(attempt models.ProgressAttempt) (*models.ExerciseProgress, error)
+	// UserProgress returns every exercise a user has attempted
+	UserProgress(userId string) ([]models.ExerciseProgress, error)
+	// ModuleProgress returns every user's progress on a module's exercises
+	ModuleProgress(moduleId string) ([]models.ExerciseProgress, error)
+}
+
+// progressSchema creates the progress table. Times are stored as RFC 3339
+// text in UTC so they sort and compare as strings.
+const progressSchema = `
+CREATE TABLE IF NOT EXISTS exercise_progress (
+	user_id          TEXT    NOT NULL,
+	module_id        TEXT    NOT NULL,
+	exercise_id      TEXT    NOT NULL,
+	attempts         INTEGER NOT NULL,
+	best_score       INTEGER NOT NULL,
+	tests_passed     INTEGER NOT NULL,
+	total_tests      INTEGER NOT NULL,
+	first_attempt_at TEXT    NOT NULL,
+	last_attempt_at  TEXT    NOT NULL,
+	completed_at     TEXT,
+	PRIMARY KEY (user_id, module_id, exercise_id)
+);
+CREATE INDEX IF NOT EXISTS exercise_progress_module ON exercise_progress (module_id);
+`
+
+// recordAttemptSQL inserts a first attempt or folds another one into the
+// existing row. Counts are replaced only when the score improves.
+const recordAttemptSQL = `
+INSERT INTO exercise_progress (user_id, module_id, exercise_id, attempts, best_score, tests_passed, total_tests, first_attempt_at, last_attempt_at, completed_at)
+VALUES (?, ?, ?, 1, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, module_id, exercise_id) DO UPDATE SET
+	attempts        = attempts + 1,
+	tests_passed    = CASE WHEN excluded.best_score > best_score THEN excluded.tests_passed ELSE tests_passed END,
+	total_tests     = CASE WHEN excluded.best_score > best_score THEN excluded.total_tests ELSE total_tests END,
+	best_score      = MAX(best_score, excluded.best_score),
+	last_attempt_at = excluded.last_attempt_at,
+	completed_at    = COALESCE(completed_at, excluded.completed_at)
+`
+
+const progressColumns = `user_id, module_id, exercise_id, attempts, best_score, tests_passed, total_tests, first_attempt_at, last_attempt_at, completed_at`
+
+// SQLiteProgressStore keeps progress in a SQLite database
+type SQLiteProgressStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteProgressStore keeps progress in db, creating its table if needed
+func NewSQLiteProgressStore(db *sql.DB) (*SQLiteProgressStore, error) {
+	if _, err := db.Exec(progressSchema); err != nil {
+		return nil, fmt.Errorf("failed to create progress tables: %w", err)
+	}
+	return &SQLiteProgressStore{db: db}, nil
+}
+
+// progressScore is the percentage of tests passed, rounded down
+func progressScore(passed, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return passed * 100 / total
+}
+
+// RecordAttempt adds a graded test run to a user's progress
+func (s *SQLiteProgressStore) RecordAttempt(attempt models.ProgressAttempt) (*models.ExerciseProgress, error) {
+	at := formatSQLiteTime(attempt.At)
+	var completedAt interface{}
+	if attempt.Passed {
+		completedAt = at
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(recordAttemptSQL,
+		attempt.UserID, attempt.ModuleID, attempt.ExerciseID,
+		progressScore(attempt.TestsPassed, attempt.TotalTests), attempt.TestsPassed, attempt.TotalTests,
+		at, at, completedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to record attempt: %w", err)
+	}
+
+	row := tx.QueryRow(`SELECT `+progressColumns+` FROM exercise_progress WHERE user_id = ? AND module_id = ? AND exercise_id = ?`,
+		attempt.UserID, attempt.ModuleID, attempt.ExerciseID)
+	progress, err := scanProgress(row)
+	if err != nil {
+		return nil, err
+	}
+	return progress, tx.Commit()
+}
+
+// UserProgress returns every exercise a user has attempted
+func (s *SQLiteProgressStore) UserProgress(userId string) ([]models.ExerciseProgress, error) {
+	return s.query(`SELECT `+progressColumns+` FROM exercise_progress WHERE user_id = ? ORDER BY module_id, exercise_id`, userId)
+}
+
+// ModuleProgress returns every user's progress on a module's exercises
+func (s *SQLiteProgressStore) ModuleProgress(moduleId string) ([]models.ExerciseProgress, error) {
+	return s.query(`SELECT `+progressColumns+` FROM exercise_progress WHERE module_id = ? ORDER BY user_id, exercise_id`, moduleId)
+}
+
+func (s *SQLiteProgressStore) query(query string, args ...interface{}) ([]models.ExerciseProgress, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load progress: %w", err)
+	}
+	defer rows.Close()
+
+	progress := []models.ExerciseProgress{}
+	for rows.Next() {
+		p, err := scanProgress(rows)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, *p)
+	}
+	return progress, rows.Err()
+}
+
+// scanProgress reads a row selected with progressColumns
+func scanProgress(row interface{ Scan(...interface{}) error }) (*models.ExerciseProgress, error) {
+	var p models.ExerciseProgress
+	var firstAt, lastAt string
+	var completedAt sql.NullString
+	if err := row.Scan(&p.UserID, &p.ModuleID, &p.ExerciseID, &p.Attempts, &p.BestScore, &p.TestsPassed, &p.TotalTests,
+		&firstAt, &lastAt, &completedAt); err != nil {
+		return nil, fmt.Errorf("failed to read progress: %w", err)
+	}
+
+	times := []struct {
+		text   string
+		target **time.Time
+	}{
+		{firstAt, &p.FirstAttemptAt},
+		{lastAt, &p.LastAttemptAt},
+		{completedAt.String, &p.CompletedAt},
+	}
+	for _, t := range times {
+		if t.text == "" {
+			continue
+		}
+		parsed, err := parseSQLiteTime(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read progress: %w", err)
+		}
+		*t.target = &parsed
+	}
+
+	p.Status = models.ProgressInProgress
+	if p.CompletedAt != nil {
+		p.Status = models.ProgressCompleted
+	}
+	return &p, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// openTestDB opens a SQLite database that is closed when the test ends
+func openTestDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLiteProgressStore_RecordAttempt(t *testing.T) {
+	store, err := NewSQLiteProgressStore(openTestDB(t, filepath.Join(t.TempDir(), "data", "progress.db")))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	attempts := []models.ProgressAttempt{
+		{TestsPassed: 2, TotalTests: 4},
+		{TestsPassed: 3, TotalTests: 4},
+		{TestsPassed: 1, TotalTests: 4},
+		{TestsPassed: 4, TotalTests: 4, Passed: true},
+		{TestsPassed: 0, TotalTests: 4},
+	}
+	var progress *models.ExerciseProgress
+	for i, attempt := range attempts {
+		attempt.UserID, attempt.ModuleID, attempt.ExerciseID = "user-1", "module-1", "exercise"
+		attempt.At = start.Add(time.Duration(i) * time.Minute)
+		if progress, err = store.RecordAttempt(attempt); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if i == 2 && (progress.BestScore != 75 || progress.TestsPassed != 3 || progress.Status != models.ProgressInProgress) {
+			t.Errorf("Expected a worse attempt to keep the best score, got %+v", progress)
+		}
+	}
+
+	if progress.Attempts != 5 || progress.BestScore != 100 || progress.TestsPassed != 4 || progress.Status != models.ProgressCompleted {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+	if !progress.FirstAttemptAt.Equal(start) || !progress.LastAttemptAt.Equal(start.Add(4*time.Minute)) || !progress.CompletedAt.Equal(start.Add(3*time.Minute)) {
+		t.Errorf("Unexpected timestamps: %v %v %v", progress.FirstAttemptAt, progress.LastAttemptAt, progress.CompletedAt)
+	}
+
+	stored, err := store.UserProgress("user-1")
+	if err != nil || len(stored) != 1 || stored[0].Attempts != 5 {
+		t.Errorf("Expected the stored progress, got %+v, %v", stored, err)
+	}
+	if stored, _ := store.UserProgress("user-2"); len(stored) != 0 {
+		t.Errorf("Expected no progress for another user, got %+v", stored)
+	}
+}
+
+func TestProgressService(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	store, err := NewSQLiteProgressStore(openTestDB(t, ":memory:"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := NewProgressService(NewModuleServiceWithPath(modulesDir), store)
+
+	passed := &models.TestSuiteResult{TotalTests: 2, PassedTests: 2}
+	failed := &models.TestSuiteResult{TotalTests: 2, PassedTests: 1, FailedTests: 1}
+
+	if _, err := service.RecordResult("user-1", "module-3", "", passed); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service.RecordResult("user-1", "module-3", "sessions", failed)
+	service.RecordResult("user-2", "module-3", "hashing", passed)
+	service.RecordResult("user-2", "module-3", "sessions", passed)
+
+	if _, err := service.RecordResult("user-1", "module-3", "missing", passed); err == nil {
+		t.Error("Expected an error for an unknown exercise")
+	}
+
+	dashboard, err := service.GetDashboard("user-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dashboard.TotalModules != 1 || dashboard.StartedModules != 1 || dashboard.CompletedModules != 0 {
+		t.Errorf("Unexpected dashboard counts: %+v", dashboard)
+	}
+	module := dashboard.Modules[0]
+	if module.Status != models.ProgressInProgress || module.CompletedExercises != 1 || module.BestScore != 75 || module.CompletedAt != nil {
+		t.Errorf("Unexpected module progress: %+v", module)
+	}
+
+	progress, err := service.GetModuleProgress("user-2", "module-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if progress.Status != models.ProgressCompleted || progress.CompletedAt == nil || progress.Attempts != 2 {
+		t.Errorf("Expected user-2 to have completed the module, got %+v", progress)
+	}
+
+	summary, err := service.GetCompletionSummary("module-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if summary.Started != 2 || summary.Completed != 1 || summary.CompletionRate != 0.5 {
+		t.Errorf("Unexpected completion summary: %+v", summary)
+	}
+	if len(summary.Exercises) != 2 || summary.Exercises[1].Completed != 1 || summary.Exercises[1].AverageBestScore != 75 {
+		t.Errorf("Unexpected exercise completion: %+v", summary.Exercises)
+	}
+}
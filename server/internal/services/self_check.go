@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ErrModulesNotFound is returned when a requested module does not exist
+var ErrModulesNotFound = errors.New("modules not found")
+
+// SelfCheckService verifies that every module's solution passes its tests
+// and that the starter code handed to students does not
+type SelfCheckService struct {
+	moduleService ModuleServiceInterface
+	testRunner    TestRunnerInterface
+}
+
+// NewSelfCheckService creates a self-check service using the given executor
+func NewSelfCheckService(moduleService ModuleServiceInterface, testRunner TestRunnerInterface) *SelfCheckService {
+	return &SelfCheckService{
+		moduleService: moduleService,
+		testRunner:    testRunner,
+	}
+}
+
+// Run checks the given modules, or every module when moduleIds is empty.
+// Modules are run one at a time because runners share the exercise
+// directory and port.
+func (s *SelfCheckService) Run(moduleIds []string) (*models.SelfCheckReport, error) {
+	startTime := time.Now()
+
+	modules, err := s.moduleService.GetAllModules()
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the selection up front so a typo fails before anything runs
+	known := make(map[string]bool, len(modules))
+	for _, module := range modules {
+		known[module.ID] = true
+	}
+
+	wanted := make(map[string]bool, len(moduleIds))
+	var missing []string
+	for _, id := range moduleIds {
+		wanted[id] = true
+		if !known[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: %s", ErrModulesNotFound, strings.Join(missing, ", "))
+	}
+
+	report := &models.SelfCheckReport{Modules: []models.SelfCheckModuleResult{}}
+	for _, module := range modules {
+		if len(wanted) > 0 && !wanted[module.ID] {
+			continue
+		}
+
+		result := s.checkModule(module)
+		if !result.OK {
+			report.Regressions++
+		}
+		report.Modules = append(report.Modules, result)
+	}
+
+	report.OK = report.Regressions == 0
+	report.Duration = time.Since(startTime).Milliseconds()
+	return report, nil
+}
+
+// checkModule runs the solution and the starter code of every exercise in
+// a module
+func (s *SelfCheckService) checkModule(module models.Module) models.SelfCheckModuleResult {
+	result := models.SelfCheckModuleResult{
+		ModuleID:  module.ID,
+		Title:     module.Title,
+		Exercises: []models.SelfCheckExerciseResult{},
+		OK:        true,
+	}
+
+	for _, exercise := range module.ExerciseList() {
+		checked := s.checkExercise(module.ID, exercise)
+		result.OK = result.OK && checked.OK
+		result.Exercises = append(result.Exercises, checked)
+	}
+	return result
+}
+
+// checkExercise runs the solution and the starter code for one exercise
+func (s *SelfCheckService) checkExercise(moduleId string, exercise models.Exercise) models.SelfCheckExerciseResult {
+	result := models.SelfCheckExerciseResult{
+		ExerciseID: exercise.ID,
+		Title:      exercise.Title,
+		Solution:   models.SelfCheckRun{Expected: models.OutcomePass, Actual: models.OutcomeFail},
+		Starter:    models.SelfCheckRun{Expected: models.OutcomeFail, Actual: models.OutcomeFail},
+	}
+
+	step, err := s.moduleService.GetModuleExercise(moduleId, exercise.ID)
+	if err != nil {
+		message := fmt.Sprintf("Failed to load exercise content: %s", err.Error())
+		result.Solution.Error = &message
+		result.Starter.Error = &message
+		return result
+	}
+
+	result.Solution = s.runVariant(moduleId, exercise.ID, step.ExerciseContent.Solution, models.OutcomePass)
+	result.Starter = s.runVariant(moduleId, exercise.ID, step.ExerciseContent.EditorFiles.Server, models.OutcomeFail)
+	result.OK = result.Solution.Matches() && result.Starter.Matches()
+	return result
+}
+
+// runVariant runs an exercise's tests against code and records the outcome
+func (s *SelfCheckService) runVariant(moduleId, exerciseId, code, expected string) models.SelfCheckRun {
+	run := models.SelfCheckRun{Expected: expected, Actual: models.OutcomeFail}
+
+	if strings.TrimSpace(code) == "" {
+		message := "No code to run"
+		run.Error = &message
+		return run
+	}
+
+	suite, err := s.testRunner.RunExerciseTests(moduleId, exerciseId, code)
+	if err != nil {
+		message := err.Error()
+		run.Error = &message
+		return run
+	}
+
+	run.Status = suite.Status
+	run.TotalTests = suite.TotalTests
+	run.PassedTests = suite.PassedTests
+	run.FailedTests = suite.FailedTests
+
+	// A pass needs at least one test and nothing failing, timed out or skipped
+	if suite.Passed() {
+		run.Actual = models.OutcomePass
+	}
+
+	return run
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// scriptedTestRunner passes code listed in passing and fails everything else
+type scriptedTestRunner struct {
+	passing   map[string]bool
+	exercises []string // the exercises whose tests were run, in order
+}
+
+func (r *scriptedTestRunner) RunCode(moduleId, inputCode string) (*models.RunResult, error) {
+	return &models.RunResult{ModuleID: moduleId, Success: true}, nil
+}
+
+func (r *scriptedTestRunner) RunTests(moduleId, inputCode string) (*models.TestSuiteResult, error) {
+	if r.passing[inputCode] {
+		return &models.TestSuiteResult{ModuleID: moduleId, Status: models.SuiteStatusCompleted, TotalTests: 2, PassedTests: 2}, nil
+	}
+	return &models.TestSuiteResult{ModuleID: moduleId, Status: models.SuiteStatusCompleted, TotalTests: 2, PassedTests: 1, FailedTests: 1}, nil
+}
+
+func (r *scriptedTestRunner) RunExerciseCode(moduleId, exerciseId, inputCode string) (*models.RunResult, error) {
+	return r.RunCode(moduleId, inputCode)
+}
+
+func (r *scriptedTestRunner) RunExerciseTests(moduleId, exerciseId, inputCode string) (*models.TestSuiteResult, error) {
+	r.exercises = append(r.exercises, exerciseId)
+	return r.RunTests(moduleId, inputCode)
+}
+
+func writeSelfCheckModule(t *testing.T, modulesDir, moduleId, server, solution string) {
+	t.Helper()
+	moduleDir := filepath.Join(modulesDir, moduleId)
+	os.MkdirAll(filepath.Join(moduleDir, "exercise"), 0755)
+	moduleJSON := `{
+		"id": "` + moduleId + `",
+		"title": "Test Module",
+		"files": {
+			"lab": {},
+			"exercise": {"server": "exercise/server.js", "solution": "exercise/solution.js"}
+		}
+	}`
+	os.WriteFile(filepath.Join(moduleDir, "module.json"), []byte(moduleJSON), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "exercise", "server.js"), []byte(server), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "exercise", "solution.js"), []byte(solution), 0644)
+}
+
+func TestSelfCheckService_Run(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSelfCheckModule(t, modulesDir, "module-1", "// starter 1", "// solution 1")
+	writeSelfCheckModule(t, modulesDir, "module-2", "// starter 2", "// solution 2")
+
+	// module-2's solution is broken and its starter already passes
+	runner := &scriptedTestRunner{passing: map[string]bool{
+		"// solution 1": true,
+		"// starter 2":  true,
+	}}
+	service := NewSelfCheckService(NewModuleServiceWithPath(modulesDir), runner)
+
+	report, err := service.Run(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(report.Modules) != 2 {
+		t.Fatalf("Expected 2 modules, got %d", len(report.Modules))
+	}
+	if report.OK || report.Regressions != 1 {
+		t.Errorf("Expected 1 regression, got ok=%v regressions=%d", report.OK, report.Regressions)
+	}
+
+	first, second := report.Modules[0], report.Modules[1]
+	if len(first.Exercises) != 1 || len(second.Exercises) != 1 {
+		t.Fatalf("Expected one exercise per module, got %+v and %+v", first, second)
+	}
+	if exercise := first.Exercises[0]; !first.OK || exercise.ExerciseID != models.DefaultExerciseID ||
+		exercise.Solution.Actual != models.OutcomePass || exercise.Starter.Actual != models.OutcomeFail {
+		t.Errorf("Expected module-1 to match expectations, got %+v", first)
+	}
+	if exercise := second.Exercises[0]; second.OK || exercise.Solution.Actual != models.OutcomeFail || exercise.Starter.Actual != models.OutcomePass {
+		t.Errorf("Expected module-2 to be a regression, got %+v", second)
+	}
+}
+
+func TestSelfCheckService_RunSteppedModule(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	moduleJSON := strings.NewReplacer(
+		`"server": "exercises/hashing/server.js",`, `"server": "exercises/hashing/server.js", "solution": "exercises/hashing/solution.js",`,
+		`"server": "exercises/sessions/server.js",`, `"server": "exercises/sessions/server.js", "solution": "exercises/sessions/solution.js",`,
+	).Replace(steppedModuleJSON)
+	moduleDir := filepath.Join(modulesDir, "module-3")
+	os.WriteFile(filepath.Join(moduleDir, "module.json"), []byte(moduleJSON), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "exercises", "hashing", "solution.js"), []byte("// hashing solution"), 0644)
+	os.WriteFile(filepath.Join(moduleDir, "exercises", "sessions", "solution.js"), []byte("// sessions solution"), 0644)
+
+	// The second step's starter already passes its tests
+	runner := &scriptedTestRunner{passing: map[string]bool{
+		"// hashing solution":  true,
+		"// sessions solution": true,
+		"// sessions starter":  true,
+	}}
+	report, err := NewSelfCheckService(NewModuleServiceWithPath(modulesDir), runner).Run(nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Modules) != 1 || len(report.Modules[0].Exercises) != 2 {
+		t.Fatalf("Expected both exercises to be checked, got %+v", report.Modules)
+	}
+
+	hashing, sessions := report.Modules[0].Exercises[0], report.Modules[0].Exercises[1]
+	if hashing.ExerciseID != "hashing" || !hashing.OK || hashing.Solution.Error != nil {
+		t.Errorf("Expected the first step to match expectations, got %+v", hashing)
+	}
+	if sessions.ExerciseID != "sessions" || sessions.OK || sessions.Solution.Actual != models.OutcomePass || sessions.Starter.Actual != models.OutcomePass {
+		t.Errorf("Expected the second step to be a regression, got %+v", sessions)
+	}
+	if report.OK || report.Regressions != 1 {
+		t.Errorf("Expected 1 regression, got ok=%v regressions=%d", report.OK, report.Regressions)
+	}
+	if !reflect.DeepEqual(runner.exercises, []string{"hashing", "hashing", "sessions", "sessions"}) {
+		t.Errorf("Expected each step's own tests to run, got %v", runner.exercises)
+	}
+}
+
+func TestSelfCheckService_RunUnknownModule(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSelfCheckModule(t, modulesDir, "module-1", "// starter", "// solution")
+
+	service := NewSelfCheckService(NewModuleServiceWithPath(modulesDir), &scriptedTestRunner{})
+
+	_, err := service.Run([]string{"module-1", "module-9"})
+	if !errors.Is(err, ErrModulesNotFound) {
+		t.Fatalf("Expected ErrModulesNotFound, got %v", err)
+	}
+}
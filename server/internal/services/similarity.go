@@ -0,0 +1,365 @@
+package services
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// DefaultSimilarityThreshold is the similarity from which submissions are
+// reported when no threshold is given
+const DefaultSimilarityThreshold = 0.8
+
+// Winnowing parameters. Runs of tokens shorter than similarityKGram are
+// ignored as noise, while every shared run of at least
+// similarityKGram+similarityWindow-1 tokens is found.
+const (
+	similarityKGram  = 5
+	similarityWindow = 4
+)
+
+// jsKeywords are kept when code is normalized; every other identifier is
+// replaced so renaming variables doesn't hide copied code
+var jsKeywords = map[string]bool{
+	"async": true, "await": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "export": true, "extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "import": true, "in": true, "instanceof": true, "let": true,
+	"new": true, "null": true, "of": true, "return": true, "static": true, "super": true,
+	"switch": true, "this": true, "throw": true, "true": true, "try": true, "typeof": true,
+	"undefined": true, "var": true, "void": true, "while": true, "with": true, "yield": true,
+}
+
+// codeToken is a normalized JavaScript token and the line it starts on
+type codeToken struct {
+	text string
+	line int
+}
+
+// tokenizeJS splits JavaScript into normalized tokens, dropping whitespace
+// and comments. Identifiers become "id", numbers "0" and strings "str";
+// keywords and punctuation are kept. Regular expressions are not recognized,
+// which only makes their contents tokens like any other code.
+func tokenizeJS(code string) []codeToken {
+	var tokens []codeToken
+	line := 1
+	isIdent := func(c byte) bool {
+		return c == '_' || c == '$' || c >= 0x80 || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+	}
+
+	for i := 0; i < len(code); {
+		c := code[i]
+		start, startLine := i, line
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(code[i:], "//"):
+			for i < len(code) && code[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(code[i:], "/*"):
+			end := strings.Index(code[i+2:], "*/")
+			if end < 0 {
+				end = len(code) - i - 2
+			} else {
+				end += 2
+			}
+			line += strings.Count(code[i:i+2+end], "\n")
+			i += 2 + end
+		case c == '\'' || c == '"' || c == '`':
+			// Quoted strings end at a newline too, so an unterminated one
+			// doesn't swallow the rest of the code
+			for i++; i < len(code) && code[i] != c && (c == '`' || code[i] != '\n'); i++ {
+				if code[i] == '\\' {
+					i++
+				}
+			}
+			if i < len(code) {
+				i++
+			}
+			line += strings.Count(code[start:min(i, len(code))], "\n")
+			tokens = append(tokens, codeToken{"str", startLine})
+		case '0' <= c && c <= '9' || c == '.' && i+1 < len(code) && '0' <= code[i+1] && code[i+1] <= '9':
+			for i++; i < len(code) && (isIdent(code[i]) || code[i] == '.'); i++ {
+			}
+			tokens = append(tokens, codeToken{"0", startLine})
+		case isIdent(c):
+			for i++; i < len(code) && isIdent(code[i]); i++ {
+			}
+			word := code[start:i]
+			if !jsKeywords[word] {
+				word = "id"
+			}
+			tokens = append(tokens, codeToken{word, startLine})
+		default:
+			i++
+			tokens = append(tokens, codeToken{code[start:i], startLine})
+		}
+	}
+	return tokens
+}
+
+// codeFingerprints are the winnowed fingerprints of some code, with the
+// lines each one was taken from
+type codeFingerprints map[uint64][]models.LineRange
+
+// fingerprintJS normalizes JavaScript and selects fingerprints by
+// winnowing: of every similarityWindow consecutive k-gram hashes, the
+// smallest is kept
+func fingerprintJS(code string) codeFingerprints {
+	tokens := tokenizeJS(code)
+	fingerprints := codeFingerprints{}
+	if len(tokens) < similarityKGram {
+		return fingerprints
+	}
+
+	hashes := make([]uint64, len(tokens)-similarityKGram+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		for _, token := range tokens[i : i+similarityKGram] {
+			h.Write([]byte(token.text))
+			h.Write([]byte{0})
+		}
+		hashes[i] = h.Sum64()
+	}
+
+	selected := -1
+	for end := min(similarityWindow, len(hashes)); end <= len(hashes); end++ {
+		// The rightmost smallest hash is kept, so a window sliding on
+		// keeps selecting the same k-gram
+		smallest := end - 1
+		for i := end - 2; i >= max(end-similarityWindow, 0); i-- {
+			if hashes[i] < hashes[smallest] {
+				smallest = i
+			}
+		}
+		if smallest != selected {
+			selected = smallest
+			lines := models.LineRange{Start: tokens[smallest].line, End: tokens[smallest+similarityKGram-1].line}
+			fingerprints[hashes[smallest]] = append(fingerprints[hashes[smallest]], lines)
+		}
+	}
+	return fingerprints
+}
+
+// without removes the fingerprints of other, such as the starter code every
+// learner begins with
+func (f codeFingerprints) without(other codeFingerprints) codeFingerprints {
+	remaining := codeFingerprints{}
+	for hash, lines := range f {
+		if _, ok := other[hash]; !ok {
+			remaining[hash] = lines
+		}
+	}
+	return remaining
+}
+
+// shared counts the fingerprints f and other have in common
+func (f codeFingerprints) shared(other codeFingerprints) int {
+	count := 0
+	for hash := range f {
+		if _, ok := other[hash]; ok {
+			count++
+		}
+	}
+	return count
+}
+
+// matchedLines lists the lines of f's code that other shares, merging
+// overlapping and adjacent ranges
+func (f codeFingerprints) matchedLines(other codeFingerprints) []models.LineRange {
+	var ranges []models.LineRange
+	for hash, lines := range f {
+		if _, ok := other[hash]; ok {
+			ranges = append(ranges, lines...)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+
+	merged := []models.LineRange{}
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r.Start <= merged[last].End+1 {
+			merged[last].End = max(merged[last].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// jaccardSimilarity is the share of the fingerprints of two pieces of code
+// that both have
+func jaccardSimilarity(shared, a, b int) float64 {
+	if union := a + b - shared; union > 0 {
+		return float64(shared) / float64(union)
+	}
+	return 0
+}
+
+// fingerprintedSubmission is a submission with its own fingerprints
+type fingerprintedSubmission struct {
+	submission   models.Submission
+	fingerprints codeFingerprints
+}
+
+// SimilarityService looks for copied code among learners' submissions
+type SimilarityService struct {
+	moduleService ModuleServiceInterface
+	store         SubmissionStore
+	now           func() time.Time
+}
+
+// NewSimilarityService creates a similarity service comparing the
+// submissions in store
+func NewSimilarityService(moduleService ModuleServiceInterface, store SubmissionStore) *SimilarityService {
+	return &SimilarityService{
+		moduleService: moduleService,
+		store:         store,
+		now:           time.Now,
+	}
+}
+
+// Detect compares each learner's latest submission to every exercise of a
+// module, or only to exerciseId when it is set, with the other learners'
+// and the exercise's solution. Pairs at least threshold similar are
+// reported, most similar first; a threshold of 0 uses the default.
+// Fingerprints of the exercise's starter code are ignored, and submissions
+// that only contain starter code are not compared.
+func (s *SimilarityService) Detect(moduleId, exerciseId string, threshold float64) (*models.SimilarityReport, error) {
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	if exerciseId != "" {
+		if _, _, err := findExercise(*module, exerciseId); err != nil {
+			return nil, err
+		}
+	}
+
+	submissions, err := s.store.LatestSubmissions(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	byExercise := make(map[string][]models.Submission)
+	for _, submission := range submissions {
+		byExercise[submission.ExerciseID] = append(byExercise[submission.ExerciseID], submission)
+	}
+
+	report := &models.SimilarityReport{
+		ModuleID:        moduleId,
+		ExerciseID:      exerciseId,
+		Threshold:       threshold,
+		Pairs:           []models.SimilarPair{},
+		SolutionMatches: []models.SolutionMatch{},
+		GeneratedAt:     s.now().UTC(),
+	}
+	for _, exercise := range module.ExerciseList() {
+		if exerciseId != "" && exercise.ID != exerciseId || len(byExercise[exercise.ID]) == 0 {
+			continue
+		}
+		step, err := s.moduleService.GetModuleExercise(moduleId, exercise.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.compareExercise(report, step, byExercise[exercise.ID])
+	}
+
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		a, b := report.Pairs[i], report.Pairs[j]
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		if a.ExerciseID != b.ExerciseID {
+			return a.ExerciseID < b.ExerciseID
+		}
+		return a.First.SubmissionID+a.Second.SubmissionID < b.First.SubmissionID+b.Second.SubmissionID
+	})
+	sort.SliceStable(report.SolutionMatches, func(i, j int) bool {
+		return report.SolutionMatches[i].Similarity > report.SolutionMatches[j].Similarity
+	})
+	return report, nil
+}
+
+// compareExercise adds the matches among an exercise's submissions to report
+func (s *SimilarityService) compareExercise(report *models.SimilarityReport, step *models.ExerciseStep, submissions []models.Submission) {
+	starter := fingerprintJS(step.ExerciseContent.EditorFiles.Server)
+	solution := fingerprintJS(step.ExerciseContent.Solution).without(starter)
+
+	var fingerprinted []fingerprintedSubmission
+	for _, submission := range submissions {
+		fingerprints := fingerprintJS(submission.Code).without(starter)
+		if len(fingerprints) > 0 {
+			fingerprinted = append(fingerprinted, fingerprintedSubmission{submission, fingerprints})
+		}
+	}
+	report.Submissions += len(fingerprinted)
+
+	// Only pairs sharing a fingerprint can be similar, so they are found
+	// through the submissions holding each fingerprint
+	holders := make(map[uint64][]int)
+	for i, f := range fingerprinted {
+		for hash := range f.fingerprints {
+			holders[hash] = append(holders[hash], i)
+		}
+	}
+	shared := make(map[[2]int]int)
+	for _, indexes := range holders {
+		for a := 0; a < len(indexes); a++ {
+			for b := a + 1; b < len(indexes); b++ {
+				shared[[2]int{indexes[a], indexes[b]}]++
+			}
+		}
+	}
+
+	for pair, count := range shared {
+		first, second := fingerprinted[pair[0]], fingerprinted[pair[1]]
+		similarity := jaccardSimilarity(count, len(first.fingerprints), len(second.fingerprints))
+		if similarity < report.Threshold {
+			continue
+		}
+		report.Pairs = append(report.Pairs, models.SimilarPair{
+			ExerciseID:         step.ID,
+			Similarity:         similarity,
+			SharedFingerprints: count,
+			First:              similarSubmission(first, second.fingerprints),
+			Second:             similarSubmission(second, first.fingerprints),
+		})
+	}
+
+	if len(solution) == 0 {
+		return
+	}
+	for _, f := range fingerprinted {
+		count := f.fingerprints.shared(solution)
+		similarity := jaccardSimilarity(count, len(f.fingerprints), len(solution))
+		if count == 0 || similarity < report.Threshold {
+			continue
+		}
+		report.SolutionMatches = append(report.SolutionMatches, models.SolutionMatch{
+			ExerciseID:         step.ID,
+			Similarity:         similarity,
+			SharedFingerprints: count,
+			Submission:         similarSubmission(f, solution),
+			SolutionLines:      solution.matchedLines(f.fingerprints),
+		})
+	}
+}
+
+// similarSubmission describes a submission as one side of a match
+func similarSubmission(f fingerprintedSubmission, other codeFingerprints) models.SimilarSubmission {
+	return models.SimilarSubmission{
+		SubmissionID: f.submission.ID,
+		LearnerID:    f.submission.LearnerID,
+		SubmittedAt:  f.submission.SubmittedAt,
+		MatchedLines: f.fingerprints.matchedLines(other),
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+const similarityStarter = `const express = require('express');
+const app = express();
+app.use(express.json());
+
+// TODO: add the session routes
+
+module.exports = app;
+`
+
+const similaritySolution = `const express = require('express');
+const app = express();
+app.use(express.json());
+
+const sessions = new Map();
+
+app.post('/login', (req, res) => {
+  if (!req.body.user) {
+    return res.status(400).json({ error: 'user is required' });
+  }
+  const id = Math.random().toString(36).slice(2);
+  sessions.set(id, req.body.user);
+  res.cookie('sid', id, { httpOnly: true });
+  res.json({ ok: true });
+});
+
+module.exports = app;
+`
+
+// similarityOriginal is a learner's own take on the exercise
+const similarityOriginal = `const express = require('express');
+const app = express();
+app.use(express.json());
+
+const store = {};
+let counter = 0;
+
+app.post('/login', function (req, res) {
+  counter += 1;
+  store[counter] = { name: req.body.user, at: Date.now() };
+  res.set('Set-Cookie', 'session=' + counter);
+  for (const key of Object.keys(store)) {
+    if (Date.now() - store[key].at > 3600000) delete store[key];
+  }
+  res.send('welcome');
+});
+
+app.get('/me', function (req, res) {
+  const match = /session=(\d+)/.exec(req.headers.cookie || '');
+  res.send(match && store[match[1]] ? store[match[1]].name : 'nobody');
+});
+
+module.exports = app;
+`
+
+// similarityDisguised copies similarityOriginal with renamed variables,
+// reformatting and comments
+const similarityDisguised = `const express = require("express");
+const app = express();
+app.use(express.json());
+
+/* my session store */
+const db = {};
+let n = 0;
+
+app.post("/login", function (request, response) {
+  n += 1; // next id
+  db[n] = { name: request.body.user, at: Date.now() };
+  response.set("Set-Cookie", "session=" + n);
+  for (const k of Object.keys(db)) {
+    if (Date.now() - db[k].at > 3600000) delete db[k];
+  }
+  response.send("hi there");
+});
+
+app.get("/me", function (request, response) {
+  const m = /session=(\d+)/.exec(request.headers.cookie || "");
+  response.send(m && db[m[1]] ? db[m[1]].name : "nobody");
+});
+
+module.exports = app;
+`
+
+func TestTokenizeJS(t *testing.T) {
+	tokens := tokenizeJS("const total = add(1, 'two'); // sum\n/* multi\nline */ return `a\n${b}`;")
+	var texts []string
+	for _, token := range tokens {
+		texts = append(texts, token.text)
+	}
+	expected := []string{"const", "id", "=", "id", "(", "0", ",", "str", ")", ";", "return", "str", ";"}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("Expected %v, got %v", expected, texts)
+	}
+	if tokens[10].line != 3 || tokens[12].line != 4 {
+		t.Errorf("Expected tokens to keep their lines, got %+v", tokens)
+	}
+
+	if len(tokenizeJS("const s = 'unterminated\nlet x = 1;")) != 9 {
+		t.Error("Expected an unterminated string to end at the newline")
+	}
+}
+
+func TestFingerprintJS(t *testing.T) {
+	original := fingerprintJS(similarityOriginal)
+	disguised := fingerprintJS(similarityDisguised)
+	if len(original) == 0 {
+		t.Fatal("Expected fingerprints")
+	}
+	if similarity := jaccardSimilarity(original.shared(disguised), len(original), len(disguised)); similarity < 0.9 {
+		t.Errorf("Expected renaming and comments not to hide a copy, got similarity %v", similarity)
+	}
+	if similarity := jaccardSimilarity(original.shared(fingerprintJS(similaritySolution)), len(original), len(fingerprintJS(similaritySolution))); similarity > 0.5 {
+		t.Errorf("Expected different code to be dissimilar, got similarity %v", similarity)
+	}
+
+	lines := original.matchedLines(disguised)
+	if len(lines) == 0 || lines[0].Start != 1 || lines[len(lines)-1].End < 20 {
+		t.Errorf("Expected the copied lines to be matched, got %+v", lines)
+	}
+
+	if len(fingerprintJS("let x;")) != 0 {
+		t.Error("Expected no fingerprints for code shorter than a k-gram")
+	}
+}
+
+func TestSimilarityService_Detect(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	moduleJSON := strings.Replace(steppedModuleJSON, `"files": {"server": "exercises/sessions/server.js",`,
+		`"files": {"solution": "exercises/sessions/solution.js", "server": "exercises/sessions/server.js",`, 1)
+	sessionsDir := filepath.Join(modulesDir, "module-3", "exercises", "sessions")
+	os.WriteFile(filepath.Join(modulesDir, "module-3", "module.json"), []byte(moduleJSON), 0644)
+	os.WriteFile(filepath.Join(sessionsDir, "server.js"), []byte(similarityStarter), 0644)
+	os.WriteFile(filepath.Join(sessionsDir, "solution.js"), []byte(similaritySolution), 0644)
+
+	moduleService := NewModuleServiceWithPath(modulesDir)
+	store, err := NewSQLiteSubmissionStore(openTestDB(t, ":memory:"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	submissions := NewSubmissionService(moduleService, store)
+	record := func(learnerId, code string) *models.Submission {
+		submission, err := submissions.Record(learnerId, "module-3", "sessions", code, &models.TestSuiteResult{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return submission
+	}
+
+	// Only each learner's latest submission is compared
+	record("learner-2", similarityStarter)
+	original := record("learner-1", similarityOriginal)
+	disguised := record("learner-2", similarityDisguised)
+	record("learner-3", similarityStarter)
+	copied := record("learner-4", strings.ReplaceAll(similaritySolution, "sessions", "active"))
+	record("", similarityDisguised)
+
+	service := NewSimilarityService(moduleService, store)
+	report, err := service.Detect("module-3", "", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Threshold != DefaultSimilarityThreshold || report.Submissions != 3 {
+		t.Errorf("Expected the default threshold and learners' own code to be compared, got %+v", report)
+	}
+	if len(report.Pairs) != 1 {
+		t.Fatalf("Expected one similar pair, got %+v", report.Pairs)
+	}
+	pair := report.Pairs[0]
+	if pair.ExerciseID != "sessions" || pair.First.SubmissionID != original.ID || pair.Second.SubmissionID != disguised.ID ||
+		pair.Second.LearnerID != "learner-2" || pair.Similarity < DefaultSimilarityThreshold {
+		t.Errorf("Unexpected pair: %+v", pair)
+	}
+	if len(pair.First.MatchedLines) == 0 || pair.First.MatchedLines[0].Start > 5 {
+		t.Errorf("Expected the copied lines, got %+v", pair.First.MatchedLines)
+	}
+	if len(report.SolutionMatches) != 1 || report.SolutionMatches[0].Submission.SubmissionID != copied.ID ||
+		len(report.SolutionMatches[0].SolutionLines) == 0 {
+		t.Errorf("Expected the copied solution to match, got %+v", report.SolutionMatches)
+	}
+
+	report, err = service.Detect("module-3", "hashing", 0.1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Submissions != 0 || len(report.Pairs) != 0 {
+		t.Errorf("Expected no hashing submissions, got %+v", report)
+	}
+
+	if _, err := service.Detect("module-3", "missing", 0); !errors.Is(err, ErrExerciseNotFound) {
+		t.Errorf("Expected ErrExerciseNotFound, got %v", err)
+	}
+	if _, err := service.Detect("module-9", "", 0); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("Expected ErrModuleNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// SolutionStore persists what unlocks exercise solutions: learners' test runs
+// and instructor releases
+type SolutionStore interface {
+	// RecordAttempt counts a learner's test run on an exercise
+	RecordAttempt(learnerId, moduleId, exerciseId string, passed bool, at time.Time) error
+	// Attempts returns a learner's test runs on an exercise, which are zero
+	// when there were none
+	Attempts(learnerId, moduleId, exerciseId string) (models.SolutionAttempts, error)
+	// Released reports whether an exercise's solution was released to every learner
+	Released(moduleId, exerciseId string) (bool, error)
+	// SetReleased releases an exercise's solution to every learner, or
+	// withdraws the release
+	SetReleased(moduleId, exerciseId string, released bool, at time.Time) error
+}
+
+const solutionSchema = `
+CREATE TABLE IF NOT EXISTS solution_attempts (
+	learner_id       TEXT    NOT NULL,
+	module_id        TEXT    NOT NULL,
+	exercise_id      TEXT    NOT NULL,
+	attempts         INTEGER NOT NULL,
+	passed           INTEGER NOT NULL,
+	first_attempt_at TEXT    NOT NULL,
+	PRIMARY KEY (learner_id, module_id, exercise_id)
+);
+CREATE TABLE IF NOT EXISTS solution_releases (
+	module_id   TEXT NOT NULL,
+	exercise_id TEXT NOT NULL,
+	released_at TEXT NOT NULL,
+	PRIMARY KEY (module_id, exercise_id)
+);
+`
+
+// SQLiteSolutionStore keeps solution attempts and releases in a SQLite database
+type SQLiteSolutionStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteSolutionStore keeps solution attempts and releases in db,
+// creating its tables if needed
+func NewSQLiteSolutionStore(db *sql.DB) (*SQLiteSolutionStore, error) {
+	if _, err := db.Exec(solutionSchema); err != nil {
+		return nil, fmt.Errorf("failed to create solution tables: %w", err)
+	}
+	return &SQLiteSolutionStore{db: db}, nil
+}
+
+// RecordAttempt counts a learner's test run on an exercise
+func (s *SQLiteSolutionStore) RecordAttempt(learnerId, moduleId, exerciseId string, passed bool, at time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO solution_attempts (learner_id, module_id, exercise_id, attempts, passed, first_attempt_at) VALUES (?, ?, ?, 1, ?, ?)
+		ON CONFLICT (learner_id, module_id, exercise_id) DO UPDATE SET
+			attempts = attempts + 1,
+			passed   = MAX(passed, excluded.passed)`,
+		learnerId, moduleId, exerciseId, passed, formatSQLiteTime(at))
+	if err != nil {
+		return fmt.Errorf("failed to record attempt: %w", err)
+	}
+	return nil
+}
+
+// Attempts returns a learner's test runs on an exercise
+func (s *SQLiteSolutionStore) Attempts(learnerId, moduleId, exerciseId string) (models.SolutionAttempts, error) {
+	var attempts models.SolutionAttempts
+	var firstAt string
+	err := s.db.QueryRow(`SELECT attempts, passed, first_attempt_at FROM solution_attempts WHERE learner_id = ? AND module_id = ? AND exercise_id = ?`,
+		learnerId, moduleId, exerciseId).Scan(&attempts.Count, &attempts.Passed, &firstAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SolutionAttempts{}, nil
+	}
+	if err != nil {
+		return models.SolutionAttempts{}, fmt.Errorf("failed to load attempts: %w", err)
+	}
+	if attempts.FirstAt, err = parseSQLiteTime(firstAt); err != nil {
+		return models.SolutionAttempts{}, fmt.Errorf("failed to load attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// Released reports whether an exercise's solution was released to every learner
+func (s *SQLiteSolutionStore) Released(moduleId, exerciseId string) (bool, error) {
+	var released bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM solution_releases WHERE module_id = ? AND exercise_id = ?)`,
+		moduleId, exerciseId).Scan(&released)
+	if err != nil {
+		return false, fmt.Errorf("failed to load solution release: %w", err)
+	}
+	return released, nil
+}
+
+// SetReleased releases an exercise's solution or withdraws the release
+func (s *SQLiteSolutionStore) SetReleased(moduleId, exerciseId string, released bool, at time.Time) error {
+	var err error
+	if released {
+		_, err = s.db.Exec(`INSERT INTO solution_releases (module_id, exercise_id, released_at) VALUES (?, ?, ?)
+			ON CONFLICT (module_id, exercise_id) DO NOTHING`, moduleId, exerciseId, formatSQLiteTime(at))
+	} else {
+		_, err = s.db.Exec(`DELETE FROM solution_releases WHERE module_id = ? AND exercise_id = ?`, moduleId, exerciseId)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save solution release: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// SolutionService decides when learners may see exercise solutions. Test
+// runs and instructor releases are recorded in a store.
+type SolutionService struct {
+	moduleService ModuleServiceInterface
+	store         SolutionStore
+	now           func() time.Time
+}
+
+// NewSolutionService creates a solution service recording attempts and
+// releases in store
+func NewSolutionService(moduleService ModuleServiceInterface, store SolutionStore) *SolutionService {
+	return &SolutionService{
+		moduleService: moduleService,
+		store:         store,
+		now:           time.Now,
+	}
+}
+
+// exercise resolves a module's exercise and its solution policy
+func (s *SolutionService) exercise(moduleId, exerciseId string) (models.Exercise, models.SolutionPolicy, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return models.Exercise{}, models.SolutionPolicy{}, err
+	}
+	exercise, _, err := findExercise(*module, exerciseId)
+	if err != nil {
+		return models.Exercise{}, models.SolutionPolicy{}, err
+	}
+	return exercise, module.SolutionPolicyFor(exercise), nil
+}
+
+// RecordAttempt counts a learner's test run towards unlocking the solution
+func (s *SolutionService) RecordAttempt(moduleId, exerciseId, learnerId string, result *models.TestSuiteResult) error {
+	if learnerId == "" {
+		return ErrLearnerRequired
+	}
+	exercise, _, err := s.exercise(moduleId, exerciseId)
+	if err != nil {
+		return err
+	}
+	return s.store.RecordAttempt(learnerId, moduleId, exercise.ID, result.Passed(), s.now().UTC())
+}
+
+// GetSolution reports whether a learner may see an exercise's solution,
+// including it when they may. An empty exercise ID selects the first exercise.
+func (s *SolutionService) GetSolution(moduleId, exerciseId, learnerId string) (*models.SolutionAccess, error) {
+	if learnerId == "" {
+		return nil, ErrLearnerRequired
+	}
+	exercise, policy, err := s.exercise(moduleId, exerciseId)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.store.Attempts(learnerId, moduleId, exercise.ID)
+	if err != nil {
+		return nil, err
+	}
+	released, err := s.store.Released(moduleId, exercise.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	access := &models.SolutionAccess{
+		ModuleID:   moduleId,
+		ExerciseID: exercise.ID,
+		Policy:     policy,
+		Released:   released,
+		Passed:     attempts.Passed,
+		Attempts:   attempts.Count,
+	}
+	access.Available, access.AvailableAt = solutionAvailable(policy, attempts, released, s.now())
+	if !access.Available {
+		return access, nil
+	}
+
+	step, err := s.moduleService.GetModuleExercise(moduleId, exercise.ID)
+	if err != nil {
+		return nil, err
+	}
+	access.Solution = step.ExerciseContent.Solution
+	return access, nil
+}
+
+// solutionAvailable applies a policy to a learner's attempts. For time
+// limits it also returns when the solution becomes available, once the
+// learner has started.
+func solutionAvailable(policy models.SolutionPolicy, attempts models.SolutionAttempts, released bool, now time.Time) (bool, *time.Time) {
+	if released {
+		return true, nil
+	}
+
+	switch policy.Release {
+	case models.SolutionReleasePassed:
+		return attempts.Passed, nil
+	case models.SolutionReleaseAttempts:
+		return attempts.Passed || attempts.Count >= policy.Attempts, nil
+	case models.SolutionReleaseTime:
+		if attempts.Passed {
+			return true, nil
+		}
+		after, err := time.ParseDuration(policy.After)
+		if err != nil || attempts.Count == 0 {
+			return false, nil
+		}
+		at := attempts.FirstAt.Add(after)
+		return !now.Before(at), &at
+	}
+	// Instructor-only and unknown policies wait for a release
+	return false, nil
+}
+
+// SetSolutionReleased releases an exercise's solution to every learner, or
+// withdraws the release
+func (s *SolutionService) SetSolutionReleased(moduleId, exerciseId string, released bool) (*models.SolutionAccess, error) {
+	exercise, policy, err := s.exercise(moduleId, exerciseId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SetReleased(moduleId, exercise.ID, released, s.now().UTC()); err != nil {
+		return nil, err
+	}
+
+	return &models.SolutionAccess{
+		ModuleID:   moduleId,
+		ExerciseID: exercise.ID,
+		Policy:     policy,
+		Available:  released,
+		Released:   released,
+	}, nil
+}
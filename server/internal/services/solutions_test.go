@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func TestSolutionAvailable(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tried := models.SolutionAttempts{Count: 2, FirstAt: start}
+	passed := models.SolutionAttempts{Count: 1, FirstAt: start, Passed: true}
+
+	cases := []struct {
+		name     string
+		policy   models.SolutionPolicy
+		attempts models.SolutionAttempts
+		released bool
+		want     bool
+	}{
+		{"passed before passing", models.DefaultSolutionPolicy, tried, false, false},
+		{"passed after passing", models.DefaultSolutionPolicy, passed, false, true},
+		{"attempts not reached", models.SolutionPolicy{Release: models.SolutionReleaseAttempts, Attempts: 3}, tried, false, false},
+		{"attempts reached", models.SolutionPolicy{Release: models.SolutionReleaseAttempts, Attempts: 2}, tried, false, true},
+		{"time not started", models.SolutionPolicy{Release: models.SolutionReleaseTime, After: "10m"}, models.SolutionAttempts{}, false, false},
+		{"time waiting", models.SolutionPolicy{Release: models.SolutionReleaseTime, After: "1h"}, tried, false, false},
+		{"time elapsed", models.SolutionPolicy{Release: models.SolutionReleaseTime, After: "10m"}, tried, false, true},
+		{"instructor after passing", models.SolutionPolicy{Release: models.SolutionReleaseInstructor}, passed, false, false},
+		{"instructor released", models.SolutionPolicy{Release: models.SolutionReleaseInstructor}, models.SolutionAttempts{}, true, true},
+	}
+	now := start.Add(30 * time.Minute)
+	for _, tc := range cases {
+		if got, _ := solutionAvailable(tc.policy, tc.attempts, tc.released, now); got != tc.want {
+			t.Errorf("%s: expected available=%t, got %t", tc.name, tc.want, got)
+		}
+	}
+
+	_, at := solutionAvailable(models.SolutionPolicy{Release: models.SolutionReleaseTime, After: "1h"}, tried, false, now)
+	if at == nil || !at.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected the solution to become available an hour after the first attempt, got %v", at)
+	}
+}
+
+func TestSolutionService_GetSolution(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	moduleJSON := strings.Replace(steppedModuleJSON, `"files": {"server": "exercises/sessions/server.js",`,
+		`"solutionPolicy": {"release": "attempts", "attempts": 2},
+			"files": {"solution": "exercises/sessions/solution.js", "server": "exercises/sessions/server.js",`, 1)
+	os.WriteFile(filepath.Join(modulesDir, "module-3", "module.json"), []byte(moduleJSON), 0644)
+	os.WriteFile(filepath.Join(modulesDir, "module-3", "exercises", "sessions", "solution.js"), []byte("// sessions solution"), 0644)
+	dbPath := filepath.Join(t.TempDir(), "data", "progress.db")
+	store, err := NewSQLiteSolutionStore(openTestDB(t, dbPath))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := NewSolutionService(NewModuleServiceWithPath(modulesDir), store)
+
+	if _, err := service.GetSolution("module-3", "sessions", ""); !errors.Is(err, ErrLearnerRequired) {
+		t.Errorf("Expected ErrLearnerRequired, got %v", err)
+	}
+
+	failing := &models.TestSuiteResult{TotalTests: 2, PassedTests: 1, FailedTests: 1}
+	service.RecordAttempt("module-3", "sessions", "learner-1", failing)
+
+	access, err := service.GetSolution("module-3", "sessions", "learner-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if access.Available || access.Solution != "" || access.Attempts != 1 {
+		t.Fatalf("Expected the solution to be locked after one attempt, got %+v", access)
+	}
+
+	service.RecordAttempt("module-3", "sessions", "learner-1", failing)
+	access, err = service.GetSolution("module-3", "sessions", "learner-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !access.Available || access.Solution != "// sessions solution" {
+		t.Errorf("Expected the solution after two attempts, got %+v", access)
+	}
+
+	// The first exercise falls back to the default policy
+	access, err = service.GetSolution("module-3", "", "learner-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if access.ExerciseID != "hashing" || access.Policy != models.DefaultSolutionPolicy || access.Available {
+		t.Errorf("Expected the hashing solution to wait for passing tests, got %+v", access)
+	}
+
+	if _, err := service.SetSolutionReleased("module-3", "hashing", true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	access, _ = service.GetSolution("module-3", "hashing", "learner-2")
+	if !access.Available || !access.Released {
+		t.Errorf("Expected a released solution to be available to everyone, got %+v", access)
+	}
+
+	// Attempts and releases survive a restart
+	reopened, err := NewSQLiteSolutionStore(openTestDB(t, dbPath))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	restarted := NewSolutionService(NewModuleServiceWithPath(modulesDir), reopened)
+	if access, _ := restarted.GetSolution("module-3", "sessions", "learner-1"); !access.Available || access.Attempts != 2 {
+		t.Errorf("Expected the persisted attempts to unlock the solution, got %+v", access)
+	}
+	if access, _ := restarted.GetSolution("module-3", "hashing", "learner-2"); !access.Released {
+		t.Errorf("Expected the persisted release, got %+v", access)
+	}
+
+	service.SetSolutionReleased("module-3", "hashing", false)
+	access, _ = service.GetSolution("module-3", "hashing", "learner-2")
+	if access.Available {
+		t.Errorf("Expected the withdrawn solution to be locked, got %+v", access)
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ErrSubmissionNotFound is returned for unknown submission IDs
+var ErrSubmissionNotFound = errors.New("submission not found")
+
+// SubmissionStore persists graded test runs
+type SubmissionStore interface {
+	SaveSubmission(submission models.Submission) error
+	// GetSubmission returns a submission with its code and results
+	GetSubmission(id string) (*models.Submission, error)
+	// ListSubmissions returns matching submissions, newest first
+	ListSubmissions(filter models.SubmissionFilter) ([]models.SubmissionSummary, error)
+	// PreviousSubmission returns the submission made just before id by the
+	// same learner on the same exercise
+	PreviousSubmission(id string) (*models.Submission, error)
+	// SubmittedBefore reports whether submission fromId was made before toId
+	SubmittedBefore(fromId, toId string) (bool, error)
+	// LatestSubmissions returns each learner's latest submission to each
+	// exercise of a module, leaving out anonymous ones
+	LatestSubmissions(moduleId string) ([]models.Submission, error)
+}
+
+// submissionSchema creates the submissions table. seq orders submissions
+// made within the same clock tick; results are stored as JSON.
+const submissionSchema = `
+CREATE TABLE IF NOT EXISTS submissions (
+	seq            INTEGER PRIMARY KEY AUTOINCREMENT,
+	id             TEXT    NOT NULL UNIQUE,
+	learner_id     TEXT    NOT NULL,
+	module_id      TEXT    NOT NULL,
+	exercise_id    TEXT    NOT NULL,
+	module_version TEXT    NOT NULL,
+	status         TEXT    NOT NULL,
+	total_tests    INTEGER NOT NULL,
+	passed_tests   INTEGER NOT NULL,
+	failed_tests   INTEGER NOT NULL,
+	passed         INTEGER NOT NULL,
+	submitted_at   TEXT    NOT NULL,
+	code           TEXT    NOT NULL,
+	result         TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS submissions_learner ON submissions (learner_id, module_id, exercise_id);
+CREATE INDEX IF NOT EXISTS submissions_module ON submissions (module_id, exercise_id);
+`
+
+const submissionSummaryColumns = `id, learner_id, module_id, exercise_id, module_version, status, total_tests, passed_tests, failed_tests, passed, submitted_at`
+
+const submissionColumns = submissionSummaryColumns + `, code, result`
+
+// SQLiteSubmissionStore keeps submissions in a SQLite database
+type SQLiteSubmissionStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteSubmissionStore keeps submissions in db, creating its table if needed
+func NewSQLiteSubmissionStore(db *sql.DB) (*SQLiteSubmissionStore, error) {
+	if _, err := db.Exec(submissionSchema); err != nil {
+		return nil, fmt.Errorf("failed to create submission tables: %w", err)
+	}
+	return &SQLiteSubmissionStore{db: db}, nil
+}
+
+// SaveSubmission stores a submission
+func (s *SQLiteSubmissionStore) SaveSubmission(submission models.Submission) error {
+	result, err := json.Marshal(submission.Result)
+	if err != nil {
+		return fmt.Errorf("failed to encode test results: %w", err)
+	}
+
+	_, err = s.db.Exec(`INSERT INTO submissions (`+submissionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		submission.ID, submission.LearnerID, submission.ModuleID, submission.ExerciseID, submission.ModuleVersion,
+		submission.Status, submission.TotalTests, submission.PassedTests, submission.FailedTests, submission.Passed,
+		formatSQLiteTime(submission.SubmittedAt), submission.Code, string(result))
+	if err != nil {
+		return fmt.Errorf("failed to save submission: %w", err)
+	}
+	return nil
+}
+
+// GetSubmission returns a submission with its code and results
+func (s *SQLiteSubmissionStore) GetSubmission(id string) (*models.Submission, error) {
+	row := s.db.QueryRow(`SELECT `+submissionColumns+` FROM submissions WHERE id = ?`, id)
+	return scanSubmission(row, id)
+}
+
+// PreviousSubmission returns the submission made just before id by the same
+// learner on the same exercise
+func (s *SQLiteSubmissionStore) PreviousSubmission(id string) (*models.Submission, error) {
+	row := s.db.QueryRow(`SELECT `+submissionColumns+` FROM submissions
+		WHERE (learner_id, module_id, exercise_id) = (SELECT learner_id, module_id, exercise_id FROM submissions WHERE id = ?1)
+			AND seq < (SELECT seq FROM submissions WHERE id = ?1)
+		ORDER BY seq DESC LIMIT 1`, id)
+	return scanSubmission(row, "before "+id)
+}
+
+// SubmittedBefore reports whether submission fromId was made before toId
+func (s *SQLiteSubmissionStore) SubmittedBefore(fromId, toId string) (bool, error) {
+	var before bool
+	err := s.db.QueryRow(`SELECT COALESCE((SELECT seq FROM submissions WHERE id = ?) < (SELECT seq FROM submissions WHERE id = ?), 0)`,
+		fromId, toId).Scan(&before)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare submissions: %w", err)
+	}
+	return before, nil
+}
+
+// LatestSubmissions returns each learner's latest submission to each
+// exercise of a module, ordered by exercise and then by submission
+func (s *SQLiteSubmissionStore) LatestSubmissions(moduleId string) ([]models.Submission, error) {
+	rows, err := s.db.Query(`SELECT `+submissionColumns+` FROM submissions AS latest
+		WHERE module_id = ? AND learner_id != ''
+			AND seq = (SELECT MAX(seq) FROM submissions
+				WHERE (learner_id, module_id, exercise_id) = (latest.learner_id, latest.module_id, latest.exercise_id))
+		ORDER BY exercise_id, seq`, moduleId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load submissions: %w", err)
+	}
+	defer rows.Close()
+
+	submissions := []models.Submission{}
+	for rows.Next() {
+		submission, err := scanSubmission(rows, "")
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, *submission)
+	}
+	return submissions, rows.Err()
+}
+
+// ListSubmissions returns matching submissions, newest first
+func (s *SQLiteSubmissionStore) ListSubmissions(filter models.SubmissionFilter) ([]models.SubmissionSummary, error) {
+	var conditions []string
+	var args []interface{}
+	for _, field := range []struct {
+		column string
+		value  string
+	}{
+		{"learner_id", filter.LearnerID},
+		{"module_id", filter.ModuleID},
+		{"exercise_id", filter.ExerciseID},
+	} {
+		if field.value != "" {
+			conditions = append(conditions, field.column+" = ?")
+			args = append(args, field.value)
+		}
+	}
+
+	query := `SELECT ` + submissionSummaryColumns + ` FROM submissions`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY seq DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load submissions: %w", err)
+	}
+	defer rows.Close()
+
+	submissions := []models.SubmissionSummary{}
+	for rows.Next() {
+		var summary models.SubmissionSummary
+		if err := scanSubmissionSummary(rows, &summary); err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, summary)
+	}
+	return submissions, rows.Err()
+}
+
+// scanSubmission reads a row selected with submissionColumns; what describes
+// the submission in the not found error
+func scanSubmission(row interface{ Scan(...interface{}) error }, what string) (*models.Submission, error) {
+	var submission models.Submission
+	var result string
+	err := scanSubmissionSummary(row, &submission.SubmissionSummary, &submission.Code, &result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrSubmissionNotFound, what)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(result), &submission.Result); err != nil {
+		return nil, fmt.Errorf("failed to read test results of submission %s: %w", submission.ID, err)
+	}
+	return &submission, nil
+}
+
+// scanSubmissionSummary reads the submissionSummaryColumns of a row, followed
+// by any extra columns
+func scanSubmissionSummary(row interface{ Scan(...interface{}) error }, summary *models.SubmissionSummary, extra ...interface{}) error {
+	var submittedAt string
+	dest := append([]interface{}{&summary.ID, &summary.LearnerID, &summary.ModuleID, &summary.ExerciseID, &summary.ModuleVersion,
+		&summary.Status, &summary.TotalTests, &summary.PassedTests, &summary.FailedTests, &summary.Passed, &submittedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return fmt.Errorf("failed to read submission: %w", err)
+	}
+
+	t, err := parseSQLiteTime(submittedAt)
+	if err != nil {
+		return fmt.Errorf("failed to read submission: %w", err)
+	}
+	summary.SubmittedAt = t
+	return nil
+}
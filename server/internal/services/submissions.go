@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+// ErrInvalidDiff is returned when comparing submissions to different
+// exercises, or a submission with one made after it
+var ErrInvalidDiff = errors.New("submissions cannot be compared")
+
+// SubmissionService keeps the history of graded test runs and compares
+// attempts with each other
+type SubmissionService struct {
+	moduleService ModuleServiceInterface
+	store         SubmissionStore
+	now           func() time.Time
+}
+
+// NewSubmissionService creates a submission service backed by store
+func NewSubmissionService(moduleService ModuleServiceInterface, store SubmissionStore) *SubmissionService {
+	return &SubmissionService{
+		moduleService: moduleService,
+		store:         store,
+		now:           time.Now,
+	}
+}
+
+// Record stores a test run's code and results. An empty exercise ID selects
+// the module's first exercise; anonymous runs have no learner ID.
+func (s *SubmissionService) Record(learnerId, moduleId, exerciseId, code string, result *models.TestSuiteResult) (*models.Submission, error) {
+	module, err := s.moduleService.GetModuleById(moduleId)
+	if err != nil {
+		return nil, err
+	}
+	exercise, _, err := findExercise(*module, exerciseId)
+	if err != nil {
+		return nil, err
+	}
+
+	version := result.ModuleVersion
+	if version == "" {
+		content, err := s.moduleService.GetModuleContent(moduleId)
+		if err != nil {
+			return nil, err
+		}
+		version = content.Version
+	}
+
+	id, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := models.Submission{
+		SubmissionSummary: models.SubmissionSummary{
+			ID:            id,
+			LearnerID:     learnerId,
+			ModuleID:      moduleId,
+			ExerciseID:    exercise.ID,
+			ModuleVersion: version,
+			Status:        result.Status,
+			TotalTests:    result.TotalTests,
+			PassedTests:   result.PassedTests,
+			FailedTests:   result.FailedTests,
+			Passed:        result.Passed(),
+			SubmittedAt:   s.now().UTC(),
+		},
+		Code:   code,
+		Result: result,
+	}
+	if err := s.store.SaveSubmission(submission); err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// List returns matching submissions, newest first
+func (s *SubmissionService) List(filter models.SubmissionFilter) ([]models.SubmissionSummary, error) {
+	return s.store.ListSubmissions(filter)
+}
+
+// Get returns a submission with its code and results
+func (s *SubmissionService) Get(id string) (*models.Submission, error) {
+	return s.store.GetSubmission(id)
+}
+
+// Diff compares submission fromId with the later submission toId on the
+// same exercise. Without fromId, the attempt made just before toId on the
+// same exercise is used. With a learner ID, submissions of anyone else are
+// reported as not found.
+func (s *SubmissionService) Diff(learnerId, fromId, toId string) (*models.SubmissionDiff, error) {
+	to, err := s.store.GetSubmission(toId)
+	if err != nil {
+		return nil, err
+	}
+	var from *models.Submission
+	if fromId == "" {
+		from, err = s.store.PreviousSubmission(toId)
+	} else {
+		from, err = s.store.GetSubmission(fromId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if learnerId != "" && (from.LearnerID != learnerId || to.LearnerID != learnerId) {
+		return nil, ErrSubmissionNotFound
+	}
+
+	if fromId != "" {
+		if from.ModuleID != to.ModuleID || from.ExerciseID != to.ExerciseID {
+			return nil, fmt.Errorf("%w: %s and %s are for different exercises", ErrInvalidDiff, fromId, toId)
+		}
+		before, err := s.store.SubmittedBefore(fromId, toId)
+		if err != nil {
+			return nil, err
+		}
+		if !before {
+			return nil, fmt.Errorf("%w: %s was not made before %s", ErrInvalidDiff, fromId, toId)
+		}
+	}
+
+	return &models.SubmissionDiff{
+		From:  from.SubmissionSummary,
+		To:    to.SubmissionSummary,
+		Code:  diffCode(from.ID, to.ID, from.Code, to.Code),
+		Tests: testChanges(from.Result, to.Result),
+	}, nil
+}
+
+// testChanges sorts tests by how their outcome changed between two runs
+func testChanges(from, to *models.TestSuiteResult) models.TestChanges {
+	before := make(map[string]bool)
+	if from != nil {
+		for _, test := range from.Results {
+			before[test.TestName] = test.Passed
+		}
+	}
+	after := make(map[string]bool)
+	if to != nil {
+		for _, test := range to.Results {
+			after[test.TestName] = test.Passed
+		}
+	}
+
+	changes := models.TestChanges{
+		Fixed:        []string{},
+		Regressed:    []string{},
+		StillFailing: []string{},
+		StillPassing: []string{},
+		Added:        []string{},
+		Removed:      []string{},
+	}
+	for name, passed := range after {
+		wasPassed, ran := before[name]
+		switch {
+		case !ran:
+			changes.Added = append(changes.Added, name)
+		case passed && !wasPassed:
+			changes.Fixed = append(changes.Fixed, name)
+		case !passed && wasPassed:
+			changes.Regressed = append(changes.Regressed, name)
+		case passed:
+			changes.StillPassing = append(changes.StillPassing, name)
+		default:
+			changes.StillFailing = append(changes.StillFailing, name)
+		}
+	}
+	for name := range before {
+		if _, ran := after[name]; !ran {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	for _, names := range [][]string{changes.Fixed, changes.Regressed, changes.StillFailing, changes.StillPassing, changes.Added, changes.Removed} {
+		sort.Strings(names)
+	}
+	return changes
+}
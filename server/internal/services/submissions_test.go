@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/backend2lab/backend2lab/server/internal/models"
+)
+
+func TestSubmissionService(t *testing.T) {
+	modulesDir := filepath.Join(t.TempDir(), "modules")
+	writeSteppedModule(t, modulesDir)
+	store, err := NewSQLiteSubmissionStore(openTestDB(t, ":memory:"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service := NewSubmissionService(NewModuleServiceWithPath(modulesDir), store)
+
+	first, err := service.Record("learner-1", "module-3", "", "const a = 1;\nmodule.exports = a;\n", &models.TestSuiteResult{
+		TotalTests: 3, PassedTests: 1, FailedTests: 2,
+		Results: []models.TestResult{
+			{TestName: "hashes", Passed: true},
+			{TestName: "salts", Passed: false},
+			{TestName: "compares", Passed: false},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.ExerciseID != "hashing" || first.ModuleVersion == "" || first.Passed {
+		t.Errorf("Unexpected submission: %+v", first.SubmissionSummary)
+	}
+
+	service.Record("learner-2", "module-3", "hashing", "other", &models.TestSuiteResult{})
+	second, err := service.Record("learner-1", "module-3", "hashing", "const a = 2;\nmodule.exports = a;\n", &models.TestSuiteResult{
+		TotalTests: 3, PassedTests: 2, FailedTests: 1,
+		Results: []models.TestResult{
+			{TestName: "hashes", Passed: false},
+			{TestName: "salts", Passed: true},
+			{TestName: "timing", Passed: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := service.Record("learner-1", "module-3", "missing", "", &models.TestSuiteResult{}); err == nil {
+		t.Error("Expected an error for an unknown exercise")
+	}
+
+	listed, err := service.List(models.SubmissionFilter{LearnerID: "learner-1", ModuleID: "module-3"})
+	if err != nil || len(listed) != 2 || listed[0].ID != second.ID || listed[1].ID != first.ID {
+		t.Errorf("Expected the learner's submissions newest first, got %+v, %v", listed, err)
+	}
+	if listed, _ := service.List(models.SubmissionFilter{Limit: 1}); len(listed) != 1 || listed[0].ID != second.ID {
+		t.Errorf("Expected the newest submission, got %+v", listed)
+	}
+
+	stored, err := service.Get(first.ID)
+	if err != nil || stored.Code != first.Code || len(stored.Result.Results) != 3 || !stored.SubmittedAt.Equal(first.SubmittedAt) {
+		t.Errorf("Expected the stored submission, got %+v, %v", stored, err)
+	}
+	if _, err := service.Get("missing"); !errors.Is(err, ErrSubmissionNotFound) {
+		t.Errorf("Expected ErrSubmissionNotFound, got %v", err)
+	}
+
+	// Without a from ID the previous attempt of the same learner is used
+	diff, err := service.Diff("learner-1", "", second.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if diff.From.ID != first.ID || diff.To.ID != second.ID {
+		t.Errorf("Expected a diff from the first attempt, got %s to %s", diff.From.ID, diff.To.ID)
+	}
+	if diff.Code.Added != 1 || diff.Code.Removed != 1 || !strings.Contains(diff.Code.Unified, "-const a = 1;\n+const a = 2;\n") {
+		t.Errorf("Unexpected code diff: %+v", diff.Code)
+	}
+	expected := models.TestChanges{
+		Fixed:        []string{"salts"},
+		Regressed:    []string{"hashes"},
+		StillFailing: []string{},
+		StillPassing: []string{},
+		Added:        []string{"timing"},
+		Removed:      []string{"compares"},
+	}
+	if !reflect.DeepEqual(diff.Tests, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diff.Tests)
+	}
+
+	if _, err := service.Diff("", "", first.ID); !errors.Is(err, ErrSubmissionNotFound) {
+		t.Errorf("Expected no previous attempt for the first submission, got %v", err)
+	}
+
+	// An explicit from ID has to be an earlier attempt at the same exercise
+	if diff, err := service.Diff("learner-1", first.ID, second.ID); err != nil || diff.From.ID != first.ID {
+		t.Errorf("Expected a diff from the first attempt, got %+v, %v", diff, err)
+	}
+	if _, err := service.Diff("learner-1", second.ID, first.ID); !errors.Is(err, ErrInvalidDiff) {
+		t.Errorf("Expected ErrInvalidDiff comparing with a later attempt, got %v", err)
+	}
+	sessions, err := service.Record("learner-1", "module-3", "sessions", "", &models.TestSuiteResult{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.Diff("learner-1", first.ID, sessions.ID); !errors.Is(err, ErrInvalidDiff) {
+		t.Errorf("Expected ErrInvalidDiff comparing different exercises, got %v", err)
+	}
+	if _, err := service.Diff("learner-2", first.ID, second.ID); !errors.Is(err, ErrSubmissionNotFound) {
+		t.Errorf("Expected another learner's submissions to be not found, got %v", err)
+	}
+}
+
+func TestDiffCode(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+
+	diff := diffCode("old", "new", from, to)
+	expected := `--- old
++++ new
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+`
+	if diff.Unified != expected || diff.Added != 2 || diff.Removed != 1 {
+		t.Errorf("Unexpected diff %+v:\n%s", diff, diff.Unified)
+	}
+
+	if diff := diffCode("old", "new", from, from); diff.Unified != "" || diff.Added != 0 || diff.Removed != 0 {
+		t.Errorf("Expected no diff for unchanged code, got %+v", diff)
+	}
+	if diff := diffCode("old", "new", "", "x\n"); diff.Unified != "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n" {
+		t.Errorf("Unexpected diff for new code:\n%s", diff.Unified)
+	}
+}
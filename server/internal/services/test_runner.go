@@ -8,10 +8,11 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/backend2lab/backend2lab/server/internal/models"
@@ -20,72 +21,219 @@ import (
 )
 
 type TestRunner struct {
-	modulesPath string
-	httpClient  *http.Client
-	dockerRunner *DockerRunner
+	source         *ContentSource
+	httpClient     *http.Client
+	dockerRunner   *DockerRunner
+	perTestTimeout time.Duration
+	// catalog records the module versions results are stamped with
+	catalog ModuleServiceInterface
+
+	// runMu lets one run at a time use the exercise directories and port,
+	// whether a learner's or the self-check's
+	runMu sync.Mutex
 }
 
 // NewTestRunner creates a new TestRunner instance
 func NewTestRunner() *TestRunner {
 	modulesPath := filepath.Join("src", "modules")
-	
+	return NewTestRunnerWithSource(NewDirContentSource(modulesPath))
+}
+
+// NewTestRunnerWithSource creates a TestRunner for modules from a content source
+func NewTestRunnerWithSource(source *ContentSource) *TestRunner {
 	// Initialize Docker runner
-	dockerRunner, err := NewDockerRunner()
+	dockerRunner, err := NewDockerRunner(source)
 	if err != nil {
 		logrus.Warnf("Failed to initialize Docker runner, falling back to direct execution: %v", err)
 		dockerRunner = nil
 	}
 	
 	return &TestRunner{
-		modulesPath: modulesPath,
+		source: source,
 		httpClient: &http.Client{
 			Timeout: 5 * time.Second,
 		},
-		dockerRunner: dockerRunner,
+		dockerRunner:   dockerRunner,
+		perTestTimeout: defaultPerTestTimeout,
 	}
 }
 
+// WithCatalog sets the module catalog that records the versions test results
+// are stamped with in its history, so a result's version can always be
+// looked up.
+func (t *TestRunner) WithCatalog(catalog ModuleServiceInterface) *TestRunner {
+	t.catalog = catalog
+	return t
+}
 
-// RunCode executes the provided code for a module
+// RunCode executes the provided code for a module's first exercise
 func (t *TestRunner) RunCode(moduleId, inputCode string) (*models.RunResult, error) {
-	startTime := time.Now()
-
-	if moduleId == "module-1" {
-		return t.runModule1Code(moduleId, inputCode, startTime)
-	}
-
-	return t.runServerCode(moduleId, inputCode, startTime)
-	// return nil, fmt.Errorf("Docker runner not available and direct execution not implemented for module: %s", moduleId)
+	return t.RunExerciseCode(moduleId, "", inputCode)
 }
 
-// RunTests executes tests for the provided code
+// RunTests executes tests for the provided code against a module's first exercise
 func (t *TestRunner) RunTests(moduleId, inputCode string) (*models.TestSuiteResult, error) {
+	return t.RunExerciseTests(moduleId, "", inputCode)
+}
+
+// RunExerciseCode executes the provided code for one of a module's exercises
+func (t *TestRunner) RunExerciseCode(moduleId, exerciseId, inputCode string) (*models.RunResult, error) {
+	t.runMu.Lock()
+	defer t.runMu.Unlock()
 	startTime := time.Now()
 
-	if moduleId == "module-1" {
-		return t.runModule1Tests(moduleId, inputCode, startTime)
+	exercise, err := t.resolveExercise(moduleId, exerciseId)
+	if err != nil {
+		return &models.RunResult{
+			ModuleID:      moduleId,
+			ExerciseID:    exerciseId,
+			Success:       false,
+			Message:       err.Error(),
+			ExecutionTime: time.Since(startTime).Milliseconds(),
+		}, nil
+	}
+
+	var result *models.RunResult
+	if exercise.exerciseType == models.ExerciseTypeFunction {
+		result, err = t.runFunctionCode(moduleId, exercise, inputCode, startTime)
+	} else {
+		result, err = t.runServerCode(moduleId, exercise, inputCode, startTime)
 	}
-	return t.runServerTests(moduleId, inputCode, startTime)
-	// return nil, fmt.Errorf("Docker runner not available and direct execution not implemented for module: %s", moduleId)
+
+	if result != nil {
+		result.ExerciseID = exercise.id
+	}
+	return result, err
 }
 
-// runModule1Code executes function-based code for module-1
-func (t *TestRunner) runModule1Code(moduleId, inputCode string, startTime time.Time) (*models.RunResult, error) {
-	modulePath := filepath.Join(t.modulesPath, moduleId)
-	mainFilePath := filepath.Join(modulePath, "exercise", "tmp-server.js")
+// RunExerciseTests executes the tests of one of a module's exercises against
+// the provided code. The result is stamped with the version of the module
+// content it was graded against.
+func (t *TestRunner) RunExerciseTests(moduleId, exerciseId, inputCode string) (*models.TestSuiteResult, error) {
+	t.runMu.Lock()
+	defer t.runMu.Unlock()
+	startTime := time.Now()
 
-	// Check if module exists
-	if _, err := os.Stat(modulePath); os.IsNotExist(err) {
-		return &models.RunResult{
+	exercise, err := t.resolveExercise(moduleId, exerciseId)
+	if err != nil {
+		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
-			Success:       false,
-			Message:       fmt.Sprintf("Module %s not found", moduleId),
+			ExerciseID:    exerciseId,
+			Status:        models.SuiteStatusError,
+			Results:       []models.TestResult{{TestName: "Module Setup", Passed: false, Error: &[]string{err.Error()}[0]}},
 			ExecutionTime: time.Since(startTime).Milliseconds(),
-			ExerciseType:  "function",
 		}, nil
 	}
 
+	var result *models.TestSuiteResult
+	if exercise.exerciseType == models.ExerciseTypeFunction {
+		result, err = t.runFunctionTests(moduleId, exercise, inputCode, startTime)
+	} else {
+		result, err = t.runServerTests(moduleId, exercise, inputCode, startTime)
+	}
+
+	if result != nil {
+		result.ModuleVersion = exercise.version
+		result.ExerciseID = exercise.id
+		t.recordVersion(moduleId, exercise.version)
+	}
+	return result, err
+}
+
+// recordVersion makes sure the catalog has loaded the version of a module a
+// result was graded against, so it can be looked up in the catalog's history.
+// Files on disk can change before the catalog is reloaded.
+func (t *TestRunner) recordVersion(moduleId, version string) {
+	if t.catalog == nil || version == "" {
+		return
+	}
+	if content, err := t.catalog.GetModuleContent(moduleId); err == nil && content.Version == version {
+		return
+	}
+	if _, err := t.catalog.Reload(); err != nil {
+		logrus.Warnf("Failed to reload modules for version %s of %s: %v", version, moduleId, err)
+	}
+}
+
+// exerciseRun describes where an exercise's code is written and tested
+type exerciseRun struct {
+	id           string
+	exerciseType string
+	dir          string // exercise directory on disk
+	testFile     string // test file name within dir
+	version      string // version of the module files the tests are run from
+}
+
+// resolveExercise looks up an exercise in the module's module.json. Code is
+// written next to the exercise's test file (or server file), which for
+// modules without an exercises list is the exercise directory.
+func (t *TestRunner) resolveExercise(moduleId, exerciseId string) (*exerciseRun, error) {
+	modulePath, err := t.moduleDir(moduleId)
+	if err != nil {
+		return nil, fmt.Errorf("Module %s not found", moduleId)
+	}
+
+	exercise, _, err := findExercise(readModuleManifest(t.source, moduleId), exerciseId)
+	if err != nil {
+		return nil, fmt.Errorf("Exercise %s not found in module %s", exerciseId, moduleId)
+	}
+
+	dir, testFile := "exercise", "test.js"
+	if exercise.Files.Test != nil {
+		if rel, ok := moduleRelativePath(*exercise.Files.Test); ok {
+			dir, testFile = path.Dir(rel), path.Base(rel)
+		}
+	} else if exercise.Files.Server != nil {
+		if rel, ok := moduleRelativePath(*exercise.Files.Server); ok {
+			dir = path.Dir(rel)
+		}
+	}
+
+	// Hash the files the tests are about to run from, rather than asking the
+	// catalog, which may have been loaded before or after them
+	version, err := t.source.ModuleVersion(moduleId)
+	if err != nil {
+		logrus.Warnf("Failed to compute version of module %s: %v", moduleId, err)
+	}
+
+	return &exerciseRun{
+		id:           exercise.ID,
+		exerciseType: exerciseType(moduleId, exercise),
+		dir:          filepath.Join(modulePath, filepath.FromSlash(dir)),
+		testFile:     testFile,
+		version:      version,
+	}, nil
+}
+
+// readModuleManifest reads a module's module.json, falling back to a module
+// with only its ID when the file is missing or malformed
+func readModuleManifest(source *ContentSource, moduleId string) models.Module {
+	module := models.Module{ID: moduleId}
+	if data, err := source.readModuleFile(moduleId, "module.json"); err == nil {
+		if err := json.Unmarshal(data, &module); err != nil {
+			logrus.Warnf("Failed to parse module.json for %s: %v", moduleId, err)
+		}
+	}
+	return module
+}
+
+// moduleDir returns the module's directory on disk, extracting embedded
+// content if needed
+func (t *TestRunner) moduleDir(moduleId string) (string, error) {
+	modulePath, err := t.source.ModuleDir(moduleId)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(modulePath); err != nil {
+		return "", err
+	}
+	return modulePath, nil
+}
+
+// runFunctionCode executes function-based code
+func (t *TestRunner) runFunctionCode(moduleId string, exercise *exerciseRun, inputCode string, startTime time.Time) (*models.RunResult, error) {
 	// Write input code to tmp-server.js
+	mainFilePath := filepath.Join(exercise.dir, "tmp-server.js")
 	if err := os.WriteFile(mainFilePath, []byte(inputCode), 0644); err != nil {
 		return &models.RunResult{
 			ModuleID:      moduleId,
@@ -102,7 +250,7 @@ func (t *TestRunner) runModule1Code(moduleId, inputCode string, startTime time.T
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "node", "tmp-server.js")
-	cmd.Dir = filepath.Join(modulePath, "exercise")
+	cmd.Dir = exercise.dir
 
 	output, err := cmd.CombinedOutput()
 	outputStr := strings.TrimSpace(string(output))
@@ -128,28 +276,14 @@ func (t *TestRunner) runModule1Code(moduleId, inputCode string, startTime time.T
 	}, nil
 }
 
-// runModule1Tests runs tests for module-1
-func (t *TestRunner) runModule1Tests(moduleId, inputCode string, startTime time.Time) (*models.TestSuiteResult, error) {
-	modulePath := filepath.Join(t.modulesPath, moduleId)
-	mainFilePath := filepath.Join(modulePath, "exercise", "tmp-server.js")
-
-	// Check if module exists
-	if _, err := os.Stat(modulePath); os.IsNotExist(err) {
-		return &models.TestSuiteResult{
-			ModuleID:      moduleId,
-			TotalTests:    0,
-			PassedTests:   0,
-			FailedTests:   0,
-			Results:       []models.TestResult{{TestName: "Module Setup", Passed: false, Error: &[]string{fmt.Sprintf("Module %s not found", moduleId)}[0]}},
-			ExecutionTime: time.Since(startTime).Milliseconds(),
-			ExerciseType:  "function",
-		}, nil
-	}
-
+// runFunctionTests runs tests for function-based exercises
+func (t *TestRunner) runFunctionTests(moduleId string, exercise *exerciseRun, inputCode string, startTime time.Time) (*models.TestSuiteResult, error) {
 	// Write input code to tmp-server.js
+	mainFilePath := filepath.Join(exercise.dir, "tmp-server.js")
 	if err := os.WriteFile(mainFilePath, []byte(inputCode), 0644); err != nil {
 		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
 			TotalTests:    0,
 			PassedTests:   0,
 			FailedTests:   0,
@@ -159,84 +293,18 @@ func (t *TestRunner) runModule1Tests(moduleId, inputCode string, startTime time.
 		}, nil
 	}
 
-	// Run tests using mocha
-	testPath := filepath.Join(modulePath, "exercise", "test.js")
-	cmd := exec.Command("npx", "mocha", testPath, "--reporter", "json")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	cmd = exec.CommandContext(ctx, cmd.Path, cmd.Args...)
-
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
-
-	var results []models.TestResult
-	if err != nil {
-		// Try to parse output even if command failed
-		if mochaOutput, parseErr := t.parseMochaOutput(outputStr); parseErr == nil {
-			results = t.convertMochaResults(mochaOutput)
-		} else {
-			results = []models.TestResult{{
-				TestName: "Test Execution",
-				Passed:   false,
-				Error:    &[]string{fmt.Sprintf("Test execution failed: %s", err.Error())}[0],
-			}}
-		}
-	} else {
-		if mochaOutput, parseErr := t.parseMochaOutput(outputStr); parseErr == nil {
-			results = t.convertMochaResults(mochaOutput)
-		} else {
-			results = []models.TestResult{{
-				TestName: "Test Execution",
-				Passed:   false,
-				Error:    &[]string{fmt.Sprintf("Failed to parse test results: %s", parseErr.Error())}[0],
-			}}
-		}
-	}
-
-	passedTests := 0
-	failedTests := 0
-	for _, result := range results {
-		if result.Passed {
-			passedTests++
-		} else {
-			failedTests++
-		}
-	}
-
-	return &models.TestSuiteResult{
-		ModuleID:      moduleId,
-		TotalTests:    len(results),
-		PassedTests:   passedTests,
-		FailedTests:   failedTests,
-		Results:       results,
-		ExecutionTime: time.Since(startTime).Milliseconds(),
-		ExerciseType:  "function",
-	}, nil
+	return t.runMochaSuite(moduleId, "function", exercise, 10*time.Second, startTime), nil
 }
 
 // runServerCode starts a server with the provided code
-func (t *TestRunner) runServerCode(moduleId, inputCode string, startTime time.Time) (*models.RunResult, error) {
-	modulePath := filepath.Join(t.modulesPath, moduleId)
-	mainFilePath := filepath.Join(modulePath, "exercise", "tmp-server.js")
-
-	// Check if module exists
-	if _, err := os.Stat(modulePath); os.IsNotExist(err) {
-		return &models.RunResult{
-			ModuleID:      moduleId,
-			Success:       false,
-			Message:       fmt.Sprintf("Module %s not found", moduleId),
-			ExecutionTime: time.Since(startTime).Milliseconds(),
-			ExerciseType:  "server",
-		}, nil
-	}
-
+func (t *TestRunner) runServerCode(moduleId string, exercise *exerciseRun, inputCode string, startTime time.Time) (*models.RunResult, error) {
 	// Kill any existing processes on port 3000
 	if err := t.killProcessOnPort(3000); err != nil {
 		logrus.Warnf("Failed to kill process on port 3000: %v", err)
 	}
 
 	// Write input code to tmp-server.js
+	mainFilePath := filepath.Join(exercise.dir, "tmp-server.js")
 	if err := os.WriteFile(mainFilePath, []byte(inputCode), 0644); err != nil {
 		return &models.RunResult{
 			ModuleID:      moduleId,
@@ -250,7 +318,7 @@ func (t *TestRunner) runServerCode(moduleId, inputCode string, startTime time.Ti
 
 	// Start the server
 	cmd := exec.Command("node", "tmp-server.js")
-	cmd.Dir = filepath.Join(modulePath, "exercise")
+	cmd.Dir = exercise.dir
 
 	// Capture server output
 	var serverOutput bytes.Buffer
@@ -312,33 +380,19 @@ func (t *TestRunner) runServerCode(moduleId, inputCode string, startTime time.Ti
 	}
 }
 
-// runServerTests runs tests for server-based modules
-func (t *TestRunner) runServerTests(moduleId, inputCode string, startTime time.Time) (*models.TestSuiteResult, error) {
-	modulePath := filepath.Join(t.modulesPath, moduleId)
-	mainFilePath := filepath.Join(modulePath, "exercise", "tmp-server.js")
-
-	// Check if module exists
-	if _, err := os.Stat(modulePath); os.IsNotExist(err) {
-		return &models.TestSuiteResult{
-			ModuleID:      moduleId,
-			TotalTests:    0,
-			PassedTests:   0,
-			FailedTests:   0,
-			Results:       []models.TestResult{{TestName: "Module Setup", Passed: false, Error: &[]string{fmt.Sprintf("Module %s not found", moduleId)}[0]}},
-			ExecutionTime: time.Since(startTime).Milliseconds(),
-			ExerciseType:  "server",
-		}, nil
-	}
-
+// runServerTests runs tests for server-based exercises
+func (t *TestRunner) runServerTests(moduleId string, exercise *exerciseRun, inputCode string, startTime time.Time) (*models.TestSuiteResult, error) {
 	// Kill any existing processes on port 3000
 	if err := t.killProcessOnPort(3000); err != nil {
 		logrus.Warnf("Failed to kill process on port 3000: %v", err)
 	}
 
 	// Write input code to tmp-server.js
+	mainFilePath := filepath.Join(exercise.dir, "tmp-server.js")
 	if err := os.WriteFile(mainFilePath, []byte(inputCode), 0644); err != nil {
 		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
 			TotalTests:    0,
 			PassedTests:   0,
 			FailedTests:   0,
@@ -350,7 +404,7 @@ func (t *TestRunner) runServerTests(moduleId, inputCode string, startTime time.T
 
 	// Start the server in background
 	cmd := exec.Command("node", "tmp-server.js")
-	cmd.Dir = filepath.Join(modulePath, "exercise")
+	cmd.Dir = exercise.dir
 
 	// Capture server output for debugging
 	var serverOutput bytes.Buffer
@@ -360,6 +414,7 @@ func (t *TestRunner) runServerTests(moduleId, inputCode string, startTime time.T
 	if err := cmd.Start(); err != nil {
 		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
 			TotalTests:    0,
 			PassedTests:   0,
 			FailedTests:   0,
@@ -394,6 +449,7 @@ func (t *TestRunner) runServerTests(moduleId, inputCode string, startTime time.T
 	if !serverStarted {
 		return &models.TestSuiteResult{
 			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
 			TotalTests:    0,
 			PassedTests:   0,
 			FailedTests:   0,
@@ -403,110 +459,83 @@ func (t *TestRunner) runServerTests(moduleId, inputCode string, startTime time.T
 		}, nil
 	}
 
-	// Run tests using mocha
-	testPath := filepath.Join(modulePath, "exercise", "test.js")
-	testCmd := exec.Command("npx", "mocha", testPath, "--reporter", "json")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-	testCmd = exec.CommandContext(ctx, testCmd.Path, testCmd.Args...)
-
-	output, err := testCmd.CombinedOutput()
-	outputStr := string(output)
+	return t.runMochaSuite(moduleId, "server", exercise, 15*time.Second, startTime), nil
+}
 
-	var results []models.TestResult
-	if err != nil {
-		// Try to parse output even if command failed
-		if mochaOutput, parseErr := t.parseMochaOutput(outputStr); parseErr == nil {
-			results = t.convertMochaResults(mochaOutput)
-		} else {
-			results = []models.TestResult{{
-				TestName: "Test Execution",
-				Passed:   false,
-				Error:    &[]string{fmt.Sprintf("Test execution failed: %s\nOutput: %s", err.Error(), outputStr)}[0],
-			}}
-		}
-	} else {
-		if mochaOutput, parseErr := t.parseMochaOutput(outputStr); parseErr == nil {
-			results = t.convertMochaResults(mochaOutput)
-		} else {
-			results = []models.TestResult{{
-				TestName: "Test Execution",
-				Passed:   false,
-				Error:    &[]string{fmt.Sprintf("Failed to parse test results: %s\nOutput: %s", parseErr.Error(), outputStr)}[0],
-			}}
-		}
+// runMochaSuite runs the module's test file with the stream reporter and
+// builds a suite result from whatever the reporter recorded before the
+// process exited or the suite timeout expired
+func (t *TestRunner) runMochaSuite(moduleId, exerciseType string, exercise *exerciseRun, suiteTimeout time.Duration, startTime time.Time) *models.TestSuiteResult {
+	exercisePath := exercise.dir
+	// The reporter is written outside the module so runs leave no files in
+	// the content tree
+	reporterDir, err := os.MkdirTemp("", "b2l-reporter-")
+	if err == nil {
+		defer os.RemoveAll(reporterDir)
+		err = os.WriteFile(filepath.Join(reporterDir, mochaReporterFile), mochaStreamReporter, 0644)
 	}
-
-	passedTests := 0
-	failedTests := 0
-	for _, result := range results {
-		if result.Passed {
-			passedTests++
-		} else {
-			failedTests++
+	reporterPath := filepath.Join(reporterDir, mochaReporterFile)
+	if err != nil {
+		return &models.TestSuiteResult{
+			ModuleID:      moduleId,
+			Status:        models.SuiteStatusError,
+			Results:       []models.TestResult{{TestName: "Test Setup", Passed: false, Error: &[]string{fmt.Sprintf("Failed to write test reporter: %s", err.Error())}[0]}},
+			ExecutionTime: time.Since(startTime).Milliseconds(),
+			ExerciseType:  exerciseType,
 		}
 	}
 
-	return &models.TestSuiteResult{
-		ModuleID:      moduleId,
-		TotalTests:    len(results),
-		PassedTests:   passedTests,
-		FailedTests:   failedTests,
-		Results:       results,
-		ExecutionTime: time.Since(startTime).Milliseconds(),
-		ExerciseType:  "server",
-	}, nil
-}
+	ctx, cancel := context.WithTimeout(context.Background(), suiteTimeout)
+	defer cancel()
 
-// parseMochaOutput parses Mocha JSON output
-func (t *TestRunner) parseMochaOutput(output string) (*models.MochaOutput, error) {
-	var mochaOutput models.MochaOutput
-	if err := json.Unmarshal([]byte(output), &mochaOutput); err != nil {
-		return nil, err
+	// Run tests using mocha
+	testPath := filepath.Join(exercisePath, exercise.testFile)
+	testCmd := exec.CommandContext(ctx, "npx", "mocha", testPath,
+		"--reporter", reporterPath,
+		"--timeout", mochaTimeoutArg(t.perTestTimeout))
+
+	// Output is consumed as it is produced so results survive a timeout;
+	// WaitDelay stops a hung grandchild from holding the pipes open
+	collector := newMochaStreamCollector()
+	testCmd.Stdout = collector
+	testCmd.Stderr = collector
+	testCmd.WaitDelay = time.Second
+
+	err = testCmd.Run()
+	timedOut := ctx.Err() == context.DeadlineExceeded
+
+	if suite, ok := collector.SuiteResult(moduleId, exerciseType, time.Since(startTime), timedOut); ok {
+		return suite
 	}
-	return &mochaOutput, nil
-}
 
-// convertMochaResults converts Mocha results to our format
-func (t *TestRunner) convertMochaResults(mochaOutput *models.MochaOutput) []models.TestResult {
-	var results []models.TestResult
-
-	// Handle passed tests
-	for _, test := range mochaOutput.Passes {
-		results = append(results, models.TestResult{
-			TestName: test.Title,
-			Passed:   true,
-		})
+	status := models.SuiteStatusError
+	message := fmt.Sprintf("Failed to parse test results\nOutput: %s", collector.Output())
+	if timedOut {
+		status = models.SuiteStatusTimedOut
+		message = fmt.Sprintf("Test execution timed out after %s\nOutput: %s", suiteTimeout, collector.Output())
+	} else if err != nil {
+		message = fmt.Sprintf("Test execution failed: %s\nOutput: %s", err.Error(), collector.Output())
 	}
 
-	// Handle failed tests
-	for _, test := range mochaOutput.Failures {
-		result := models.TestResult{
-			TestName: test.Title,
+	suite := &models.TestSuiteResult{
+		ModuleID:    moduleId,
+		Status:      status,
+		TotalTests:  1,
+		FailedTests: 1,
+		Results: []models.TestResult{{
+			TestName: "Test Execution",
 			Passed:   false,
-		}
-
-		if test.Err != nil {
-			errorMsg := test.Err.Message
-			result.Error = &errorMsg
-
-			// Try to extract expected/actual from error message
-			expectedMatch := regexp.MustCompile(`expected\s+(.+?)\s+to`).FindStringSubmatch(errorMsg)
-			actualMatch := regexp.MustCompile(`got\s+(.+?)$`).FindStringSubmatch(errorMsg)
-
-			if len(expectedMatch) > 1 {
-				result.Expected = expectedMatch[1]
-			}
-			if len(actualMatch) > 1 {
-				result.Actual = actualMatch[1]
-			}
-		}
-
-		results = append(results, result)
+			TimedOut: timedOut,
+			Error:    &message,
+		}},
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+		ExerciseType:  exerciseType,
+	}
+	if timedOut {
+		suite.TimedOutTests = 1
 	}
 
-	return results
+	return suite
 }
 
 // killProcessOnPort kills any process running on the specified port
@@ -5,8 +5,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/backend2lab/backend2lab/server/config"
 	"github.com/backend2lab/backend2lab/server/internal/handlers"
 	"github.com/backend2lab/backend2lab/server/internal/middleware"
+	"github.com/backend2lab/backend2lab/server/internal/models"
 	"github.com/backend2lab/backend2lab/server/internal/services"
 
 	"github.com/gin-contrib/cors"
@@ -15,6 +17,11 @@ import (
 )
 
 func main() {
+	// Run a subcommand instead of the server when one is given
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
 	// Setup logging
 	logrus.SetLevel(logrus.InfoLevel)
 	logrus.SetFormatter(&logrus.JSONFormatter{})
@@ -23,13 +30,110 @@ func main() {
 	host := getEnv("HOST", "localhost")
 	port := getEnv("PORT", "4000")
 
+	contentConfig := config.LoadContentConfig()
+	contentSource, err := newContentSource(contentConfig)
+	if err != nil {
+		log.Fatalf("Failed to set up module content: %v", err)
+	}
+
 	// Initialize services
-	moduleService := services.NewModuleService()
-	testRunner := services.NewTestRunner()
+	moduleService := services.NewModuleServiceWithSource(contentSource)
+	if contentConfig.HistoryDir != "" {
+		history, err := services.NewModuleHistoryWithDir(contentConfig.HistoryDir)
+		if err != nil {
+			log.Fatalf("Failed to load module history: %v", err)
+		}
+		moduleService.WithHistory(history)
+	}
+	checkModuleContent(moduleService, getEnv("STRICT_CONTENT_VALIDATION", "false") == "true")
+	if _, err := moduleService.Reload(); err != nil {
+		log.Fatalf("Failed to load modules: %v", err)
+	}
+	if _, onDisk := contentSource.Dir(); onDisk && contentConfig.Watch {
+		stopWatching, err := moduleService.Watch()
+		if err != nil {
+			logrus.Warnf("Module hot reload disabled: %v", err)
+		} else {
+			defer stopWatching()
+		}
+	}
+	testRunner := services.NewTestRunnerWithSource(contentSource).WithCatalog(moduleService)
+	selfCheckService := services.NewSelfCheckService(moduleService, testRunner)
+	db, err := services.OpenSQLite(config.LoadProgressConfig().DatabasePath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	accountStore, err := services.NewSQLiteAccountStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open account storage: %v", err)
+	}
+	authConfig := config.LoadAuthConfig()
+	accountService := services.NewAccountService(accountStore, authConfig.SessionTTL)
+	hintStore, err := services.NewSQLiteHintStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open hint storage: %v", err)
+	}
+	hintService := services.NewHintService(moduleService, hintStore)
+	solutionStore, err := services.NewSQLiteSolutionStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open solution storage: %v", err)
+	}
+	solutionService := services.NewSolutionService(moduleService, solutionStore)
+	progressStore, err := services.NewSQLiteProgressStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open progress database: %v", err)
+	}
+	progressService := services.NewProgressService(moduleService, progressStore)
+	submissionStore, err := services.NewSQLiteSubmissionStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open submission history: %v", err)
+	}
+	submissionService := services.NewSubmissionService(moduleService, submissionStore)
+	similarityService := services.NewSimilarityService(moduleService, submissionStore)
+	apiKeyStore, err := services.NewSQLiteAPIKeyStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open API key storage: %v", err)
+	}
+	apiKeyService := services.NewAPIKeyService(accountService, apiKeyStore)
+	draftStore, err := services.NewSQLiteDraftStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open draft storage: %v", err)
+	}
+	draftService := services.NewDraftService(moduleService, draftStore)
+	cohortStore, err := services.NewSQLiteCohortStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open cohort storage: %v", err)
+	}
+	cohortService := services.NewCohortService(moduleService, cohortStore, accountService, progressService, submissionService)
+	ltiStore, err := services.NewSQLiteLTIStore(db)
+	if err != nil {
+		log.Fatalf("Failed to open LTI storage: %v", err)
+	}
+	ltiConfig := config.LoadLTIConfig()
+	ltiService, err := services.NewLTIService(ltiConfig, moduleService, accountService, ltiStore)
+	if err != nil {
+		log.Fatalf("Failed to set up LTI: %v", err)
+	}
+	if ltiConfig.Enabled() && ltiConfig.PrivateKeyFile == "" {
+		logrus.Warn("LTI_PRIVATE_KEY_FILE is not set; the LTI signing key changes on every restart")
+	}
 
 	// Initialize handlers
 	moduleHandler := handlers.NewModuleHandler(moduleService)
-	testHandler := handlers.NewTestHandler(testRunner)
+	testHandler := handlers.NewTestHandler(testRunner, moduleService, hintService, solutionService, progressService, submissionService, ltiService)
+	adminHandler := handlers.NewAdminHandler(moduleService, selfCheckService)
+	authoringHandler := handlers.NewAuthoringHandler(moduleService, moduleService)
+	hintHandler := handlers.NewHintHandler(moduleService, hintService)
+	solutionHandler := handlers.NewSolutionHandler(moduleService, solutionService)
+	authHandler := handlers.NewAuthHandler(accountService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	progressHandler := handlers.NewProgressHandler(moduleService, progressService)
+	submissionHandler := handlers.NewSubmissionHandler(submissionService)
+	similarityHandler := handlers.NewSimilarityHandler(similarityService)
+	draftHandler := handlers.NewDraftHandler(moduleService, draftService)
+	cohortHandler := handlers.NewCohortHandler(cohortService)
+	ltiHandler := handlers.NewLTIHandler(ltiService)
 
 	// Setup Gin router
 	router := gin.New()
@@ -40,8 +144,9 @@ func main() {
 	// CORS middleware
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "X-Requested-With", "Content-Type", "Accept", "Authorization"}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	config.AllowHeaders = []string{"Origin", "X-Requested-With", "Content-Type", "Accept", "Authorization", "If-None-Match", "If-Match", handlers.LearnerIdHeader}
+	config.ExposeHeaders = []string{"ETag", "X-Total-Count"}
 	router.Use(cors.New(config))
 
 	// Request logging middleware
@@ -58,15 +163,105 @@ func main() {
 	})
 
 	// API routes
-	api := router.Group("/api")
+	api := router.Group("/api", middleware.Authenticate(accountService), middleware.APIKey(apiKeyService), middleware.AdminToken(getEnv("ADMIN_TOKEN", "")))
 	{
+		// Account routes
+		api.POST("/auth/signup", authHandler.Signup)
+		api.POST("/auth/login", authHandler.Login)
+		api.POST("/auth/logout", middleware.RequireUser(), authHandler.Logout)
+		api.GET("/auth/me", middleware.RequireUser(), authHandler.Me)
+		api.GET("/auth/api-keys", middleware.RequireUser(), apiKeyHandler.ListKeys)
+		api.POST("/auth/api-keys", middleware.RequireUser(), apiKeyHandler.CreateKey)
+		api.POST("/auth/api-keys/:keyId/rotate", middleware.RequireUser(), apiKeyHandler.RotateKey)
+		api.DELETE("/auth/api-keys/:keyId", middleware.RequireUser(), apiKeyHandler.RevokeKey)
+		api.GET("/progress", middleware.RequireUser(), progressHandler.GetDashboard)
+		api.GET("/progress/modules/:moduleId", middleware.RequireUser(), progressHandler.GetModuleProgress)
+		api.GET("/submissions", middleware.RequireValidSession(), submissionHandler.ListSubmissions)
+		api.GET("/submissions/:submissionId", middleware.RequireValidSession(), submissionHandler.GetSubmission)
+		api.GET("/submissions/:submissionId/diff", middleware.RequireValidSession(), submissionHandler.DiffSubmissions)
+		api.GET("/submissions/:submissionId/junit", middleware.RequireValidSession(), submissionHandler.ExportSubmission)
+		api.GET("/drafts/:moduleId", middleware.RequireUser(), draftHandler.ListDrafts)
+		api.GET("/drafts/:moduleId/:file", middleware.RequireUser(), draftHandler.GetDraft)
+		api.PUT("/drafts/:moduleId/:file", middleware.RequireUser(), draftHandler.SaveDraft)
+		api.POST("/drafts/:moduleId/:file/reset", middleware.RequireUser(), draftHandler.ResetDraft)
+		api.GET("/cohorts", middleware.RequireUser(), cohortHandler.ListMyCohorts)
+
 		// Module routes
 		api.GET("/modules", moduleHandler.GetAllModules)
 		api.GET("/modules/:moduleId", moduleHandler.GetModuleContent)
+		api.GET("/modules/:moduleId/versions", moduleHandler.GetModuleVersions)
+		api.GET("/modules/:moduleId/versions/:version", moduleHandler.GetModuleVersion)
+		api.GET("/modules/:moduleId/exercises", moduleHandler.GetModuleExercises)
+		api.GET("/modules/:moduleId/exercises/:exerciseId", moduleHandler.GetModuleExercise)
+		api.GET("/modules/:moduleId/dependencies", moduleHandler.GetModuleDependencies)
+		api.GET("/modules/:moduleId/assets/*path", moduleHandler.GetModuleAsset)
+		api.GET("/modules/:moduleId/hints", hintHandler.GetHints)
+		api.POST("/modules/:moduleId/hints/next", hintHandler.RevealNextHint)
+		api.GET("/modules/:moduleId/solution", solutionHandler.GetSolution)
+		api.GET("/learning-path", moduleHandler.GetLearningPath)
+		api.GET("/learning-path/next", moduleHandler.GetNextModule)
+
+		// LTI routes; the platform posts launches from the LMS
+		api.GET("/lti/jwks", ltiHandler.GetKeySet)
+		api.GET("/lti/login", ltiHandler.Login)
+		api.POST("/lti/login", ltiHandler.Login)
+		api.POST("/lti/launch", ltiHandler.Launch)
+		api.POST("/lti/deep-links/:deepLinkId", middleware.RequireUser(), ltiHandler.DeepLink)
 
 		// Test routes
 		api.POST("/test/:moduleId", testHandler.RunTests)
 		api.POST("/run/:moduleId", testHandler.RunCode)
+
+		// Admin routes; each group requires a permission granted by the
+		// caller's role, and the admin token acts as an admin
+		admin := api.Group("/admin")
+
+		// Instructor routes
+		reports := admin.Group("", middleware.RequirePermission(models.PermissionViewReports))
+		reports.GET("/modules/:moduleId/hints/usage", hintHandler.GetHintUsage)
+		reports.GET("/modules/:moduleId/completion", progressHandler.GetCompletionSummary)
+		solutions := admin.Group("", middleware.RequirePermission(models.PermissionReleaseSolutions))
+		solutions.POST("/modules/:moduleId/solution", solutionHandler.ReleaseSolution)
+		solutions.DELETE("/modules/:moduleId/solution", solutionHandler.WithdrawSolution)
+		submissions := admin.Group("/submissions", middleware.RequirePermission(models.PermissionViewSubmissions))
+		submissions.GET("", submissionHandler.ListAllSubmissions)
+		submissions.GET("/:submissionId", submissionHandler.GetAnySubmission)
+		submissions.GET("/:submissionId/diff", submissionHandler.DiffAnySubmissions)
+		submissions.GET("/:submissionId/junit", submissionHandler.ExportAnySubmission)
+		admin.GET("/modules/:moduleId/similarity", middleware.RequirePermission(models.PermissionViewSubmissions), similarityHandler.GetSimilarityReport)
+		cohorts := admin.Group("/cohorts", middleware.RequirePermission(models.PermissionManageCohorts))
+		cohorts.GET("", cohortHandler.ListCohorts)
+		cohorts.POST("", cohortHandler.CreateCohort)
+		cohorts.GET("/:cohortId", cohortHandler.GetCohort)
+		cohorts.DELETE("/:cohortId", cohortHandler.DeleteCohort)
+		cohorts.POST("/:cohortId/members", cohortHandler.EnrollMember)
+		cohorts.DELETE("/:cohortId/members/:userId", cohortHandler.RemoveMember)
+		cohorts.PUT("/:cohortId/assignments/:moduleId", cohortHandler.AssignModule)
+		cohorts.DELETE("/:cohortId/assignments/:moduleId", cohortHandler.UnassignModule)
+		cohorts.GET("/:cohortId/report", cohortHandler.GetReport)
+		cohorts.GET("/:cohortId/gradebook", cohortHandler.ExportGradebook)
+
+		// Admin-only routes
+		users := admin.Group("/users", middleware.RequirePermission(models.PermissionManageUsers))
+		users.GET("", authHandler.ListUsers)
+		users.PATCH("/:userId", authHandler.SetUserRole)
+		content := admin.Group("", middleware.RequirePermission(models.PermissionManageContent))
+		content.POST("/modules/reload", adminHandler.ReloadModules)
+		content.GET("/modules/:moduleId/export", adminHandler.ExportModule)
+		content.POST("/modules/:moduleId/self-check", adminHandler.RunSelfCheck)
+		content.POST("/modules/import", adminHandler.ImportModule)
+
+		// Authoring routes
+		content.GET("/modules", authoringHandler.ListModules)
+		content.POST("/modules", authoringHandler.CreateModule)
+		content.GET("/modules/:moduleId", authoringHandler.GetModule)
+		content.PATCH("/modules/:moduleId", authoringHandler.UpdateModule)
+		content.DELETE("/modules/:moduleId", authoringHandler.DeleteModule)
+		content.POST("/modules/:moduleId/publish", authoringHandler.PublishModule)
+		content.POST("/modules/:moduleId/unpublish", authoringHandler.UnpublishModule)
+		content.GET("/modules/:moduleId/files/*path", authoringHandler.GetModuleFile)
+		content.PUT("/modules/:moduleId/files/*path", authoringHandler.PutModuleFile)
+		content.DELETE("/modules/:moduleId/files/*path", authoringHandler.DeleteModuleFile)
 	}
 
 	// Start server
@@ -76,6 +271,33 @@ func main() {
 	}
 }
 
+// checkModuleContent validates module content at startup, logging every
+// issue found. In strict mode the server refuses to start on errors.
+func checkModuleContent(moduleService *services.ModuleService, strict bool) {
+	report, err := moduleService.Validate()
+	if err != nil {
+		logrus.Errorf("Failed to validate module content: %v", err)
+		return
+	}
+
+	for _, issue := range report.Issues {
+		entry := logrus.WithFields(logrus.Fields{
+			"module": issue.Module,
+			"path":   issue.Path,
+			"code":   issue.Code,
+		})
+		if issue.Severity == models.SeverityError {
+			entry.Error(issue.Message)
+		} else {
+			entry.Warn(issue.Message)
+		}
+	}
+
+	if !report.OK && strict {
+		log.Fatalf("Module content has %d validation errors", report.Errors)
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,9 @@
+// Package schema holds the JSON Schema for module content.
+package schema
+
+import _ "embed"
+
+// ModuleSchema is the JSON Schema (draft-07) for module.json
+//
+//go:embed module.schema.json
+var ModuleSchema []byte